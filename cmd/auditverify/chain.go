@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main // import "github.com/open-telemetry/opentelemetry-collector-contrib/cmd/auditverify"
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/hashchain"
+)
+
+// fileResult summarizes a verified WORM file.
+type fileResult struct {
+	Path     string
+	FirstSeq uint64
+	LastSeq  uint64
+	// Head is the hash of the last entry.
+	Head    []byte
+	Entries uint64
+	Sealed  bool
+}
+
+// chainStart returns the state the first verified file must continue from:
+// the seal of the rotated file after, or the start of the chain when after is
+// empty.
+func chainStart(after string) (hashchain.State, error) {
+	if after == "" {
+		return hashchain.Genesis(), nil
+	}
+	f, err := os.Open(after)
+	if err != nil {
+		return hashchain.State{}, err
+	}
+	defer f.Close()
+	state, err := hashchain.ReadSeal(f)
+	if err != nil {
+		return hashchain.State{}, fmt.Errorf("%s: %w", after, err)
+	}
+	return state, nil
+}
+
+// verifyFiles verifies files given in rotation order: every file must
+// continue the chain where the previous one, or start, left it, and every
+// file but the last must be sealed. fn, when not nil, is called with every
+// verified entry and the index of its file.
+func verifyFiles(paths []string, start hashchain.State, fn func(file int, e hashchain.Entry) error) ([]fileResult, error) {
+	var results []fileResult
+	state := start
+	for i, path := range paths {
+		if i > 0 && !state.Sealed {
+			return results, fmt.Errorf("%s: file is not sealed but followed by %s", paths[i-1], path)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return results, err
+		}
+		next, err := hashchain.Verify(f, state, func(e hashchain.Entry) error {
+			if fn == nil {
+				return nil
+			}
+			return fn(i, e)
+		})
+		_ = f.Close()
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", path, err)
+		}
+		results = append(results, fileResult{
+			Path:     path,
+			FirstSeq: state.Seq + 1,
+			LastSeq:  next.Seq,
+			Head:     next.Head,
+			Entries:  next.Entries,
+			Sealed:   next.Sealed,
+		})
+		state = next
+	}
+	return results, nil
+}
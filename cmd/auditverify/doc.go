@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// auditverify verifies the tamper evidence of exported logs without running a
+// collector.
+package main // import "github.com/open-telemetry/opentelemetry-collector-contrib/cmd/auditverify"
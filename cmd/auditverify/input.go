@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main // import "github.com/open-telemetry/opentelemetry-collector-contrib/cmd/auditverify"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/hashchain"
+)
+
+const (
+	formatOTLPJSON  = "otlp_json"
+	formatOTLPProto = "otlp_proto"
+	formatWORMFile  = "worm_file"
+)
+
+// logsFile holds the batches read from a file.
+type logsFile struct {
+	path    string
+	batches []plog.Logs
+}
+
+// readFiles reads the batches of exported files:
+//   - otlp_json: one OTLP JSON request per line, as written by the file
+//     exporter with the json format.
+//   - otlp_proto: OTLP protobuf requests prefixed by their length as a
+//     big-endian uint32, as written by the file exporter with the proto format.
+//   - worm_file: files written by the WORM file exporter, in rotation order.
+//     Their chain is verified while reading, from start.
+func readFiles(paths []string, format string, start hashchain.State) ([]logsFile, error) {
+	files := make([]logsFile, len(paths))
+	for i, path := range paths {
+		files[i].path = path
+	}
+	if format == formatWORMFile {
+		_, err := verifyFiles(paths, start, func(i int, e hashchain.Entry) error {
+			if e.Type != hashchain.EntryTypeLogs {
+				return nil
+			}
+			ld, err := decodeWORMEntry(e.Data)
+			if err != nil {
+				return err
+			}
+			files[i].batches = append(files[i].batches, ld)
+			return nil
+		})
+		return files, err
+	}
+	for i, path := range paths {
+		batches, err := readLogs(path, format)
+		if err != nil {
+			return nil, err
+		}
+		files[i].batches = batches
+	}
+	return files, nil
+}
+
+// readLogs reads the batches of an OTLP JSON or protobuf file.
+func readLogs(path, format string) ([]plog.Logs, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var batches []plog.Logs
+	switch format {
+	case formatOTLPJSON:
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 64<<20)
+		for scanner.Scan() {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			ld, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(scanner.Bytes())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			batches = append(batches, ld)
+		}
+		return batches, scanner.Err()
+	case formatOTLPProto:
+		r := bufio.NewReader(f)
+		for {
+			var size uint32
+			if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+				if errors.Is(err, io.EOF) {
+					return batches, nil
+				}
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			buf := make([]byte, size)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			ld, err := (&plog.ProtoUnmarshaler{}).UnmarshalLogs(buf)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			batches = append(batches, ld)
+		}
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// decodeWORMEntry decodes the batch of a WORM file entry: OTLP JSON embedded
+// as is, or OTLP protobuf as a base64 string.
+func decodeWORMEntry(data []byte) (plog.Logs, error) {
+	if len(data) > 0 && data[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(data, &encoded); err != nil {
+			return plog.Logs{}, err
+		}
+		buf, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return plog.Logs{}, err
+		}
+		return (&plog.ProtoUnmarshaler{}).UnmarshalLogs(buf)
+	}
+	return (&plog.JSONUnmarshaler{}).UnmarshalLogs(data)
+}
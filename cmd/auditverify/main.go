@@ -0,0 +1,208 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main // import "github.com/open-telemetry/opentelemetry-collector-contrib/cmd/auditverify"
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/merkle"
+)
+
+const usage = `usage: auditverify <command> [flags] [files...]
+
+Commands:
+  chain      verify the hash chains of files written by the WORM file exporter
+  merkle     verify a ledger anchor receipt and the inclusion of exported records
+
+Run "auditverify <command> -h" for the flags of a command.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "verification failed:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return errors.New("no command given")
+	}
+	switch args[0] {
+	case "chain":
+		return runChain(args[1:], stdout, stderr)
+	case "merkle":
+		return runMerkle(args[1:], stdout, stderr)
+	}
+	fmt.Fprint(stderr, usage)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func runChain(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("chain", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	after := fs.String("after", "", "rotated file preceding the first given file, when verifying from the middle of the chain")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: auditverify chain [-after <file>] <file>...")
+		fmt.Fprintln(stderr, "\nFiles must be given in rotation order, oldest first, the active file last.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no file given")
+	}
+	start, err := chainStart(*after)
+	if err != nil {
+		return err
+	}
+
+	results, err := verifyFiles(fs.Args(), start, nil)
+	for _, res := range results {
+		state := "open"
+		if res.Sealed {
+			state = "sealed"
+		}
+		fmt.Fprintf(stdout, "OK %s: %d entries, seq %d-%d, %s, head %x\n",
+			res.Path, res.Entries, res.FirstSeq, res.LastSeq, state, res.Head)
+	}
+	return err
+}
+
+func runMerkle(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("merkle", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	receiptPath := fs.String("receipt", "", "anchor receipt recorded by the ledger anchor exporter (required)")
+	root := fs.String("root", "", "hex encoded root read from the ledger transaction (required)")
+	format := fs.String("format", formatOTLPJSON, "format of the files: otlp_json, otlp_proto or worm_file")
+	after := fs.String("after", "", "with worm_file, rotated file preceding the first given file")
+	hashAttribute := fs.String("hash-attribute", "", "hash_attribute configured on the exporter")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: auditverify merkle -receipt <receipt.json> -root <root> [flags] [file...]")
+		fmt.Fprintln(stderr, "\nEvery record of the given files must be included in the anchored tree.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch {
+	case *receiptPath == "":
+		fs.Usage()
+		return errors.New("-receipt is required")
+	case *root == "":
+		fs.Usage()
+		return errors.New("-root is required, read it from the ledger transaction of the receipt")
+	}
+	anchored, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(*root), "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid -root: %w", err)
+	}
+	start, err := chainStart(*after)
+	if err != nil {
+		return err
+	}
+
+	receipt, leaves, err := readReceipt(*receiptPath)
+	if err != nil {
+		return err
+	}
+	// The receipt is only trusted as far as it matches the ledger.
+	if !strings.EqualFold(receipt.Root, hex.EncodeToString(anchored)) {
+		return fmt.Errorf("receipt root %s does not match the anchored root %x", receipt.Root, anchored)
+	}
+	computed := merkle.Root(leaves)
+	if !bytes.Equal(computed, anchored) {
+		return fmt.Errorf("the root %x of the %d leaves of the receipt does not match the anchored root %x", computed, len(leaves), anchored)
+	}
+	fmt.Fprintf(stdout, "OK receipt %s: root %x of %d leaves, %s transaction %s\n",
+		*receiptPath, anchored, len(leaves), receipt.Backend, receipt.TransactionID)
+
+	files, err := readFiles(fs.Args(), *format, start)
+	if err != nil {
+		return err
+	}
+	index := make(map[string]int, len(leaves))
+	for i, leaf := range leaves {
+		if _, ok := index[string(leaf)]; !ok {
+			index[string(leaf)] = i
+		}
+	}
+	for _, file := range files {
+		records := 0
+		err := forEachRecord(file.batches, func(rl plog.ResourceLogs, sl plog.ScopeLogs, lr plog.LogRecord) error {
+			records++
+			leaf, err := merkle.RecordLeaf(rl, sl, lr, *hashAttribute)
+			if err != nil {
+				return err
+			}
+			i, ok := index[string(leaf)]
+			if !ok {
+				return fmt.Errorf("%s: record %d is not included in root %x", file.path, records, anchored)
+			}
+			proof := merkle.AuditPath(i, leaves)
+			if r, ok := merkle.RootFromAuditPath(leaf, i, len(leaves), proof); !ok || !bytes.Equal(r, anchored) {
+				return fmt.Errorf("%s: record %d: audit path does not verify", file.path, records)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "OK %s: %d records included\n", file.path, records)
+	}
+	return nil
+}
+
+// readReceipt reads a receipt and decodes its leaves.
+func readReceipt(path string) (merkle.Receipt, [][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return merkle.Receipt{}, nil, err
+	}
+	var r merkle.Receipt
+	if err := json.Unmarshal(data, &r); err != nil {
+		return merkle.Receipt{}, nil, fmt.Errorf("failed to decode receipt %s: %w", path, err)
+	}
+	leaves := make([][]byte, len(r.Leaves))
+	for i, l := range r.Leaves {
+		if leaves[i], err = hex.DecodeString(l); err != nil {
+			return merkle.Receipt{}, nil, fmt.Errorf("receipt %s: invalid leaf %d: %w", path, i, err)
+		}
+	}
+	return r, leaves, nil
+}
+
+// forEachRecord calls fn with every record of batches, in order.
+func forEachRecord(batches []plog.Logs, fn func(plog.ResourceLogs, plog.ScopeLogs, plog.LogRecord) error) error {
+	for _, ld := range batches {
+		rls := ld.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			rl := rls.At(i)
+			sls := rl.ScopeLogs()
+			for j := 0; j < sls.Len(); j++ {
+				sl := sls.At(j)
+				lrs := sl.LogRecords()
+				for k := 0; k < lrs.Len(); k++ {
+					if err := fn(rl, sl, lrs.At(k)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/hashchain"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/merkle"
+)
+
+func testLogs(bodies ...string) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "audit")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, body := range bodies {
+		lrs.AppendEmpty().Body().SetStr(body)
+	}
+	return ld
+}
+
+// writeWORMFile writes batches as a WORM file with JSON data continuing the
+// chain at state, sealed when seal is set.
+func writeWORMFile(t *testing.T, path string, state *hashchain.State, seal bool, batches ...plog.Logs) {
+	t.Helper()
+	*state = state.Next()
+	var b []byte
+	for _, ld := range batches {
+		data, err := (&plog.JSONMarshaler{}).MarshalLogs(ld)
+		require.NoError(t, err)
+		b = append(b, state.Append(hashchain.EntryTypeLogs, data)...)
+	}
+	if seal {
+		data, err := json.Marshal(hashchain.Seal{Entries: state.Entries})
+		require.NoError(t, err)
+		b = append(b, state.Append(hashchain.EntryTypeSeal, data)...)
+	}
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+}
+
+// writeReceipt writes the receipt of a tree over the records of batches.
+func writeReceipt(t *testing.T, path string, batches ...plog.Logs) string {
+	t.Helper()
+	var leaves [][]byte
+	var hexLeaves []string
+	for _, ld := range batches {
+		require.NoError(t, forEachRecord([]plog.Logs{ld}, func(rl plog.ResourceLogs, sl plog.ScopeLogs, lr plog.LogRecord) error {
+			leaf, err := merkle.RecordLeaf(rl, sl, lr, "")
+			leaves = append(leaves, leaf)
+			hexLeaves = append(hexLeaves, hex.EncodeToString(leaf))
+			return err
+		}))
+	}
+	root := hex.EncodeToString(merkle.Root(leaves))
+	data, err := json.Marshal(merkle.Receipt{Root: root, Backend: "ethereum", TransactionID: "0xabc", Leaves: hexLeaves})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return root
+}
+
+func TestRunChain(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "audit-1.ndjson")
+	second := filepath.Join(dir, "audit-2.ndjson")
+	active := filepath.Join(dir, "audit.ndjson")
+	state := hashchain.Genesis()
+	writeWORMFile(t, first, &state, true, testLogs("login"))
+	writeWORMFile(t, second, &state, true, testLogs("read"))
+	writeWORMFile(t, active, &state, false, testLogs("logout"), testLogs("login"))
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, run([]string{"chain", first, second, active}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "OK "+first+": 2 entries, seq 1-2, sealed, head ")
+	assert.Contains(t, stdout.String(), "OK "+active+": 2 entries, seq 5-6, open, head ")
+
+	// Verification may start after a rotated file, trusting its seal.
+	stdout.Reset()
+	require.NoError(t, run([]string{"chain", "-after", first, second, active}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "OK "+second+": 2 entries, seq 3-4, sealed, head ")
+
+	// A removed file is detected.
+	assert.EqualError(t, run([]string{"chain", first, active}, &stdout, &stderr),
+		active+": line 1: prev does not match the hash of the previous file's seal")
+	assert.EqualError(t, run([]string{"chain", second, active}, &stdout, &stderr),
+		second+": line 1: prev does not match the hash of the previous file's seal")
+	assert.EqualError(t, run([]string{"chain", active, first}, &stdout, &stderr),
+		active+": line 1: prev does not match the hash of the previous file's seal")
+
+	data, err := os.ReadFile(active)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(active, bytes.Replace(data, []byte("logout"), []byte("logoff"), 1), 0o600))
+	assert.EqualError(t, run([]string{"chain", first, second, active}, &stdout, &stderr), active+": line 1: hash mismatch")
+
+	// Lines removed from the start of a file are detected.
+	_, rest, _ := bytes.Cut(data, []byte("\n"))
+	require.NoError(t, os.WriteFile(active, rest, 0o600))
+	assert.EqualError(t, run([]string{"chain", first, second, active}, &stdout, &stderr),
+		active+": line 1: prev does not match the hash of the previous file's seal")
+}
+
+func TestRunChainUnsealed(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "audit-1.ndjson")
+	active := filepath.Join(dir, "audit.ndjson")
+	state := hashchain.Genesis()
+	writeWORMFile(t, first, &state, false, testLogs("login"))
+	writeWORMFile(t, active, &state, false, testLogs("logout"))
+
+	var stdout, stderr bytes.Buffer
+	assert.EqualError(t, run([]string{"chain", first, active}, &stdout, &stderr),
+		first+": file is not sealed but followed by "+active)
+}
+
+func TestRunMerkle(t *testing.T) {
+	dir := t.TempDir()
+	batches := []plog.Logs{testLogs("login", "read"), testLogs("logout")}
+	receipt := filepath.Join(dir, "receipt.json")
+	root := writeReceipt(t, receipt, batches...)
+
+	jsonPath := filepath.Join(dir, "logs.json")
+	var lines []byte
+	for _, ld := range batches {
+		data, err := (&plog.JSONMarshaler{}).MarshalLogs(ld)
+		require.NoError(t, err)
+		lines = append(append(lines, data...), '\n')
+	}
+	require.NoError(t, os.WriteFile(jsonPath, lines, 0o600))
+	wormPath := filepath.Join(dir, "audit.ndjson")
+	state := hashchain.Genesis()
+	writeWORMFile(t, wormPath, &state, false, batches[1])
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, run([]string{"merkle", "-receipt", receipt, "-root", "0x" + root, jsonPath}, &stdout, &stderr))
+	assert.Equal(t, fmt.Sprintf("OK receipt %s: root %s of 3 leaves, ethereum transaction 0xabc\nOK %s: 3 records included\n", receipt, root, jsonPath), stdout.String())
+
+	stdout.Reset()
+	require.NoError(t, run([]string{"merkle", "-receipt", receipt, "-root", root, "-format", "worm_file", wormPath}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "OK "+wormPath+": 1 records included\n")
+
+	other := filepath.Join(dir, "other.json")
+	data, err := (&plog.JSONMarshaler{}).MarshalLogs(testLogs("delete"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(other, data, 0o600))
+	assert.EqualError(t, run([]string{"merkle", "-receipt", receipt, "-root", root, other}, &stdout, &stderr),
+		other+": record 1 is not included in root "+root)
+
+	assert.EqualError(t, run([]string{"merkle", "-receipt", receipt, "-root", strings.Repeat("00", 32)}, &stdout, &stderr),
+		"receipt root "+root+" does not match the anchored root "+strings.Repeat("00", 32))
+
+	// A receipt listing other leaves than the anchored tree is rejected, even
+	// with a matching root.
+	forged := filepath.Join(dir, "forged.json")
+	writeReceipt(t, forged, testLogs("delete"))
+	data, err = os.ReadFile(forged)
+	require.NoError(t, err)
+	var r merkle.Receipt
+	require.NoError(t, json.Unmarshal(data, &r))
+	r.Root = root
+	data, err = json.Marshal(r)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(forged, data, 0o600))
+	assert.ErrorContains(t, run([]string{"merkle", "-receipt", forged, "-root", root, other}, &stdout, &stderr),
+		"of the 1 leaves of the receipt does not match the anchored root "+root)
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.EqualError(t, run(nil, &stdout, &stderr), "no command given")
+	assert.EqualError(t, run([]string{"sign"}, &stdout, &stderr), `unknown command "sign"`)
+	assert.EqualError(t, run([]string{"chain"}, &stdout, &stderr), "no file given")
+	assert.EqualError(t, run([]string{"merkle"}, &stdout, &stderr), "-receipt is required")
+	assert.EqualError(t, run([]string{"merkle", "-receipt", "receipt.json"}, &stdout, &stderr), "-root is required, read it from the ledger transaction of the receipt")
+	assert.Contains(t, stderr.String(), "usage: auditverify")
+}
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditmetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditmetricsconnector"
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Config defines the configuration for the audit metrics connector.
+type Config struct {
+	// Dimensions maps the dimensions of the emitted metrics to the attributes
+	// they are read from.
+	Dimensions DimensionsConfig `mapstructure:"dimensions"`
+
+	// ResourceAttributes lists the resource attributes copied to the
+	// resource of the emitted metrics. Other resource attributes are dropped.
+	ResourceAttributes []string `mapstructure:"resource_attributes"`
+
+	// Histograms lists numeric attributes recorded as histograms.
+	Histograms []HistogramConfig `mapstructure:"histograms"`
+
+	// MaxSeries bounds the number of series whose previous window is held in
+	// memory. When the limit is reached the least recently used series is
+	// evicted: its next window starts at the end of the latest window of an
+	// evicted series, so that windows never overlap. 0 means unlimited.
+	// Default: 100000.
+	MaxSeries int `mapstructure:"max_series"`
+}
+
+// DimensionsConfig holds the attribute each dimension is read from. The
+// attribute is looked up on the log record, then its scope, then its
+// resource. An empty value disables the dimension.
+type DimensionsConfig struct {
+	Action  string `mapstructure:"action"`
+	Actor   string `mapstructure:"actor"`
+	Outcome string `mapstructure:"outcome"`
+	Tenant  string `mapstructure:"tenant"`
+}
+
+// HistogramConfig defines a histogram over a numeric record attribute.
+type HistogramConfig struct {
+	// Name is the name of the emitted metric.
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+	Unit        string `mapstructure:"unit"`
+	// Attribute is the numeric attribute recorded. Records without it are
+	// not recorded in the histogram.
+	Attribute string `mapstructure:"attribute"`
+	// Buckets are the explicit bucket boundaries, in increasing order.
+	Buckets []float64 `mapstructure:"buckets"`
+}
+
+func (c *Config) Validate() error {
+	names := map[string]bool{metricNameEvents: true}
+	for i, h := range c.Histograms {
+		if h.Name == "" {
+			return fmt.Errorf("histograms[%d]: name must be specified", i)
+		}
+		if names[h.Name] {
+			return fmt.Errorf("histograms[%d]: duplicate metric name %q", i, h.Name)
+		}
+		names[h.Name] = true
+		if h.Attribute == "" {
+			return fmt.Errorf("histograms[%d]: attribute must be specified", i)
+		}
+		if len(h.Buckets) == 0 {
+			return fmt.Errorf("histograms[%d]: buckets must be specified", i)
+		}
+		if !slices.IsSorted(h.Buckets) || len(slices.Compact(slices.Clone(h.Buckets))) != len(h.Buckets) {
+			return fmt.Errorf("histograms[%d]: buckets must be strictly increasing", i)
+		}
+	}
+	if c.MaxSeries < 0 {
+		return fmt.Errorf("max_series must be >= 0, got %d", c.MaxSeries)
+	}
+	if c.Dimensions == (DimensionsConfig{}) && len(c.Histograms) == 0 {
+		return errors.New("at least one dimension or histogram must be configured")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditmetricsconnector
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditmetricsconnector/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr string
+	}{
+		{
+			id: component.NewID(metadata.Type),
+			expected: func() *Config {
+				return createDefaultConfig().(*Config)
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "custom"),
+			expected: func() *Config {
+				return &Config{
+					Dimensions: DimensionsConfig{
+						Action:  "event.action",
+						Outcome: "event.outcome",
+						Tenant:  "k8s.namespace.name",
+					},
+					ResourceAttributes: []string{"service.name", "k8s.namespace.name"},
+					Histograms: []HistogramConfig{{
+						Name:        "audit.request.duration",
+						Description: "Duration of audited requests.",
+						Unit:        "ms",
+						Attribute:   "http.server.duration",
+						Buckets:     []float64{10, 100, 1000},
+					}},
+					MaxSeries: 1000,
+				}
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "negative_max_series"),
+			expectedErr: "max_series must be >= 0, got -1",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "no_dimensions"),
+			expectedErr: "at least one dimension or histogram must be configured",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "missing_attribute"),
+			expectedErr: "histograms[0]: attribute must be specified",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "unsorted_buckets"),
+			expectedErr: "histograms[0]: buckets must be strictly increasing",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "duplicate_name"),
+			expectedErr: `histograms[0]: duplicate metric name "audit.events"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
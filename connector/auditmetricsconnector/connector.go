@@ -0,0 +1,333 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditmetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditmetricsconnector"
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditmetricsconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+)
+
+const (
+	metricNameEvents = "audit.events"
+	metricDescEvents = "The number of audit events observed."
+	metricUnitEvents = "{event}"
+)
+
+// dimension is an attribute of the emitted data points and the record
+// attribute its value is read from.
+type dimension struct {
+	name      string
+	attribute string
+}
+
+// seriesKey identifies a metric stream: a metric of a resource with the
+// attributes of its data points.
+type seriesKey struct {
+	metric   string
+	resource [16]byte
+	attrs    [16]byte
+}
+
+// auditMetrics turns every batch of audit log records into delta metrics.
+// Only the configured dimensions, resource attributes and histogram values
+// are taken from the records; their bodies and other attributes are never
+// forwarded.
+type auditMetrics struct {
+	component.ShutdownFunc
+
+	metricsConsumer    consumer.Metrics
+	dimensions         []dimension
+	resourceAttributes []string
+	histograms         []HistogramConfig
+	maxSeries          int
+	now                func() time.Time
+
+	// Every data point covers the time from the previous data point of its
+	// series, or from start, to the time its batch was consumed, so that
+	// the delta windows of a series are contiguous and never overlap.
+	mu        sync.Mutex
+	startTime pcommon.Timestamp
+	// lastTime holds the end of the previous window of the series by key, in
+	// recently used order, the least recently used at the back.
+	lastTime map[seriesKey]*list.Element
+	recent   *list.List
+	// evictedTime is the latest end of a window of an evicted series. Series
+	// not in lastTime start from it, since they may have been evicted.
+	evictedTime pcommon.Timestamp
+}
+
+// seriesWindow is the end of the previous window of a series.
+type seriesWindow struct {
+	key seriesKey
+	end pcommon.Timestamp
+}
+
+func newAuditMetrics(cfg *Config, nextConsumer consumer.Metrics) *auditMetrics {
+	var dims []dimension
+	for _, d := range []dimension{
+		{name: "audit.action", attribute: cfg.Dimensions.Action},
+		{name: "audit.actor", attribute: cfg.Dimensions.Actor},
+		{name: "audit.outcome", attribute: cfg.Dimensions.Outcome},
+		{name: "audit.tenant", attribute: cfg.Dimensions.Tenant},
+	} {
+		if d.attribute != "" {
+			dims = append(dims, d)
+		}
+	}
+	return &auditMetrics{
+		metricsConsumer:    nextConsumer,
+		dimensions:         dims,
+		resourceAttributes: cfg.ResourceAttributes,
+		histograms:         cfg.Histograms,
+		maxSeries:          cfg.MaxSeries,
+		now:                time.Now,
+		lastTime:           make(map[seriesKey]*list.Element),
+		recent:             list.New(),
+	}
+}
+
+func (a *auditMetrics) Start(context.Context, component.Host) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.startTime = pcommon.NewTimestampFromTime(a.now())
+	return nil
+}
+
+func (*auditMetrics) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (a *auditMetrics) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	// Records are aggregated by the configured attributes of their resource,
+	// so that resources only differing by other attributes share a series.
+	var resources []pcommon.Map
+	aggs := map[[16]byte]*aggregator{}
+	var order [][16]byte
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		resourceAttrs := a.resourceAttrs(rl.Resource().Attributes())
+		key := pdatautil.MapHash(resourceAttrs)
+		agg, ok := aggs[key]
+		if !ok {
+			agg = newAggregator(len(a.histograms))
+			aggs[key] = agg
+			order = append(order, key)
+			resources = append(resources, resourceAttrs)
+		}
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			sl := rl.ScopeLogs().At(j)
+			for k := 0; k < sl.LogRecords().Len(); k++ {
+				lr := sl.LogRecords().At(k)
+				attrs := a.dimensionAttributes(lr.Attributes(), sl.Scope().Attributes(), rl.Resource().Attributes())
+				agg.addEvent(attrs)
+				for h, hc := range a.histograms {
+					if v, ok := numericValue(lr.Attributes(), hc.Attribute); ok {
+						agg.addValue(h, hc.Buckets, attrs, v)
+					}
+				}
+			}
+		}
+	}
+
+	md := pmetric.NewMetrics()
+	a.mu.Lock()
+	now := pcommon.NewTimestampFromTime(a.now())
+	for i, key := range order {
+		agg := aggs[key]
+		if len(agg.events) == 0 {
+			continue // don't add an empty resource
+		}
+		rm := md.ResourceMetrics().AppendEmpty()
+		resources[i].CopyTo(rm.Resource().Attributes())
+		sm := rm.ScopeMetrics().AppendEmpty()
+		sm.Scope().SetName(metadata.ScopeName)
+		agg.appendMetricsTo(sm.Metrics(), a.histograms, func(metric string, attrs [16]byte) pcommon.Timestamp {
+			return a.window(seriesKey{metric: metric, resource: key, attrs: attrs}, now)
+		}, now)
+	}
+	a.evict()
+	a.mu.Unlock()
+	if md.ResourceMetrics().Len() == 0 {
+		return nil
+	}
+	return a.metricsConsumer.ConsumeMetrics(ctx, md)
+}
+
+// window returns the start of the delta window of a series ending at now:
+// the end of its previous window, or the start of the connector, and marks
+// the series as the most recently used. a.mu must be held.
+func (a *auditMetrics) window(key seriesKey, now pcommon.Timestamp) pcommon.Timestamp {
+	if elem, ok := a.lastTime[key]; ok {
+		sw := elem.Value.(*seriesWindow)
+		start := sw.end
+		sw.end = now
+		a.recent.MoveToFront(elem)
+		return start
+	}
+	a.lastTime[key] = a.recent.PushFront(&seriesWindow{key: key, end: now})
+	return max(a.startTime, a.evictedTime)
+}
+
+// evict removes the least recently used series beyond max_series. It is
+// called once a batch was aggregated, so that the series of a batch are never
+// evicted while it is. a.mu must be held.
+func (a *auditMetrics) evict() {
+	for a.maxSeries > 0 && len(a.lastTime) > a.maxSeries {
+		oldest := a.recent.Remove(a.recent.Back()).(*seriesWindow)
+		delete(a.lastTime, oldest.key)
+		a.evictedTime = max(a.evictedTime, oldest.end)
+	}
+}
+
+// resourceAttrs returns the configured attributes of a resource.
+func (a *auditMetrics) resourceAttrs(attrs pcommon.Map) pcommon.Map {
+	filtered := pcommon.NewMap()
+	for _, name := range a.resourceAttributes {
+		if v, ok := attrs.Get(name); ok {
+			v.CopyTo(filtered.PutEmpty(name))
+		}
+	}
+	return filtered
+}
+
+// dimensionAttributes returns the data point attributes of a record. A
+// dimension whose attribute is missing is left out.
+func (a *auditMetrics) dimensionAttributes(recordAttrs, scopeAttrs, resourceAttrs pcommon.Map) pcommon.Map {
+	attrs := pcommon.NewMap()
+	for _, d := range a.dimensions {
+		for _, m := range []pcommon.Map{recordAttrs, scopeAttrs, resourceAttrs} {
+			if v, ok := m.Get(d.attribute); ok {
+				v.CopyTo(attrs.PutEmpty(d.name))
+				break
+			}
+		}
+	}
+	return attrs
+}
+
+func numericValue(attrs pcommon.Map, key string) (float64, bool) {
+	v, ok := attrs.Get(key)
+	if !ok {
+		return 0, false
+	}
+	switch v.Type() {
+	case pcommon.ValueTypeInt:
+		return float64(v.Int()), true
+	case pcommon.ValueTypeDouble:
+		return v.Double(), true
+	}
+	return 0, false
+}
+
+type eventCount struct {
+	attrs pcommon.Map
+	count uint64
+}
+
+type histogramData struct {
+	attrs  pcommon.Map
+	counts []uint64
+	count  uint64
+	sum    float64
+	min    float64
+	max    float64
+}
+
+// aggregator accumulates the metrics of the records of a resource.
+type aggregator struct {
+	events     map[[16]byte]*eventCount
+	histograms []map[[16]byte]*histogramData
+}
+
+func newAggregator(histograms int) *aggregator {
+	agg := &aggregator{
+		events:     make(map[[16]byte]*eventCount),
+		histograms: make([]map[[16]byte]*histogramData, histograms),
+	}
+	for i := range agg.histograms {
+		agg.histograms[i] = make(map[[16]byte]*histogramData)
+	}
+	return agg
+}
+
+func (agg *aggregator) addEvent(attrs pcommon.Map) {
+	key := pdatautil.MapHash(attrs)
+	ec, ok := agg.events[key]
+	if !ok {
+		ec = &eventCount{attrs: attrs}
+		agg.events[key] = ec
+	}
+	ec.count++
+}
+
+func (agg *aggregator) addValue(histogram int, buckets []float64, attrs pcommon.Map, v float64) {
+	key := pdatautil.MapHash(attrs)
+	hd, ok := agg.histograms[histogram][key]
+	if !ok {
+		hd = &histogramData{attrs: attrs, counts: make([]uint64, len(buckets)+1), min: v, max: v}
+		agg.histograms[histogram][key] = hd
+	}
+	// Explicit bucket i holds values in (buckets[i-1], buckets[i]].
+	hd.counts[sort.SearchFloat64s(buckets, v)]++
+	hd.count++
+	hd.sum += v
+	hd.min = min(hd.min, v)
+	hd.max = max(hd.max, v)
+}
+
+// appendMetricsTo appends the aggregated metrics, with data points ending at
+// endTime and starting at the time returned by start for their series.
+func (agg *aggregator) appendMetricsTo(metrics pmetric.MetricSlice, histograms []HistogramConfig, start func(metric string, attrs [16]byte) pcommon.Timestamp, endTime pcommon.Timestamp) {
+	m := metrics.AppendEmpty()
+	m.SetName(metricNameEvents)
+	m.SetDescription(metricDescEvents)
+	m.SetUnit(metricUnitEvents)
+	sum := m.SetEmptySum()
+	// The delta value is always positive, so a value accumulated downstream is monotonic
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	for key, ec := range agg.events {
+		dp := sum.DataPoints().AppendEmpty()
+		ec.attrs.CopyTo(dp.Attributes())
+		dp.SetIntValue(int64(ec.count))
+		dp.SetStartTimestamp(start(metricNameEvents, key))
+		dp.SetTimestamp(endTime)
+	}
+
+	for i, hc := range histograms {
+		if len(agg.histograms[i]) == 0 {
+			continue
+		}
+		m := metrics.AppendEmpty()
+		m.SetName(hc.Name)
+		m.SetDescription(hc.Description)
+		m.SetUnit(hc.Unit)
+		hist := m.SetEmptyHistogram()
+		hist.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		for key, hd := range agg.histograms[i] {
+			dp := hist.DataPoints().AppendEmpty()
+			hd.attrs.CopyTo(dp.Attributes())
+			dp.ExplicitBounds().FromRaw(hc.Buckets)
+			dp.BucketCounts().FromRaw(hd.counts)
+			dp.SetCount(hd.count)
+			dp.SetSum(hd.sum)
+			dp.SetMin(hd.min)
+			dp.SetMax(hd.max)
+			dp.SetStartTimestamp(start(hc.Name, key))
+			dp.SetTimestamp(endTime)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditmetricsconnector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/connector/connectortest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditmetricsconnector/internal/metadata"
+)
+
+func addRecord(sl plog.ScopeLogs, ts int, attrs map[string]any) {
+	lr := sl.LogRecords().AppendEmpty()
+	lr.SetTimestamp(pcommon.Timestamp(ts))
+	lr.Body().SetStr("raw audit content")
+	_ = lr.Attributes().FromRaw(attrs)
+}
+
+// newTestConnector creates a started connector whose clock returns the
+// values of *now as seconds.
+func newTestConnector(t *testing.T, cfg *Config, now *int64) (*consumertest.MetricsSink, *auditMetrics) {
+	sink := &consumertest.MetricsSink{}
+	conn, err := NewFactory().CreateLogsToMetrics(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	conn.(*auditMetrics).now = func() time.Time { return time.Unix(*now, 0) }
+	require.NoError(t, conn.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, conn.Shutdown(t.Context()))
+	})
+	return sink, conn.(*auditMetrics)
+}
+
+// dataPoints returns the data points of a sum by their attributes.
+func dataPoints(dps pmetric.NumberDataPointSlice) map[string]int64 {
+	counts := map[string]int64{}
+	for i := 0; i < dps.Len(); i++ {
+		key := ""
+		dps.At(i).Attributes().Range(func(k string, v pcommon.Value) bool {
+			key += k + "=" + v.AsString() + ","
+			return true
+		})
+		counts[key] = dps.At(i).IntValue()
+	}
+	return counts
+}
+
+func TestConsumeLogsCounts(t *testing.T) {
+	now := int64(100)
+	sink, conn := newTestConnector(t, createDefaultConfig().(*Config), &now)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("tenant.id", "acme")
+	rl.Resource().Attributes().PutStr("host.name", "node-1")
+	sl := rl.ScopeLogs().AppendEmpty()
+	addRecord(sl, 30, map[string]any{"event.action": "login", "user.id": "alice", "event.outcome": "success"})
+	addRecord(sl, 10, map[string]any{"event.action": "login", "user.id": "alice", "event.outcome": "success"})
+	addRecord(sl, 20, map[string]any{"event.action": "login", "user.id": "bob", "event.outcome": "failure", "tenant.id": "other"})
+	addRecord(sl, 0, map[string]any{"user.id": "carol"})
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+
+	now = 160
+	require.NoError(t, conn.ConsumeLogs(t.Context(), ld))
+	require.Len(t, sink.AllMetrics(), 1)
+	md := sink.AllMetrics()[0]
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+
+	// No resource attribute is configured, none is copied.
+	rm := md.ResourceMetrics().At(0)
+	assert.Empty(t, rm.Resource().Attributes().AsRaw())
+	require.Equal(t, 1, rm.ScopeMetrics().Len())
+	assert.Equal(t, metadata.ScopeName, rm.ScopeMetrics().At(0).Scope().Name())
+	metrics := rm.ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, metrics.Len())
+
+	m := metrics.At(0)
+	assert.Equal(t, "audit.events", m.Name())
+	assert.Equal(t, "{event}", m.Unit())
+	assert.True(t, m.Sum().IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, m.Sum().AggregationTemporality())
+	assert.Equal(t, map[string]int64{
+		"audit.action=login,audit.actor=alice,audit.outcome=success,audit.tenant=acme,": 2,
+		"audit.action=login,audit.actor=bob,audit.outcome=failure,audit.tenant=other,":  1,
+		"audit.actor=carol,audit.tenant=acme,":                                          1,
+	}, dataPoints(m.Sum().DataPoints()))
+	for i := 0; i < m.Sum().DataPoints().Len(); i++ {
+		dp := m.Sum().DataPoints().At(i)
+		assert.Equal(t, pcommon.Timestamp(100*time.Second), dp.StartTimestamp())
+		assert.Equal(t, pcommon.Timestamp(160*time.Second), dp.Timestamp())
+	}
+}
+
+func TestConsumeLogsWindows(t *testing.T) {
+	now := int64(100)
+	sink, conn := newTestConnector(t, createDefaultConfig().(*Config), &now)
+
+	consume := func(ts int64, actors ...string) {
+		now = ts
+		ld := plog.NewLogs()
+		sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+		for _, actor := range actors {
+			// Record timestamps don't matter, they may be late or out of order.
+			addRecord(sl, 5, map[string]any{"user.id": actor})
+		}
+		require.NoError(t, conn.ConsumeLogs(t.Context(), ld))
+	}
+	consume(110, "alice")
+	consume(120, "bob")
+	consume(130, "alice", "bob")
+
+	// Every series has contiguous windows, from the start of the connector or
+	// the previous data point of the series to the time its batch was consumed.
+	windows := map[string][][2]int64{}
+	for _, md := range sink.AllMetrics() {
+		dps := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			actor, _ := dp.Attributes().Get("audit.actor")
+			windows[actor.Str()] = append(windows[actor.Str()], [2]int64{
+				int64(dp.StartTimestamp()) / int64(time.Second),
+				int64(dp.Timestamp()) / int64(time.Second),
+			})
+		}
+	}
+	assert.Equal(t, map[string][][2]int64{
+		"alice": {{100, 110}, {110, 130}},
+		"bob":   {{100, 120}, {120, 130}},
+	}, windows)
+}
+
+func TestConsumeLogsEvictsSeries(t *testing.T) {
+	now := int64(100)
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxSeries = 2
+	sink, conn := newTestConnector(t, cfg, &now)
+
+	consume := func(ts int64, actors ...string) {
+		now = ts
+		ld := plog.NewLogs()
+		sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+		for _, actor := range actors {
+			addRecord(sl, 5, map[string]any{"user.id": actor})
+		}
+		require.NoError(t, conn.ConsumeLogs(t.Context(), ld))
+	}
+	// The series of a batch are kept until it is aggregated, even beyond
+	// max_series, then the least recently used are evicted.
+	consume(110, "alice")
+	consume(120, "bob", "carol")
+	consume(130, "alice")
+	consume(140, "dave")
+	assert.Len(t, conn.lastTime, 2)
+
+	windows := map[string][][2]int64{}
+	for _, md := range sink.AllMetrics() {
+		dps := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			actor, _ := dp.Attributes().Get("audit.actor")
+			windows[actor.Str()] = append(windows[actor.Str()], [2]int64{
+				int64(dp.StartTimestamp()) / int64(time.Second),
+				int64(dp.Timestamp()) / int64(time.Second),
+			})
+		}
+	}
+	// Evicted series, and new series that may have been evicted, start at the
+	// end of the latest window of an evicted series: windows never overlap.
+	assert.Equal(t, map[string][][2]int64{
+		"alice": {{100, 110}, {110, 130}},
+		"bob":   {{100, 120}},
+		"carol": {{100, 120}},
+		"dave":  {{120, 140}},
+	}, windows)
+}
+
+func TestConsumeLogsResourceAttributes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ResourceAttributes = []string{"service.name", "deployment.environment"}
+	now := int64(100)
+	sink, conn := newTestConnector(t, cfg, &now)
+
+	ld := plog.NewLogs()
+	for _, host := range []string{"node-1", "node-2"} {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("service.name", "api")
+		rl.Resource().Attributes().PutStr("host.name", host)
+		addRecord(rl.ScopeLogs().AppendEmpty(), 1, map[string]any{"user.id": "alice"})
+	}
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "auth")
+	addRecord(rl.ScopeLogs().AppendEmpty(), 1, map[string]any{"user.id": "alice"})
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), ld))
+	require.Len(t, sink.AllMetrics(), 1)
+	md := sink.AllMetrics()[0]
+
+	// Resources only differing by attributes that are not copied share their
+	// series.
+	require.Equal(t, 2, md.ResourceMetrics().Len())
+	counts := map[string]map[string]int64{}
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		service, _ := rm.Resource().Attributes().Get("service.name")
+		assert.Equal(t, 1, rm.Resource().Attributes().Len())
+		counts[service.Str()] = dataPoints(rm.ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints())
+	}
+	assert.Equal(t, map[string]map[string]int64{
+		"api":  {"audit.actor=alice,": 2},
+		"auth": {"audit.actor=alice,": 1},
+	}, counts)
+}
+
+func TestConsumeLogsHistograms(t *testing.T) {
+	cfg := &Config{
+		Dimensions: DimensionsConfig{Outcome: "event.outcome"},
+		Histograms: []HistogramConfig{{
+			Name:      "audit.request.duration",
+			Unit:      "ms",
+			Attribute: "duration",
+			Buckets:   []float64{10, 100},
+		}},
+	}
+	now := int64(100)
+	sink, conn := newTestConnector(t, cfg, &now)
+
+	ld := plog.NewLogs()
+	sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	addRecord(sl, 1, map[string]any{"event.outcome": "success", "duration": 5})
+	addRecord(sl, 1, map[string]any{"event.outcome": "success", "duration": 10.0})
+	addRecord(sl, 1, map[string]any{"event.outcome": "success", "duration": 250})
+	addRecord(sl, 1, map[string]any{"event.outcome": "success", "duration": "slow"})
+	addRecord(sl, 1, map[string]any{"event.outcome": "success"})
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), ld))
+	require.Len(t, sink.AllMetrics(), 1)
+	metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+	assert.Equal(t, map[string]int64{"audit.outcome=success,": 5}, dataPoints(metrics.At(0).Sum().DataPoints()))
+
+	m := metrics.At(1)
+	assert.Equal(t, "audit.request.duration", m.Name())
+	assert.Equal(t, "ms", m.Unit())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, m.Histogram().AggregationTemporality())
+	require.Equal(t, 1, m.Histogram().DataPoints().Len())
+	dp := m.Histogram().DataPoints().At(0)
+	assert.Equal(t, map[string]any{"audit.outcome": "success"}, dp.Attributes().AsRaw())
+	assert.Equal(t, []float64{10, 100}, dp.ExplicitBounds().AsRaw())
+	assert.Equal(t, []uint64{2, 0, 1}, dp.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(3), dp.Count())
+	assert.Equal(t, 265.0, dp.Sum())
+	assert.Equal(t, 5.0, dp.Min())
+	assert.Equal(t, 250.0, dp.Max())
+}
+
+func TestConsumeLogsEmpty(t *testing.T) {
+	now := int64(100)
+	sink, conn := newTestConnector(t, createDefaultConfig().(*Config), &now)
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), plog.NewLogs()))
+	assert.Empty(t, sink.AllMetrics())
+}
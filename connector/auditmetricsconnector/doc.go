@@ -0,0 +1,7 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package auditmetricsconnector aggregates audit log records into metrics.
+package auditmetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditmetricsconnector"
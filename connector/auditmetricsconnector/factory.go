@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditmetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditmetricsconnector"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditmetricsconnector/internal/metadata"
+)
+
+// NewFactory returns a new factory for the audit metrics connector.
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		connector.WithLogsToMetrics(createLogsToMetrics, metadata.LogsToMetricsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Dimensions: DimensionsConfig{
+			Action:  "event.action",
+			Actor:   "user.id",
+			Outcome: "event.outcome",
+			Tenant:  "tenant.id",
+		},
+		MaxSeries: 100000,
+	}
+}
+
+func createLogsToMetrics(
+	_ context.Context,
+	_ connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (connector.Logs, error) {
+	return newAuditMetrics(cfg.(*Config), nextConsumer), nil
+}
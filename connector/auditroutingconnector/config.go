@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditroutingconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditroutingconnector"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/pipeline"
+)
+
+// defaultRouteName is the route name reported for records sent to the
+// default pipelines.
+const defaultRouteName = "default"
+
+// Config defines the configuration for the audit routing connector.
+type Config struct {
+	// Attributes are the keys of the attributes routes are matched on.
+	Attributes AttributesConfig `mapstructure:"attributes"`
+
+	// Routes are evaluated in order; every record is sent to the pipelines of
+	// the first route it matches.
+	Routes []RouteConfig `mapstructure:"routes"`
+
+	// DefaultPipelines receive the records matching no route. Required, so
+	// that no audit record is dropped for lack of a route.
+	DefaultPipelines []pipeline.ID `mapstructure:"default_pipelines"`
+}
+
+// AttributesConfig defines the attributes holding the values routes are
+// matched on. They are looked up on the record, then on its scope and
+// resource, except for Integrity.
+type AttributesConfig struct {
+	// Integrity holds the integrity verification status of the record. It is
+	// only read from the record, where the verifying component sets it.
+	// Default: "audit.integrity.status".
+	Integrity string `mapstructure:"integrity"`
+
+	// Classification holds the classification of the record, as set by the
+	// OPA policy processor. Default: "audit.classification".
+	Classification string `mapstructure:"classification"`
+
+	// Route holds the route decided by a policy, as set by the OPA policy
+	// processor. Default: "audit.route".
+	Route string `mapstructure:"route"`
+
+	// Tenant holds the tenant the record belongs to. Default: "tenant.id".
+	Tenant string `mapstructure:"tenant"`
+}
+
+// RouteConfig defines a route. A record matches a route when it matches every
+// condition set on it; a condition matches when the attribute holds one of the
+// listed values.
+type RouteConfig struct {
+	// Name identifies the route in telemetry.
+	Name string `mapstructure:"name"`
+
+	// Integrity lists the integrity verification statuses matched. Records
+	// without the integrity attribute have the status "unverified".
+	Integrity []string `mapstructure:"integrity"`
+
+	// Classification lists the classifications matched.
+	Classification []string `mapstructure:"classification"`
+
+	// Route lists the policy routes matched.
+	Route []string `mapstructure:"route"`
+
+	// Tenant lists the tenants matched.
+	Tenant []string `mapstructure:"tenant"`
+
+	// Pipelines receive the records matching the route.
+	Pipelines []pipeline.ID `mapstructure:"pipelines"`
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	for _, attr := range []struct{ key, value string }{
+		{"integrity", c.Attributes.Integrity},
+		{"classification", c.Attributes.Classification},
+		{"route", c.Attributes.Route},
+		{"tenant", c.Attributes.Tenant},
+	} {
+		if attr.value == "" {
+			errs = append(errs, fmt.Errorf("attributes::%s must not be empty", attr.key))
+		}
+	}
+	if len(c.DefaultPipelines) == 0 {
+		errs = append(errs, errors.New("default_pipelines must be specified"))
+	}
+
+	names := make(map[string]bool, len(c.Routes))
+	for i, route := range c.Routes {
+		switch {
+		case route.Name == "":
+			errs = append(errs, fmt.Errorf("routes[%d]: name must be specified", i))
+		case route.Name == defaultRouteName:
+			errs = append(errs, fmt.Errorf("routes[%d]: name %q is reserved", i, defaultRouteName))
+		case names[route.Name]:
+			errs = append(errs, fmt.Errorf("routes[%d]: duplicate name %q", i, route.Name))
+		}
+		names[route.Name] = true
+		if len(route.Integrity)+len(route.Classification)+len(route.Route)+len(route.Tenant) == 0 {
+			errs = append(errs, fmt.Errorf("routes[%d]: at least one of integrity, classification, route or tenant must be specified", i))
+		}
+		if len(route.Pipelines) == 0 {
+			errs = append(errs, fmt.Errorf("routes[%d]: pipelines must be specified", i))
+		}
+	}
+	return errors.Join(errs...)
+}
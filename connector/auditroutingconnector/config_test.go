@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditroutingconnector
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditroutingconnector/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	logs := func(name string) pipeline.ID {
+		return pipeline.NewIDWithName(pipeline.SignalLogs, name)
+	}
+	tests := []struct {
+		id          component.ID
+		expected    *Config
+		expectedErr []string
+	}{
+		{
+			id: component.NewIDWithName(metadata.Type, "custom"),
+			expected: &Config{
+				Attributes: AttributesConfig{
+					Integrity:      "integrity.status",
+					Classification: "audit.classification",
+					Route:          "audit.route",
+					Tenant:         "audit.tenant",
+				},
+				Routes: []RouteConfig{
+					{
+						Name:           "verified",
+						Integrity:      []string{"verified"},
+						Classification: []string{"security", "compliance"},
+						Pipelines:      []pipeline.ID{logs("worm")},
+					},
+					{
+						Name:      "quarantine",
+						Integrity: []string{"failed", "unverified"},
+						Pipelines: []pipeline.ID{logs("quarantine")},
+					},
+					{
+						Name:      "acme",
+						Tenant:    []string{"acme"},
+						Route:     []string{"siem"},
+						Pipelines: []pipeline.ID{logs("acme"), logs("siem")},
+					},
+				},
+				DefaultPipelines: []pipeline.ID{logs("default")},
+			},
+		},
+		{
+			id:          component.NewID(metadata.Type),
+			expectedErr: []string{"default_pipelines must be specified"},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "no_default"),
+			expectedErr: []string{"default_pipelines must be specified"},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "invalid_routes"),
+			expectedErr: []string{
+				"attributes::integrity must not be empty",
+				"routes[0]: name must be specified",
+				"routes[0]: pipelines must be specified",
+				`routes[1]: name "default" is reserved`,
+				"routes[1]: at least one of integrity, classification, route or tenant must be specified",
+				`routes[3]: duplicate name "worm"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if len(tt.expectedErr) > 0 {
+				err := xconfmap.Validate(cfg)
+				for _, expected := range tt.expectedErr {
+					assert.ErrorContains(t, err, expected)
+				}
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditroutingconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditroutingconnector"
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pipeline"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditroutingconnector/internal/metadata"
+)
+
+// integrityUnverified is the integrity status of records without the
+// integrity attribute.
+const integrityUnverified = "unverified"
+
+var errUnexpectedConsumer = errors.New("expected consumer to be a connector router")
+
+// condition matches records whose attribute holds one of values.
+type condition struct {
+	attribute string
+	values    []string
+	// recordOnly ignores the attribute on the scope and resource.
+	recordOnly bool
+}
+
+type route struct {
+	name       string
+	conditions []condition
+	consumer   consumer.Logs
+	attrs      metric.MeasurementOption
+}
+
+// auditRouting sends every record to the pipelines of the first route it
+// matches, or to the default pipelines.
+type auditRouting struct {
+	component.StartFunc
+	component.ShutdownFunc
+
+	integrityAttribute string
+	// routes holds the configured routes followed by the default route.
+	routes    []route
+	telemetry *metadata.TelemetryBuilder
+}
+
+func newAuditRouting(set connector.Settings, cfg *Config, next consumer.Logs) (*auditRouting, error) {
+	router, ok := next.(connector.LogsRouterAndConsumer)
+	if !ok {
+		return nil, errUnexpectedConsumer
+	}
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]route, 0, len(cfg.Routes)+1)
+	for _, rc := range cfg.Routes {
+		r, err := newRoute(rc.Name, rc.Pipelines, router)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range []condition{
+			// The integrity status is set on the record by the verifying
+			// component; senders control the scope and resource.
+			{attribute: cfg.Attributes.Integrity, values: rc.Integrity, recordOnly: true},
+			{attribute: cfg.Attributes.Classification, values: rc.Classification},
+			{attribute: cfg.Attributes.Route, values: rc.Route},
+			{attribute: cfg.Attributes.Tenant, values: rc.Tenant},
+		} {
+			if len(c.values) > 0 {
+				r.conditions = append(r.conditions, c)
+			}
+		}
+		routes = append(routes, r)
+	}
+	r, err := newRoute(defaultRouteName, cfg.DefaultPipelines, router)
+	if err != nil {
+		return nil, err
+	}
+	routes = append(routes, r)
+
+	return &auditRouting{
+		integrityAttribute: cfg.Attributes.Integrity,
+		routes:             routes,
+		telemetry:          telemetryBuilder,
+	}, nil
+}
+
+func newRoute(name string, pipelines []pipeline.ID, router connector.LogsRouterAndConsumer) (route, error) {
+	next, err := router.Consumer(pipelines...)
+	if err != nil {
+		return route{}, err
+	}
+	return route{
+		name:     name,
+		consumer: next,
+		attrs:    metric.WithAttributeSet(attribute.NewSet(attribute.String("route", name))),
+	}, nil
+}
+
+func (*auditRouting) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (a *auditRouting) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	groups := make([]routedLogs, len(a.routes))
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		for g := range groups {
+			groups[g].hasResource = false
+		}
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			sl := rl.ScopeLogs().At(j)
+			for g := range groups {
+				groups[g].hasScope = false
+			}
+			for k := 0; k < sl.LogRecords().Len(); k++ {
+				lr := sl.LogRecords().At(k)
+				r := a.match(lr.Attributes(), sl.Scope().Attributes(), rl.Resource().Attributes())
+				groups[r].append(rl, sl, lr)
+			}
+		}
+	}
+
+	var errs []error
+	for r, group := range groups {
+		if group.count == 0 {
+			continue
+		}
+		a.telemetry.ConnectorAuditRoutingRecords.Add(ctx, group.count, a.routes[r].attrs)
+		errs = append(errs, a.routes[r].consumer.ConsumeLogs(ctx, group.logs))
+	}
+	return errors.Join(errs...)
+}
+
+// routedLogs collects the records sent to a route, keeping the resource and
+// scope they were received with.
+type routedLogs struct {
+	logs        plog.Logs
+	count       int64
+	resource    plog.ResourceLogs
+	scope       plog.ScopeLogs
+	hasResource bool
+	hasScope    bool
+}
+
+func (g *routedLogs) append(rl plog.ResourceLogs, sl plog.ScopeLogs, lr plog.LogRecord) {
+	if g.count == 0 {
+		g.logs = plog.NewLogs()
+	}
+	if !g.hasResource {
+		g.resource = g.logs.ResourceLogs().AppendEmpty()
+		rl.Resource().CopyTo(g.resource.Resource())
+		g.resource.SetSchemaUrl(rl.SchemaUrl())
+		g.hasResource = true
+		g.hasScope = false
+	}
+	if !g.hasScope {
+		g.scope = g.resource.ScopeLogs().AppendEmpty()
+		sl.Scope().CopyTo(g.scope.Scope())
+		g.scope.SetSchemaUrl(sl.SchemaUrl())
+		g.hasScope = true
+	}
+	lr.CopyTo(g.scope.LogRecords().AppendEmpty())
+	g.count++
+}
+
+// match returns the index of the route of a record. Attributes are looked up
+// on the record, then on its scope and resource, except for the integrity
+// status which is only read from the record.
+func (a *auditRouting) match(recordAttrs, scopeAttrs, resourceAttrs pcommon.Map) int {
+	maps := []pcommon.Map{recordAttrs, scopeAttrs, resourceAttrs}
+	for i, r := range a.routes[:len(a.routes)-1] {
+		if a.matchConditions(r.conditions, maps) {
+			return i
+		}
+	}
+	return len(a.routes) - 1
+}
+
+func (a *auditRouting) matchConditions(conditions []condition, maps []pcommon.Map) bool {
+	for _, c := range conditions {
+		levels := maps
+		if c.recordOnly {
+			levels = maps[:1]
+		}
+		value, ok := lookup(levels, c.attribute)
+		if !ok && c.attribute == a.integrityAttribute {
+			value, ok = integrityUnverified, true
+		}
+		if !ok || !slices.Contains(c.values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the value of the first map holding key.
+func lookup(maps []pcommon.Map, key string) (string, bool) {
+	for _, m := range maps {
+		if v, ok := m.Get(key); ok {
+			return v.AsString(), true
+		}
+	}
+	return "", false
+}
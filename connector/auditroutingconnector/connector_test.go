@@ -0,0 +1,219 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditroutingconnector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/connector/connectortest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pipeline"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditroutingconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditroutingconnector/internal/metadatatest"
+)
+
+var (
+	wormPipeline       = pipeline.NewIDWithName(pipeline.SignalLogs, "worm")
+	quarantinePipeline = pipeline.NewIDWithName(pipeline.SignalLogs, "quarantine")
+	acmePipeline       = pipeline.NewIDWithName(pipeline.SignalLogs, "acme")
+	defaultPipeline    = pipeline.NewIDWithName(pipeline.SignalLogs, "default")
+)
+
+func testConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Routes = []RouteConfig{
+		{
+			Name:           "verified",
+			Integrity:      []string{"verified"},
+			Classification: []string{"security", "compliance"},
+			Pipelines:      []pipeline.ID{wormPipeline},
+		},
+		{
+			Name:      "quarantine",
+			Integrity: []string{"failed", "unverified"},
+			Pipelines: []pipeline.ID{quarantinePipeline},
+		},
+		{
+			Name:      "acme",
+			Tenant:    []string{"acme"},
+			Pipelines: []pipeline.ID{acmePipeline, wormPipeline},
+		},
+	}
+	cfg.DefaultPipelines = []pipeline.ID{defaultPipeline}
+	return cfg
+}
+
+type sinks map[pipeline.ID]*consumertest.LogsSink
+
+func newTestConnector(t *testing.T, cfg *Config, tel *componenttest.Telemetry) (connector.Logs, sinks) {
+	t.Helper()
+	s := sinks{
+		wormPipeline:       new(consumertest.LogsSink),
+		quarantinePipeline: new(consumertest.LogsSink),
+		acmePipeline:       new(consumertest.LogsSink),
+		defaultPipeline:    new(consumertest.LogsSink),
+	}
+	consumers := make(map[pipeline.ID]consumer.Logs, len(s))
+	for id, sink := range s {
+		consumers[id] = sink
+	}
+	set := connectortest.NewNopSettings(metadata.Type)
+	if tel != nil {
+		set.TelemetrySettings = tel.NewTelemetrySettings()
+	}
+	conn, err := NewFactory().CreateLogsToLogs(t.Context(), set, cfg, connector.NewLogsRouter(consumers))
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, conn.Shutdown(context.Background())) })
+	return conn, s
+}
+
+// bodies returns the bodies of the records received by sink, per resource.
+func bodies(sink *consumertest.LogsSink) map[string][]string {
+	out := map[string][]string{}
+	for _, ld := range sink.AllLogs() {
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			rl := ld.ResourceLogs().At(i)
+			service, _ := rl.Resource().Attributes().Get("service.name")
+			for j := 0; j < rl.ScopeLogs().Len(); j++ {
+				lrs := rl.ScopeLogs().At(j).LogRecords()
+				for k := 0; k < lrs.Len(); k++ {
+					out[service.Str()] = append(out[service.Str()], lrs.At(k).Body().Str())
+				}
+			}
+		}
+	}
+	return out
+}
+
+func TestRouting(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	conn, s := newTestConnector(t, testConfig(), tel)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "api")
+	rl.Resource().Attributes().PutStr("tenant.id", "globex")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, r := range []struct {
+		body, integrity, classification string
+	}{
+		{body: "verified security", integrity: "verified", classification: "security"},
+		{body: "verified internal", integrity: "verified", classification: "internal"},
+		{body: "failed", integrity: "failed", classification: "security"},
+		{body: "unverified", classification: "security"},
+	} {
+		lr := lrs.AppendEmpty()
+		lr.Body().SetStr(r.body)
+		if r.integrity != "" {
+			lr.Attributes().PutStr("audit.integrity.status", r.integrity)
+		}
+		lr.Attributes().PutStr("audit.classification", r.classification)
+	}
+
+	rl = ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "billing")
+	rl.Resource().Attributes().PutStr("tenant.id", "acme")
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("audit")
+	for _, body := range []string{"acme verified compliance", "acme verified internal"} {
+		lr := sl.LogRecords().AppendEmpty()
+		lr.Body().SetStr(body)
+		lr.Attributes().PutStr("audit.integrity.status", "verified")
+	}
+	// The scope attribute applies to the first record only, as the
+	// record attribute takes precedence on the second.
+	sl.Scope().Attributes().PutStr("audit.classification", "compliance")
+	sl.LogRecords().At(1).Attributes().PutStr("audit.classification", "internal")
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), ld))
+
+	assert.Equal(t, map[string][]string{
+		"api":     {"verified security"},
+		"billing": {"acme verified compliance", "acme verified internal"},
+	}, bodies(s[wormPipeline]))
+	assert.Equal(t, map[string][]string{"api": {"failed", "unverified"}}, bodies(s[quarantinePipeline]))
+	assert.Equal(t, map[string][]string{"billing": {"acme verified internal"}}, bodies(s[acmePipeline]))
+	assert.Equal(t, map[string][]string{"api": {"verified internal"}}, bodies(s[defaultPipeline]))
+
+	// Records keep their resource and scope.
+	acme := s[acmePipeline].AllLogs()[0].ResourceLogs().At(0)
+	assert.Equal(t, map[string]any{"service.name": "billing", "tenant.id": "acme"}, acme.Resource().Attributes().AsRaw())
+	assert.Equal(t, "audit", acme.ScopeLogs().At(0).Scope().Name())
+
+	metadatatest.AssertEqualConnectorAuditRoutingRecords(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 2, Attributes: attribute.NewSet(attribute.String("route", "verified"))},
+		{Value: 2, Attributes: attribute.NewSet(attribute.String("route", "quarantine"))},
+		{Value: 1, Attributes: attribute.NewSet(attribute.String("route", "acme"))},
+		{Value: 1, Attributes: attribute.NewSet(attribute.String("route", "default"))},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestSenderIntegrityStatusIgnored(t *testing.T) {
+	conn, s := newTestConnector(t, testConfig(), nil)
+
+	// Only the verifier sets the status, on the record: a status claimed by
+	// the sender on the resource or scope does not make a record verified.
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "api")
+	rl.Resource().Attributes().PutStr("audit.integrity.status", "verified")
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().Attributes().PutStr("audit.integrity.status", "verified")
+	lr := sl.LogRecords().AppendEmpty()
+	lr.Body().SetStr("forged")
+	lr.Attributes().PutStr("audit.classification", "security")
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), ld))
+	assert.Empty(t, bodies(s[wormPipeline]))
+	assert.Equal(t, map[string][]string{"api": {"forged"}}, bodies(s[quarantinePipeline]))
+}
+
+func TestRoutingErrors(t *testing.T) {
+	s := map[pipeline.ID]consumer.Logs{
+		wormPipeline:       consumertest.NewErr(errors.New("disk full")),
+		quarantinePipeline: new(consumertest.LogsSink),
+		acmePipeline:       new(consumertest.LogsSink),
+		defaultPipeline:    new(consumertest.LogsSink),
+	}
+	conn, err := NewFactory().CreateLogsToLogs(t.Context(), connectortest.NewNopSettings(metadata.Type), testConfig(), connector.NewLogsRouter(s))
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lr := lrs.AppendEmpty()
+	lr.Attributes().PutStr("audit.integrity.status", "verified")
+	lr.Attributes().PutStr("audit.classification", "security")
+	lrs.AppendEmpty().Attributes().PutStr("audit.integrity.status", "failed")
+
+	assert.EqualError(t, conn.ConsumeLogs(t.Context(), ld), "disk full")
+	// Other routes are still delivered.
+	assert.Equal(t, 1, s[quarantinePipeline].(*consumertest.LogsSink).LogRecordCount())
+}
+
+func TestUnknownPipeline(t *testing.T) {
+	cfg := testConfig()
+	cfg.DefaultPipelines = []pipeline.ID{pipeline.NewIDWithName(pipeline.SignalLogs, "missing")}
+	_, err := NewFactory().CreateLogsToLogs(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg,
+		connector.NewLogsRouter(map[pipeline.ID]consumer.Logs{wormPipeline: consumertest.NewNop()}))
+	assert.Error(t, err)
+}
+
+func TestUnexpectedConsumer(t *testing.T) {
+	_, err := NewFactory().CreateLogsToLogs(t.Context(), connectortest.NewNopSettings(metadata.Type), testConfig(), consumertest.NewNop())
+	assert.ErrorIs(t, err, errUnexpectedConsumer)
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package auditroutingconnector routes audit log records to pipelines by
+// their integrity verification status, classification and tenant.
+package auditroutingconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditroutingconnector"
@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditroutingconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditroutingconnector"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/auditroutingconnector/internal/metadata"
+)
+
+// NewFactory returns a new factory for the audit routing connector.
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		connector.WithLogsToLogs(createLogsToLogs, metadata.LogsToLogsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Attributes: AttributesConfig{
+			Integrity:      "audit.integrity.status",
+			Classification: "audit.classification",
+			Route:          "audit.route",
+			Tenant:         "tenant.id",
+		},
+	}
+}
+
+func createLogsToLogs(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (connector.Logs, error) {
+	return newAuditRouting(set, cfg.(*Config), nextConsumer)
+}
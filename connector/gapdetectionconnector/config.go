@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gapdetectionconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/gapdetectionconnector"
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines the configuration for the gap detection connector.
+type Config struct {
+	// StreamAttributes are the attribute keys whose values identify the
+	// stream of a record, looked up on the record and then on its resource.
+	// They must match the stream attributes used to assign the numbers.
+	// Default: ["tenant.id", "service.name"].
+	StreamAttributes []string `mapstructure:"stream_attributes"`
+
+	// SequenceAttribute is the record attribute holding the sequence number.
+	// Records without it are ignored. Default: "log.record.sequence".
+	SequenceAttribute string `mapstructure:"sequence_attribute"`
+
+	// MaxStreams bounds the number of streams whose highest number is held in
+	// memory. When the limit is reached the least recently used stream is
+	// evicted: its number is read again from storage when it comes back, or
+	// its next record sets a new starting point without storage. 0 means
+	// unlimited. Default: 100000.
+	MaxStreams int `mapstructure:"max_streams"`
+
+	// Storage is the ID of a storage extension persisting the last sequence
+	// number seen per stream, so that gaps spanning a restart of the collector
+	// are detected. Optional.
+	Storage *component.ID `mapstructure:"storage"`
+}
+
+func (c *Config) Validate() error {
+	if slices.Contains(c.StreamAttributes, "") {
+		return errors.New("stream_attributes must not contain empty keys")
+	}
+	if c.SequenceAttribute == "" {
+		return errors.New("sequence_attribute must not be empty")
+	}
+	if c.MaxStreams < 0 {
+		return fmt.Errorf("max_streams must be >= 0, got %d", c.MaxStreams)
+	}
+	return nil
+}
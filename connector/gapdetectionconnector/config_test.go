@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gapdetectionconnector
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/gapdetectionconnector/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	storageID := component.MustNewID("file_storage")
+	tests := []struct {
+		id          component.ID
+		expected    *Config
+		expectedErr string
+	}{
+		{
+			id:       component.NewID(metadata.Type),
+			expected: createDefaultConfig().(*Config),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "custom"),
+			expected: &Config{
+				StreamAttributes:  []string{"k8s.namespace.name"},
+				SequenceAttribute: "audit.sequence",
+				MaxStreams:        1000,
+				Storage:           &storageID,
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "empty_stream_attribute"),
+			expectedErr: "stream_attributes must not contain empty keys",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "empty_sequence_attribute"),
+			expectedErr: "sequence_attribute must not be empty",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "negative_max_streams"),
+			expectedErr: "max_streams must be >= 0, got -1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gapdetectionconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/gapdetectionconnector"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/gapdetectionconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+)
+
+const (
+	eventNameGap        = "audit.sequence.gap"
+	eventNameOutOfOrder = "audit.sequence.out_of_order"
+)
+
+// gapConnector is the connector of a signal. The connectors of both signals
+// share a detector.
+type gapConnector struct {
+	*sharedcomponent.SharedComponent
+	detector *detector
+	signal   pipeline.Signal
+}
+
+func (*gapConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (c *gapConnector) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return c.detector.consume(ctx, ld, c.signal)
+}
+
+// consume checks a batch received by the connector of signal. The connectors
+// of both signals receive every batch: when both are used, the batch is only
+// checked once, when received by the logs connector, and the detected gaps are
+// reported to both pipelines.
+func (d *detector) consume(ctx context.Context, ld plog.Logs, signal pipeline.Signal) error {
+	if signal == pipeline.SignalMetrics && d.logsConsumer != nil {
+		return nil
+	}
+	events := d.detect(ctx, ld)
+	if len(events) == 0 {
+		return nil
+	}
+	var errs error
+	if d.metricsConsumer != nil {
+		errs = errors.Join(errs, d.metricsConsumer.ConsumeMetrics(ctx, gapMetrics(events)))
+	}
+	if d.logsConsumer != nil {
+		errs = errors.Join(errs, d.logsConsumer.ConsumeLogs(ctx, gapLogs(events)))
+	}
+	return errs
+}
+
+// gapMetrics returns delta sums counting the gaps and out-of-order records per
+// stream.
+func gapMetrics(events []event) pmetric.Metrics {
+	type counts struct {
+		stream                    pcommon.Map
+		gaps, missing, outOfOrder int64
+	}
+	byStream := map[[16]byte]*counts{}
+	for _, e := range events {
+		key := pdatautil.MapHash(e.stream)
+		cs, ok := byStream[key]
+		if !ok {
+			cs = &counts{stream: e.stream}
+			byStream[key] = cs
+		}
+		switch e.kind {
+		case kindGap:
+			cs.gaps++
+			cs.missing += int64(e.last - e.first + 1)
+		case kindOutOfOrder:
+			cs.outOfOrder++
+		}
+	}
+
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(metadata.ScopeName)
+	now := pcommon.NewTimestampFromTime(time.Now())
+	for _, m := range []struct {
+		name, desc, unit string
+		value            func(*counts) int64
+	}{
+		{"audit.sequence.gaps", "The number of gaps detected in sequence numbers.", "{gap}", func(cs *counts) int64 { return cs.gaps }},
+		{"audit.sequence.missing", "The number of sequence numbers missing in detected gaps.", "{record}", func(cs *counts) int64 { return cs.missing }},
+		{"audit.sequence.out_of_order", "The number of records received with a sequence number below the highest seen.", "{record}", func(cs *counts) int64 { return cs.outOfOrder }},
+	} {
+		metric := sm.Metrics().AppendEmpty()
+		metric.SetName(m.name)
+		metric.SetDescription(m.desc)
+		metric.SetUnit(m.unit)
+		sum := metric.SetEmptySum()
+		sum.SetIsMonotonic(true)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		for _, cs := range byStream {
+			dp := sum.DataPoints().AppendEmpty()
+			cs.stream.CopyTo(dp.Attributes())
+			dp.SetIntValue(m.value(cs))
+			dp.SetStartTimestamp(now)
+			dp.SetTimestamp(now)
+		}
+	}
+	return md
+}
+
+// gapLogs returns a gap report log record per event.
+func gapLogs(events []event) plog.Logs {
+	out := plog.NewLogs()
+	sl := out.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	sl.Scope().SetName(metadata.ScopeName)
+	now := pcommon.NewTimestampFromTime(time.Now())
+	for _, e := range events {
+		lr := sl.LogRecords().AppendEmpty()
+		lr.SetTimestamp(now)
+		lr.SetObservedTimestamp(now)
+		lr.SetSeverityNumber(plog.SeverityNumberWarn)
+		lr.SetSeverityText("WARN")
+		e.stream.CopyTo(lr.Attributes())
+		switch e.kind {
+		case kindGap:
+			lr.SetEventName(eventNameGap)
+			lr.Body().SetStr(fmt.Sprintf("Missing sequence numbers %d to %d", e.first, e.last))
+			lr.Attributes().PutInt("audit.sequence.gap.first", int64(e.first))
+			lr.Attributes().PutInt("audit.sequence.gap.last", int64(e.last))
+			lr.Attributes().PutInt("audit.sequence.gap.missing", int64(e.last-e.first+1))
+		case kindOutOfOrder:
+			lr.SetEventName(eventNameOutOfOrder)
+			lr.Body().SetStr(fmt.Sprintf("Received sequence number %d, expected %d", e.sequence, e.expected))
+			lr.Attributes().PutInt("audit.sequence.received", int64(e.sequence))
+			lr.Attributes().PutInt("audit.sequence.expected", int64(e.expected))
+		}
+	}
+	return out
+}
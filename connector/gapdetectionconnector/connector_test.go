@@ -0,0 +1,236 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gapdetectionconnector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/connector/connectortest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/gapdetectionconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+func storageID() *component.ID {
+	id := storagetest.NewStorageID("test")
+	return &id
+}
+
+// makeLogs creates one record per sequence number in the stream of tenant,
+// for service "api".
+func makeLogs(tenant string, seqs ...int64) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "api")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, seq := range seqs {
+		lr := lrs.AppendEmpty()
+		lr.Attributes().PutStr("tenant.id", tenant)
+		lr.Attributes().PutInt("log.record.sequence", seq)
+	}
+	return ld
+}
+
+func newLogsToLogs(t *testing.T, cfg *Config, sink *consumertest.LogsSink, host component.Host) connector.Logs {
+	t.Helper()
+	// The ID must be stable for the storage client to be reopened.
+	set := connectortest.NewNopSettings(metadata.Type)
+	set.ID = component.NewID(metadata.Type)
+	conn, err := NewFactory().CreateLogsToLogs(t.Context(), set, cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), host))
+	return conn
+}
+
+func TestDetect(t *testing.T) {
+	d := newDetector(connectortest.NewNopSettings(metadata.Type), createDefaultConfig().(*Config))
+	require.NoError(t, d.Start(t.Context(), componenttest.NewNopHost()))
+
+	assert.Empty(t, d.detect(t.Context(), makeLogs("acme", 3, 4, 5)))
+
+	ld := makeLogs("acme", 6, 9, 7, 10)
+	lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().AppendEmpty()
+	lr.Attributes().PutStr("tenant.id", "acme")
+	events := d.detect(t.Context(), ld)
+	require.Len(t, events, 2)
+	assert.Equal(t, kindGap, events[0].kind)
+	assert.Equal(t, uint64(7), events[0].first)
+	assert.Equal(t, uint64(8), events[0].last)
+	assert.Equal(t, map[string]any{"tenant.id": "acme", "service.name": "api"}, events[0].stream.AsRaw())
+	assert.Equal(t, kindOutOfOrder, events[1].kind)
+	assert.Equal(t, uint64(7), events[1].sequence)
+	assert.Equal(t, uint64(10), events[1].expected)
+
+	// Streams are tracked independently.
+	assert.Empty(t, d.detect(t.Context(), makeLogs("globex", 1)))
+	events = d.detect(t.Context(), makeLogs("globex", 3))
+	require.Len(t, events, 1)
+	assert.Equal(t, uint64(2), events[0].first)
+	require.NoError(t, d.Shutdown(t.Context()))
+}
+
+func TestLogsToMetrics(t *testing.T) {
+	sink := &consumertest.MetricsSink{}
+	conn, err := NewFactory().CreateLogsToMetrics(t.Context(), connectortest.NewNopSettings(metadata.Type), createDefaultConfig(), sink)
+	require.NoError(t, err)
+	require.NoError(t, conn.Start(t.Context(), componenttest.NewNopHost()))
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), makeLogs("acme", 1, 2)))
+	assert.Empty(t, sink.AllMetrics())
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), makeLogs("acme", 5, 9, 3)))
+	require.Len(t, sink.AllMetrics(), 1)
+	values := map[string]int64{}
+	metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		m := metrics.At(i)
+		assert.Equal(t, pmetric.AggregationTemporalityDelta, m.Sum().AggregationTemporality())
+		require.Equal(t, 1, m.Sum().DataPoints().Len())
+		dp := m.Sum().DataPoints().At(0)
+		assert.Equal(t, map[string]any{"tenant.id": "acme", "service.name": "api"}, dp.Attributes().AsRaw())
+		values[m.Name()] = dp.IntValue()
+	}
+	assert.Equal(t, map[string]int64{
+		"audit.sequence.gaps":         2,
+		"audit.sequence.missing":      5,
+		"audit.sequence.out_of_order": 1,
+	}, values)
+	require.NoError(t, conn.Shutdown(t.Context()))
+}
+
+func TestLogsToLogs(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	conn := newLogsToLogs(t, createDefaultConfig().(*Config), sink, componenttest.NewNopHost())
+
+	require.NoError(t, conn.ConsumeLogs(t.Context(), makeLogs("acme", 1, 4, 2)))
+	require.Len(t, sink.AllLogs(), 1)
+	lrs := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, lrs.Len())
+
+	gap := lrs.At(0)
+	assert.Equal(t, eventNameGap, gap.EventName())
+	assert.Equal(t, plog.SeverityNumberWarn, gap.SeverityNumber())
+	assert.Equal(t, "Missing sequence numbers 2 to 3", gap.Body().Str())
+	assert.Equal(t, map[string]any{
+		"tenant.id":                  "acme",
+		"service.name":               "api",
+		"audit.sequence.gap.first":   int64(2),
+		"audit.sequence.gap.last":    int64(3),
+		"audit.sequence.gap.missing": int64(2),
+	}, gap.Attributes().AsRaw())
+
+	outOfOrder := lrs.At(1)
+	assert.Equal(t, eventNameOutOfOrder, outOfOrder.EventName())
+	assert.Equal(t, "Received sequence number 2, expected 5", outOfOrder.Body().Str())
+	v, _ := outOfOrder.Attributes().Get("audit.sequence.expected")
+	assert.Equal(t, pcommon.NewValueInt(5), v)
+	require.NoError(t, conn.Shutdown(t.Context()))
+}
+
+func TestSharedDetector(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = storageID()
+	set := connectortest.NewNopSettings(metadata.Type)
+	set.ID = component.NewID(metadata.Type)
+
+	metricsSink := &consumertest.MetricsSink{}
+	metricsConn, err := NewFactory().CreateLogsToMetrics(t.Context(), set, cfg, metricsSink)
+	require.NoError(t, err)
+	logsSink := &consumertest.LogsSink{}
+	logsConn, err := NewFactory().CreateLogsToLogs(t.Context(), set, cfg, logsSink)
+	require.NoError(t, err)
+	require.NoError(t, metricsConn.Start(t.Context(), host))
+	require.NoError(t, logsConn.Start(t.Context(), host))
+
+	// Both connectors receive every batch, which is checked once.
+	for _, ld := range []plog.Logs{makeLogs("acme", 1, 2), makeLogs("acme", 4)} {
+		require.NoError(t, metricsConn.ConsumeLogs(t.Context(), ld))
+		require.NoError(t, logsConn.ConsumeLogs(t.Context(), ld))
+	}
+	require.Len(t, metricsSink.AllMetrics(), 1)
+	require.Len(t, logsSink.AllLogs(), 1)
+	lrs := logsSink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, lrs.Len())
+	assert.Equal(t, "Missing sequence numbers 3 to 3", lrs.At(0).Body().Str())
+
+	require.NoError(t, metricsConn.Shutdown(t.Context()))
+	require.NoError(t, logsConn.Shutdown(t.Context()))
+}
+
+func TestPersistsAcrossRestarts(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = storageID()
+
+	sink := &consumertest.LogsSink{}
+	conn := newLogsToLogs(t, cfg, sink, host)
+	require.NoError(t, conn.ConsumeLogs(t.Context(), makeLogs("acme", 1, 2, 3)))
+	require.NoError(t, conn.Shutdown(t.Context()))
+	assert.Empty(t, sink.AllLogs())
+
+	conn = newLogsToLogs(t, cfg, sink, host)
+	require.NoError(t, conn.ConsumeLogs(t.Context(), makeLogs("acme", 6)))
+	require.Len(t, sink.AllLogs(), 1)
+	gap := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "Missing sequence numbers 4 to 5", gap.Body().Str())
+	require.NoError(t, conn.Shutdown(context.Background()))
+}
+
+func TestEvictsLeastRecentlyUsedStreams(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage bool
+	}{
+		{name: "memory"},
+		{name: "storage", storage: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.MaxStreams = 1
+			host := component.Host(componenttest.NewNopHost())
+			if tt.storage {
+				cfg.Storage = storageID()
+				host = storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+			}
+			d := newDetector(connectortest.NewNopSettings(metadata.Type), cfg)
+			require.NoError(t, d.Start(t.Context(), host))
+			t.Cleanup(func() { require.NoError(t, d.Shutdown(context.Background())) })
+
+			assert.Empty(t, d.detect(t.Context(), makeLogs("acme", 1, 2)))
+			assert.Empty(t, d.detect(t.Context(), makeLogs("globex", 1)))
+			assert.Len(t, d.last, 1)
+
+			// An evicted stream is read again from storage, without storage
+			// its next record sets a new starting point.
+			events := d.detect(t.Context(), makeLogs("acme", 5))
+			if !tt.storage {
+				assert.Empty(t, events)
+				return
+			}
+			require.Len(t, events, 1)
+			assert.Equal(t, uint64(3), events[0].first)
+			assert.Equal(t, uint64(4), events[0].last)
+		})
+	}
+}
+
+func TestMissingStorage(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = storageID()
+	conn, err := NewFactory().CreateLogsToLogs(t.Context(), connectortest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.ErrorContains(t, conn.Start(t.Context(), componenttest.NewNopHost()), `storage extension "test_storage/test" not found`)
+}
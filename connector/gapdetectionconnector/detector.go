@@ -0,0 +1,224 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gapdetectionconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/gapdetectionconnector"
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+const (
+	kindGap        = "gap"
+	kindOutOfOrder = "out_of_order"
+
+	storageKeyPrefix = "stream/"
+)
+
+// event is a gap or an out-of-order record detected in a stream.
+type event struct {
+	kind string
+	// stream holds the stream attributes found on the record.
+	stream pcommon.Map
+	// first and last delimit the missing numbers of a gap.
+	first, last uint64
+	// sequence is the number of an out-of-order record, expected the number
+	// following the highest seen.
+	sequence, expected uint64
+}
+
+// detector tracks the highest sequence number seen per stream, and reports
+// gaps to the consumers of the connectors using it.
+type detector struct {
+	config      *Config
+	componentID component.ID
+	logger      *zap.Logger
+
+	metricsConsumer consumer.Metrics
+	logsConsumer    consumer.Logs
+
+	mu sync.Mutex
+	// last holds the highest number seen per stream by key, in recently used
+	// order, the least recently used at the back.
+	last          map[string]*list.Element
+	recent        *list.List
+	storageClient storage.Client
+}
+
+// streamLast is the highest number seen in a stream.
+type streamLast struct {
+	key string
+	seq uint64
+}
+
+func newDetector(set connector.Settings, cfg *Config) *detector {
+	return &detector{
+		config:      cfg,
+		componentID: set.ID,
+		logger:      set.Logger,
+		last:        map[string]*list.Element{},
+		recent:      list.New(),
+	}
+}
+
+// Start opens the storage client when configured. The last numbers are loaded
+// from storage when their stream is first seen.
+func (d *detector) Start(ctx context.Context, host component.Host) error {
+	if d.config.Storage == nil {
+		return nil
+	}
+	var err error
+	d.storageClient, err = getStorageClient(ctx, host, d.config.Storage, d.componentID)
+	if err != nil {
+		return fmt.Errorf("failed to get storage client: %w", err)
+	}
+	return nil
+}
+
+// Shutdown closes the storage client.
+func (d *detector) Shutdown(ctx context.Context) error {
+	if d.storageClient == nil {
+		return nil
+	}
+	return d.storageClient.Close(ctx)
+}
+
+// detect checks the sequence numbers of ld, in order, and returns the gaps and
+// out-of-order records found. The first record seen in a stream only sets its
+// starting point.
+func (d *detector) detect(ctx context.Context, ld plog.Logs) []event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var events []event
+	touched := map[string]bool{}
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lr := lrs.At(k)
+				v, ok := lr.Attributes().Get(d.config.SequenceAttribute)
+				if !ok || v.Type() != pcommon.ValueTypeInt || v.Int() <= 0 {
+					continue
+				}
+				seq := uint64(v.Int())
+				key, stream := d.stream(lr.Attributes(), rl.Resource().Attributes())
+
+				last, seen := d.lastSeen(ctx, key)
+				switch {
+				case !seen:
+				case seq <= last:
+					events = append(events, event{kind: kindOutOfOrder, stream: stream, sequence: seq, expected: last + 1})
+					continue
+				case seq > last+1:
+					events = append(events, event{kind: kindGap, stream: stream, first: last + 1, last: seq - 1})
+				}
+				d.setLast(key, seq)
+				touched[key] = true
+			}
+		}
+	}
+	d.persist(ctx, touched)
+	d.evict()
+	return events
+}
+
+// stream returns the key and the attributes of the stream of a record.
+func (d *detector) stream(recordAttrs, resourceAttrs pcommon.Map) (string, pcommon.Map) {
+	attrs := pcommon.NewMap()
+	values := make([]string, len(d.config.StreamAttributes))
+	for i, key := range d.config.StreamAttributes {
+		v, ok := recordAttrs.Get(key)
+		if !ok {
+			v, ok = resourceAttrs.Get(key)
+		}
+		if ok {
+			values[i] = v.AsString()
+			attrs.PutStr(key, values[i])
+		}
+	}
+	// A JSON array keeps the key unambiguous whatever the values contain.
+	key, _ := json.Marshal(values)
+	return string(key), attrs
+}
+
+// lastSeen returns the highest number seen in a stream, loading it from
+// storage when the stream was not seen since startup. Must be called with
+// d.mu held.
+func (d *detector) lastSeen(ctx context.Context, key string) (uint64, bool) {
+	if elem, ok := d.last[key]; ok {
+		return elem.Value.(*streamLast).seq, true
+	}
+	if d.storageClient == nil {
+		return 0, false
+	}
+	data, err := d.storageClient.Get(ctx, storageKeyPrefix+key)
+	if err != nil {
+		d.logger.Warn("Failed to read the last sequence number of a stream", zap.String("stream", key), zap.Error(err))
+		return 0, false
+	}
+	if len(data) != 8 {
+		return 0, false
+	}
+	last := binary.BigEndian.Uint64(data)
+	d.setLast(key, last)
+	return last, true
+}
+
+// setLast sets the highest number seen in a stream and marks it as the most
+// recently used. Must be called with d.mu held.
+func (d *detector) setLast(key string, seq uint64) {
+	if elem, ok := d.last[key]; ok {
+		elem.Value.(*streamLast).seq = seq
+		d.recent.MoveToFront(elem)
+		return
+	}
+	d.last[key] = d.recent.PushFront(&streamLast{key: key, seq: seq})
+}
+
+// evict removes the least recently used streams beyond max_streams. It is
+// called once a batch was persisted, so that the streams of a batch are never
+// evicted before their numbers are written. Must be called with d.mu held.
+func (d *detector) evict() {
+	evicted := 0
+	for d.config.MaxStreams > 0 && len(d.last) > d.config.MaxStreams {
+		oldest := d.recent.Remove(d.recent.Back()).(*streamLast)
+		delete(d.last, oldest.key)
+		evicted++
+	}
+	if evicted > 0 {
+		d.logger.Debug("Evicted streams from memory", zap.Int("count", evicted))
+	}
+}
+
+// persist writes the last numbers of the touched streams. Failures are
+// logged: detection goes on from the in-memory state. Must be called with d.mu
+// held.
+func (d *detector) persist(ctx context.Context, touched map[string]bool) {
+	if d.storageClient == nil || len(touched) == 0 {
+		return
+	}
+	ops := make([]*storage.Operation, 0, len(touched))
+	for key := range touched {
+		seq := d.last[key].Value.(*streamLast).seq
+		ops = append(ops, storage.SetOperation(storageKeyPrefix+key, binary.BigEndian.AppendUint64(nil, seq)))
+	}
+	if err := d.storageClient.Batch(ctx, ops...); err != nil {
+		d.logger.Warn("Failed to persist the last sequence numbers", zap.Error(err))
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package gapdetectionconnector detects missing and out-of-order sequence
+// numbers in sequenced audit log streams.
+package gapdetectionconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/gapdetectionconnector"
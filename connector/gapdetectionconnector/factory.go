@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gapdetectionconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/gapdetectionconnector"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/gapdetectionconnector/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
+)
+
+// NewFactory returns a new factory for the gap detection connector.
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		connector.WithLogsToMetrics(createLogsToMetrics, metadata.LogsToMetricsStability),
+		connector.WithLogsToLogs(createLogsToLogs, metadata.LogsToLogsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		StreamAttributes:  []string{"tenant.id", "service.name"},
+		SequenceAttribute: "log.record.sequence",
+		MaxStreams:        100000,
+	}
+}
+
+func createLogsToMetrics(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (connector.Logs, error) {
+	shared, d := getDetector(set, cfg.(*Config))
+	d.metricsConsumer = nextConsumer
+	return &gapConnector{SharedComponent: shared, detector: d, signal: pipeline.SignalMetrics}, nil
+}
+
+func createLogsToLogs(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (connector.Logs, error) {
+	shared, d := getDetector(set, cfg.(*Config))
+	d.logsConsumer = nextConsumer
+	return &gapConnector{SharedComponent: shared, detector: d, signal: pipeline.SignalLogs}, nil
+}
+
+// getDetector returns the detector shared by the connectors of both signals,
+// so that a stream is tracked and persisted once.
+func getDetector(set connector.Settings, cfg *Config) (*sharedcomponent.SharedComponent, *detector) {
+	shared := detectors.GetOrAdd(cfg, func() component.Component {
+		return newDetector(set, cfg)
+	})
+	return shared, shared.Unwrap().(*detector)
+}
+
+// detectors holds the detectors in use by configuration. It is not safe for
+// concurrent use, connectors are created one at a time.
+var detectors = sharedcomponent.NewSharedComponents()
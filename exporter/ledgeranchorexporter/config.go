@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgeranchorexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/ledgeranchorexporter"
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+)
+
+var addressRegexp = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// Config defines configuration for the ledger anchor exporter.
+type Config struct {
+	// Interval at which the Merkle root of the records received since the
+	// previous anchor is written to the ledger.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// HashAttribute names a log record attribute holding a precomputed hash
+	// of the record, such as the head of a hash chain. When set, its value is
+	// used as the Merkle leaf instead of a hash of the whole record.
+	HashAttribute string `mapstructure:"hash_attribute"`
+
+	// Storage is the ID of the storage extension in which pending leaves and
+	// anchor receipts are recorded. Receipts hold the transaction ID and the
+	// leaves of every anchored tree, which is what existence proofs are built
+	// from.
+	Storage component.ID `mapstructure:"storage"`
+
+	// MaxPendingRecords bounds the number of records waiting to be anchored.
+	// When it is reached, new records are refused until the ledger catches up.
+	MaxPendingRecords int `mapstructure:"max_pending_records"`
+
+	// Ethereum anchors roots by calling a contract on an Ethereum-compatible
+	// chain.
+	Ethereum configoptional.Optional[EthereumConfig] `mapstructure:"ethereum"`
+
+	// Fabric anchors roots by invoking a Hyperledger Fabric chaincode through
+	// a REST gateway.
+	Fabric configoptional.Optional[FabricConfig] `mapstructure:"fabric"`
+}
+
+// EthereumConfig defines how roots are anchored to an Ethereum-compatible
+// chain through its JSON-RPC API.
+type EthereumConfig struct {
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	// From is the account sending the anchoring transactions. It must be
+	// unlocked in, or managed by, the node or signer behind the endpoint.
+	From string `mapstructure:"from"`
+	// Contract is the address of the anchoring contract.
+	Contract string `mapstructure:"contract"`
+	// Method is the signature of the contract method called with the root.
+	// It must take a single bytes32 argument.
+	Method string `mapstructure:"method"`
+	// Gas limit of the anchoring transactions. When 0 the node estimates it.
+	Gas uint64 `mapstructure:"gas"`
+	// ConfirmationTimeout bounds the time waited for the transaction receipt
+	// of an anchoring transaction.
+	ConfirmationTimeout time.Duration `mapstructure:"confirmation_timeout"`
+}
+
+// FabricConfig defines how roots are anchored to a Hyperledger Fabric
+// channel through a REST gateway.
+type FabricConfig struct {
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	// Channel the chaincode is deployed on.
+	Channel string `mapstructure:"channel"`
+	// Chaincode is the name of the anchoring chaincode.
+	Chaincode string `mapstructure:"chaincode"`
+	// Function of the chaincode invoked with the hex encoded root.
+	Function string `mapstructure:"function"`
+}
+
+// Validate checks if the exporter configuration is valid.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.Interval <= 0 {
+		errs = append(errs, errors.New("interval must be positive"))
+	}
+	if cfg.Storage.Type().String() == "" {
+		errs = append(errs, errors.New("storage must be specified"))
+	}
+	if cfg.MaxPendingRecords <= 0 {
+		errs = append(errs, errors.New("max_pending_records must be positive"))
+	}
+	switch {
+	case cfg.Ethereum.HasValue() && cfg.Fabric.HasValue():
+		errs = append(errs, errors.New("only one of ethereum or fabric can be configured"))
+	case !cfg.Ethereum.HasValue() && !cfg.Fabric.HasValue():
+		errs = append(errs, errors.New("one of ethereum or fabric must be configured"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks if the Ethereum backend configuration is valid.
+func (cfg *EthereumConfig) Validate() error {
+	var errs []error
+	if cfg.Endpoint == "" {
+		errs = append(errs, errors.New("endpoint must be specified"))
+	}
+	if !addressRegexp.MatchString(cfg.From) {
+		errs = append(errs, errors.New("from must be a hex encoded address"))
+	}
+	if !addressRegexp.MatchString(cfg.Contract) {
+		errs = append(errs, errors.New("contract must be a hex encoded address"))
+	}
+	if cfg.Method == "" {
+		errs = append(errs, errors.New("method must be specified"))
+	}
+	if cfg.ConfirmationTimeout <= 0 {
+		errs = append(errs, errors.New("confirmation_timeout must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks if the Fabric backend configuration is valid.
+func (cfg *FabricConfig) Validate() error {
+	var errs []error
+	if cfg.Endpoint == "" {
+		errs = append(errs, errors.New("endpoint must be specified"))
+	}
+	if cfg.Channel == "" {
+		errs = append(errs, errors.New("channel must be specified"))
+	}
+	if cfg.Chaincode == "" {
+		errs = append(errs, errors.New("chaincode must be specified"))
+	}
+	if cfg.Function == "" {
+		errs = append(errs, errors.New("function must be specified"))
+	}
+	return errors.Join(errs...)
+}
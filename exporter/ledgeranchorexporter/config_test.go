@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgeranchorexporter
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/ledgeranchorexporter/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	storageID := component.MustNewID("file_storage")
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr string
+	}{
+		{
+			id:          component.NewID(metadata.Type),
+			expectedErr: "one of ethereum or fabric must be configured",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "ethereum"),
+			expected: func() *Config {
+				clientConfig := confighttp.NewDefaultClientConfig()
+				clientConfig.Endpoint = "http://localhost:8545"
+				cfg := createDefaultConfig().(*Config)
+				cfg.Interval = 10 * time.Minute
+				cfg.HashAttribute = "log.record.chain_head"
+				cfg.Storage = storageID
+				cfg.MaxPendingRecords = 5000
+				cfg.Ethereum = configoptional.Some(EthereumConfig{
+					ClientConfig:        clientConfig,
+					From:                "0x8ba1f109551bD432803012645Ac136ddd64DBA72",
+					Contract:            "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+					Method:              "anchor(bytes32)",
+					Gas:                 100000,
+					ConfirmationTimeout: 10 * time.Minute,
+				})
+				return cfg
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "fabric"),
+			expected: func() *Config {
+				clientConfig := confighttp.NewDefaultClientConfig()
+				clientConfig.Endpoint = "https://fabric-gateway.example.com/invoke"
+				cfg := createDefaultConfig().(*Config)
+				cfg.Storage = storageID
+				cfg.Fabric = configoptional.Some(FabricConfig{
+					ClientConfig: clientConfig,
+					Channel:      "audit",
+					Chaincode:    "anchors",
+					Function:     "Anchor",
+				})
+				return cfg
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "both"),
+			expectedErr: "only one of ethereum or fabric can be configured",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_address"),
+			expectedErr: "from must be a hex encoded address",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "missing_channel"),
+			expectedErr: "channel must be specified",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "missing_channel"),
+			expectedErr: "interval must be positive",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "missing_channel"),
+			expectedErr: "storage must be specified",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_limits"),
+			expectedErr: "max_pending_records must be positive",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_limits"),
+			expectedErr: "confirmation_timeout must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
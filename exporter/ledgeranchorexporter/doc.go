@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package ledgeranchorexporter anchors Merkle roots of exported log records
+// to a ledger backend.
+package ledgeranchorexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/ledgeranchorexporter"
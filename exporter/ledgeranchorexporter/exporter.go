@@ -0,0 +1,352 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgeranchorexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/ledgeranchorexporter"
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/merkle"
+)
+
+const (
+	receiptKeyPrefix = "receipt/"
+	// Pending leaves are stored in batches, one per call to pushLogs, under
+	// consecutive sequence numbers starting at the one in pendingStartKey.
+	pendingKeyPrefix = "pending/"
+	pendingStartKey  = "pending_start"
+)
+
+// unsavedReceipt is a receipt of an anchored root that could not be written
+// to storage yet, with the end of the pending batches it replaces.
+type unsavedReceipt struct {
+	receipt merkle.Receipt
+	end     uint64
+}
+
+// errPendingFull is returned while max_pending_records leaves are waiting to
+// be anchored. It is retryable, so that the sending queue applies
+// backpressure until the ledger catches up.
+var errPendingFull = errors.New("max_pending_records reached, waiting for the ledger to catch up")
+
+type anchorExporter struct {
+	config *Config
+	set    component.TelemetrySettings
+	logger *zap.Logger
+	id     component.ID
+	now    func() time.Time
+
+	ledger        ledger
+	storageClient storage.Client
+
+	// mu guards the pending leaves and their sequence numbers in storage:
+	// batches pendingStart to pendingNext-1 hold the leaves in pending and
+	// the inflight leaves, taken by anchorPending and still in storage until
+	// their receipt is saved.
+	mu           sync.Mutex
+	pending      [][]byte
+	inflight     int
+	pendingStart uint64
+	pendingNext  uint64
+
+	// anchorMu serializes anchoring between the periodic loop and shutdown,
+	// and guards unsaved.
+	anchorMu sync.Mutex
+	unsaved  []unsavedReceipt
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func newAnchorExporter(set exporter.Settings, cfg *Config) *anchorExporter {
+	return &anchorExporter{
+		config: cfg,
+		set:    set.TelemetrySettings,
+		logger: set.Logger,
+		id:     set.ID,
+		now:    time.Now,
+	}
+}
+
+func (e *anchorExporter) start(ctx context.Context, host component.Host) error {
+	var err error
+	if e.ledger, err = e.newLedger(ctx, host); err != nil {
+		return err
+	}
+	if e.storageClient, err = getStorageClient(ctx, host, e.config.Storage, e.id); err != nil {
+		return err
+	}
+	if err = e.loadPending(ctx); err != nil {
+		return err
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	go e.anchorLoop(loopCtx)
+	return nil
+}
+
+func (e *anchorExporter) newLedger(ctx context.Context, host component.Host) (ledger, error) {
+	var client *http.Client
+	var err error
+	if ethCfg := e.config.Ethereum.Get(); ethCfg != nil {
+		if client, err = ethCfg.ToClient(ctx, host.GetExtensions(), e.set); err != nil {
+			return nil, err
+		}
+		return newEthereumLedger(client, ethCfg), nil
+	}
+	fabricCfg := e.config.Fabric.Get()
+	if client, err = fabricCfg.ToClient(ctx, host.GetExtensions(), e.set); err != nil {
+		return nil, err
+	}
+	return newFabricLedger(client, fabricCfg), nil
+}
+
+func (e *anchorExporter) anchorLoop(ctx context.Context) {
+	defer close(e.done)
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.anchorPending(ctx); err != nil {
+				e.logger.Warn("failed to anchor Merkle root, retrying at the next interval", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *anchorExporter) shutdown(ctx context.Context) error {
+	if e.cancel != nil {
+		e.cancel()
+		<-e.done
+		if err := e.anchorPending(ctx); err != nil {
+			e.logger.Error("failed to anchor Merkle root on shutdown, records are anchored after restart",
+				zap.Int("records", e.pendingCount()), zap.Error(err))
+		}
+	}
+	if e.storageClient != nil {
+		return e.storageClient.Close(ctx)
+	}
+	return nil
+}
+
+// pushLogs adds the leaves of the records to the pending leaves. They are
+// written to storage before the records are acknowledged, so that they are
+// anchored after a restart.
+func (e *anchorExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	var leaves [][]byte
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			sl := sls.At(j)
+			lrs := sl.LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				leaf, err := merkle.RecordLeaf(rl, sl, lrs.At(k), e.config.HashAttribute)
+				if err != nil {
+					return err
+				}
+				leaves = append(leaves, leaf)
+			}
+		}
+	}
+
+	if len(leaves) == 0 {
+		return nil
+	}
+	if len(leaves) > e.config.MaxPendingRecords {
+		return consumererror.NewPermanent(fmt.Errorf("batch of %d records exceeds max_pending_records", len(leaves)))
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// Leaves being anchored still count: they are only released from
+	// storage once their receipt is saved.
+	if e.inflight+len(e.pending)+len(leaves) > e.config.MaxPendingRecords {
+		return errPendingFull
+	}
+	if err := e.storageClient.Set(ctx, pendingKey(e.pendingNext), encodeLeaves(leaves)); err != nil {
+		return fmt.Errorf("failed to write pending records to storage: %w", err)
+	}
+	e.pendingNext++
+	e.pending = append(e.pending, leaves...)
+	return nil
+}
+
+func (e *anchorExporter) pendingCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.pending)
+}
+
+// anchorPending anchors the root of the tree over all leaves received since
+// the previous anchor. On failure the leaves are kept and included in the
+// next attempt.
+func (e *anchorExporter) anchorPending(ctx context.Context) error {
+	e.anchorMu.Lock()
+	defer e.anchorMu.Unlock()
+
+	// Receipts that could not be saved are retried first, so that pending
+	// batches are always released in order.
+	for len(e.unsaved) > 0 {
+		if err := e.saveReceipt(ctx, e.unsaved[0]); err != nil {
+			return err
+		}
+		e.unsaved = e.unsaved[1:]
+	}
+
+	e.mu.Lock()
+	leaves := e.pending
+	end := e.pendingNext
+	e.pending = nil
+	e.inflight += len(leaves)
+	e.mu.Unlock()
+	if len(leaves) == 0 {
+		return nil
+	}
+
+	root := merkle.Root(leaves)
+	txID, err := e.ledger.anchor(ctx, root)
+	if err != nil {
+		e.mu.Lock()
+		e.pending = append(leaves, e.pending...)
+		e.inflight -= len(leaves)
+		e.mu.Unlock()
+		return err
+	}
+
+	r := merkle.Receipt{
+		Root:          hex.EncodeToString(root),
+		Backend:       e.ledger.name(),
+		TransactionID: txID,
+		AnchoredAt:    e.now().UTC(),
+		Leaves:        make([]string, len(leaves)),
+	}
+	for i, leaf := range leaves {
+		r.Leaves[i] = hex.EncodeToString(leaf)
+	}
+	e.logger.Info("anchored Merkle root",
+		zap.String("root", r.Root),
+		zap.String("backend", r.Backend),
+		zap.String("transaction_id", txID),
+		zap.Int("records", len(leaves)))
+
+	// The root is anchored: a failure to save the receipt must not cause the
+	// leaves to be anchored again, so the receipt is kept and saved later.
+	u := unsavedReceipt{receipt: r, end: end}
+	if err := e.saveReceipt(ctx, u); err != nil {
+		e.unsaved = append(e.unsaved, u)
+		return err
+	}
+	return nil
+}
+
+// saveReceipt writes the receipt to storage and, in the same batch, removes
+// the pending batches of the leaves it covers.
+func (e *anchorExporter) saveReceipt(ctx context.Context, u unsavedReceipt) error {
+	data, err := json.Marshal(u.receipt)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	start := e.pendingStart
+	e.mu.Unlock()
+	ops := []*storage.Operation{
+		storage.SetOperation(receiptKeyPrefix+u.receipt.Root, data),
+		storage.SetOperation(pendingStartKey, binary.BigEndian.AppendUint64(nil, u.end)),
+	}
+	for seq := start; seq < u.end; seq++ {
+		ops = append(ops, storage.DeleteOperation(pendingKey(seq)))
+	}
+	if err := e.storageClient.Batch(ctx, ops...); err != nil {
+		return fmt.Errorf("failed to record anchor receipt for root %s: %w", u.receipt.Root, err)
+	}
+
+	e.mu.Lock()
+	e.pendingStart = u.end
+	e.inflight -= len(u.receipt.Leaves)
+	e.mu.Unlock()
+	return nil
+}
+
+// loadPending restores the leaves that were not anchored before the
+// exporter was stopped.
+func (e *anchorExporter) loadPending(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	start, err := e.storageClient.Get(ctx, pendingStartKey)
+	if err != nil {
+		return fmt.Errorf("failed to read pending records from storage: %w", err)
+	}
+	if len(start) == 8 {
+		e.pendingStart = binary.BigEndian.Uint64(start)
+	}
+	for e.pendingNext = e.pendingStart; ; e.pendingNext++ {
+		data, err := e.storageClient.Get(ctx, pendingKey(e.pendingNext))
+		if err != nil {
+			return fmt.Errorf("failed to read pending records from storage: %w", err)
+		}
+		if data == nil {
+			break
+		}
+		leaves, err := decodeLeaves(data)
+		if err != nil {
+			return fmt.Errorf("failed to decode pending records %d: %w", e.pendingNext, err)
+		}
+		e.pending = append(e.pending, leaves...)
+	}
+	if len(e.pending) > 0 {
+		e.logger.Info("loaded pending records from storage", zap.Int("records", len(e.pending)))
+	}
+	return nil
+}
+
+func pendingKey(seq uint64) string {
+	return pendingKeyPrefix + strconv.FormatUint(seq, 10)
+}
+
+// encodeLeaves encodes leaves as a sequence of length-prefixed values.
+func encodeLeaves(leaves [][]byte) []byte {
+	var data []byte
+	for _, leaf := range leaves {
+		data = binary.AppendUvarint(data, uint64(len(leaf)))
+		data = append(data, leaf...)
+	}
+	return data
+}
+
+func decodeLeaves(data []byte) ([][]byte, error) {
+	var leaves [][]byte
+	for len(data) > 0 {
+		n, size := binary.Uvarint(data)
+		if size <= 0 || uint64(len(data)-size) < n {
+			return nil, errors.New("truncated leaf")
+		}
+		data = data[size:]
+		leaves = append(leaves, data[:n:n])
+		data = data[n:]
+	}
+	return leaves, nil
+}
@@ -0,0 +1,424 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgeranchorexporter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/ledgeranchorexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/merkle"
+)
+
+// anchorSelector is the selector of anchor(bytes32).
+const anchorSelector = "eecdf927"
+
+// ledgerServer records the requests sent to a fake ledger backend.
+type ledgerServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []map[string]any
+	fail     bool
+}
+
+// newLedgerServer starts a fake ledger backend answering every request with
+// respond(request).
+func newLedgerServer(t *testing.T, respond func(req map[string]any) any) *ledgerServer {
+	s := &ledgerServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.fail {
+			http.Error(w, "ledger unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		s.requests = append(s.requests, req)
+		assert.NoError(t, json.NewEncoder(w).Encode(respond(req)))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// ethereumNode answers eth_sendTransaction with txHash, and
+// eth_getTransactionReceipt with a receipt of the given status once it was
+// asked pendingPolls times.
+func ethereumNode(status string, pendingPolls int) func(map[string]any) any {
+	var polls int
+	return func(req map[string]any) any {
+		switch req["method"] {
+		case "eth_sendTransaction":
+			return map[string]any{"jsonrpc": "2.0", "id": 1, "result": "0xabc123"}
+		case "eth_getTransactionReceipt":
+			polls++
+			if polls <= pendingPolls {
+				return map[string]any{"jsonrpc": "2.0", "id": 1, "result": nil}
+			}
+			return map[string]any{"jsonrpc": "2.0", "id": 1, "result": map[string]any{"status": status}}
+		}
+		return map[string]any{"jsonrpc": "2.0", "id": 1, "error": map[string]any{"code": -32601, "message": "method not found"}}
+	}
+}
+
+func respondWith(response any) func(map[string]any) any {
+	return func(map[string]any) any { return response }
+}
+
+func (s *ledgerServer) setFail(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = fail
+}
+
+func (s *ledgerServer) received() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func ethereumConfig(endpoint string) *Config {
+	clientConfig := confighttp.NewDefaultClientConfig()
+	clientConfig.Endpoint = endpoint
+	cfg := createDefaultConfig().(*Config)
+	cfg.Interval = time.Hour
+	cfg.Storage = storagetest.NewStorageID("test")
+	cfg.Ethereum = configoptional.Some(EthereumConfig{
+		ClientConfig:        clientConfig,
+		From:                "0x8ba1f109551bD432803012645Ac136ddd64DBA72",
+		Contract:            "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+		Method:              "anchor(bytes32)",
+		Gas:                 100000,
+		ConfirmationTimeout: time.Second,
+	})
+	return cfg
+}
+
+func storageHost() component.Host {
+	return storagetest.NewStorageHost().WithInMemoryStorageExtension("test")
+}
+
+func newTestExporter(t *testing.T, cfg *Config, host component.Host) *anchorExporter {
+	t.Helper()
+	set := exportertest.NewNopSettings(metadata.Type)
+	set.ID = component.NewID(metadata.Type)
+	exp := newAnchorExporter(set, cfg)
+	exp.now = func() time.Time { return time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC) }
+	require.NoError(t, exp.start(t.Context(), host))
+	if l, ok := exp.ledger.(*ethereumLedger); ok {
+		l.pollInterval = time.Millisecond
+	}
+	return exp
+}
+
+func startExporter(t *testing.T, cfg *Config, host component.Host) *anchorExporter {
+	t.Helper()
+	exp := newTestExporter(t, cfg, host)
+	t.Cleanup(func() {
+		require.NoError(t, exp.shutdown(context.Background()))
+	})
+	return exp
+}
+
+func makeLogs(bodies ...string) plog.Logs {
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, body := range bodies {
+		lrs.AppendEmpty().Body().SetStr(body)
+	}
+	return ld
+}
+
+func TestAnchorEthereum(t *testing.T) {
+	srv := newLedgerServer(t, ethereumNode("0x1", 2))
+	cfg := ethereumConfig(srv.URL)
+	cfg.HashAttribute = "chain_head"
+	exp := startExporter(t, cfg, storageHost())
+
+	ld := makeLogs("first", "second")
+	lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	lr.Attributes().PutStr("chain_head", "00ff")
+	require.NoError(t, exp.pushLogs(t.Context(), ld))
+	require.NoError(t, exp.anchorPending(t.Context()))
+
+	assert.Zero(t, exp.pendingCount())
+
+	// The second record has no hash attribute and is hashed as a whole.
+	single := plog.NewLogs()
+	ld.ResourceLogs().At(0).CopyTo(single.ResourceLogs().AppendEmpty())
+	single.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().RemoveIf(func(r plog.LogRecord) bool {
+		return r.Body().Str() == "first"
+	})
+	data, err := (&plog.ProtoMarshaler{}).MarshalLogs(single)
+	require.NoError(t, err)
+	secondLeaf := sha256.Sum256(data)
+	leaves := [][]byte{{0x00, 0xff}, secondLeaf[:]}
+	root := hex.EncodeToString(merkle.Root(leaves))
+
+	// The transaction is followed by receipt requests until it is mined.
+	requests := srv.received()
+	require.Len(t, requests, 4)
+	assert.Equal(t, "eth_sendTransaction", requests[0]["method"])
+	for _, req := range requests[1:] {
+		assert.Equal(t, "eth_getTransactionReceipt", req["method"])
+		assert.Equal(t, []any{"0xabc123"}, req["params"])
+	}
+	assert.Equal(t, []any{map[string]any{
+		"from": "0x8ba1f109551bD432803012645Ac136ddd64DBA72",
+		"to":   "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+		"data": "0x" + anchorSelector + root,
+		"gas":  "0x186a0",
+	}}, requests[0]["params"])
+
+	data, err = exp.storageClient.Get(t.Context(), receiptKeyPrefix+root)
+	require.NoError(t, err)
+	var r merkle.Receipt
+	require.NoError(t, json.Unmarshal(data, &r))
+	assert.Equal(t, merkle.Receipt{
+		Root:          root,
+		Backend:       "ethereum",
+		TransactionID: "0xabc123",
+		AnchoredAt:    time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		Leaves:        []string{"00ff", hex.EncodeToString(secondLeaf[:])},
+	}, r)
+
+	// The pending records were released with the receipt.
+	data, err = exp.storageClient.Get(t.Context(), pendingKey(0))
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	// Nothing is anchored when no record was received.
+	require.NoError(t, exp.anchorPending(t.Context()))
+	assert.Len(t, srv.received(), 4)
+}
+
+func TestAnchorEthereumReverted(t *testing.T) {
+	srv := newLedgerServer(t, ethereumNode("0x0", 0))
+	exp := startExporter(t, ethereumConfig(srv.URL), storageHost())
+
+	require.NoError(t, exp.pushLogs(t.Context(), makeLogs("first")))
+	err := exp.anchorPending(t.Context())
+	assert.EqualError(t, err, "anchoring transaction 0xabc123 was reverted")
+	assert.Equal(t, 1, exp.pendingCount())
+}
+
+func TestAnchorEthereumNotMined(t *testing.T) {
+	srv := newLedgerServer(t, ethereumNode("0x1", 1000000))
+	cfg := ethereumConfig(srv.URL)
+	cfg.Ethereum.Get().ConfirmationTimeout = 20 * time.Millisecond
+	exp := startExporter(t, cfg, storageHost())
+
+	require.NoError(t, exp.pushLogs(t.Context(), makeLogs("first")))
+	err := exp.anchorPending(t.Context())
+	assert.ErrorContains(t, err, "anchoring transaction 0xabc123 was not mined")
+	assert.Equal(t, 1, exp.pendingCount())
+}
+
+func TestAnchorEthereumError(t *testing.T) {
+	srv := newLedgerServer(t, respondWith(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"error":   map[string]any{"code": -32000, "message": "insufficient funds"},
+	}))
+	exp := startExporter(t, ethereumConfig(srv.URL), storageHost())
+
+	require.NoError(t, exp.pushLogs(t.Context(), makeLogs("first")))
+	err := exp.anchorPending(t.Context())
+	assert.EqualError(t, err, "eth_sendTransaction failed: insufficient funds (code -32000)")
+	assert.Equal(t, 1, exp.pendingCount())
+}
+
+func TestAnchorRetriesPendingLeaves(t *testing.T) {
+	srv := newLedgerServer(t, respondWith(map[string]any{"transaction_id": "tx-1"}))
+	clientConfig := confighttp.NewDefaultClientConfig()
+	clientConfig.Endpoint = srv.URL
+	cfg := createDefaultConfig().(*Config)
+	cfg.HashAttribute = "chain_head"
+	cfg.Storage = storagetest.NewStorageID("test")
+	cfg.Fabric = configoptional.Some(FabricConfig{
+		ClientConfig: clientConfig,
+		Channel:      "audit",
+		Chaincode:    "anchors",
+		Function:     "Anchor",
+	})
+	exp := startExporter(t, cfg, storageHost())
+
+	withHead := func(head string) plog.Logs {
+		ld := makeLogs("record")
+		ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().PutStr("chain_head", head)
+		return ld
+	}
+
+	srv.setFail(true)
+	require.NoError(t, exp.pushLogs(t.Context(), withHead("01")))
+	assert.ErrorContains(t, exp.anchorPending(t.Context()), "failed with status 503: ledger unavailable")
+
+	srv.setFail(false)
+	require.NoError(t, exp.pushLogs(t.Context(), withHead("02")))
+	require.NoError(t, exp.anchorPending(t.Context()))
+
+	requests := srv.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, map[string]any{
+		"channel":   "audit",
+		"chaincode": "anchors",
+		"function":  "Anchor",
+		"args":      []any{hex.EncodeToString(merkle.Root([][]byte{{0x01}, {0x02}}))},
+	}, requests[0])
+}
+
+func TestAnchorOnShutdown(t *testing.T) {
+	srv := newLedgerServer(t, ethereumNode("0x1", 0))
+	exp := newTestExporter(t, ethereumConfig(srv.URL), storageHost())
+
+	require.NoError(t, exp.pushLogs(t.Context(), makeLogs("first")))
+	require.NoError(t, exp.shutdown(t.Context()))
+	assert.Len(t, srv.received(), 2)
+	assert.Zero(t, exp.pendingCount())
+}
+
+// TestPendingSurvivesRestart verifies that records are written to storage
+// before they are acknowledged, so they are anchored after a crash.
+func TestPendingSurvivesRestart(t *testing.T) {
+	srv := newLedgerServer(t, ethereumNode("0x1", 0))
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+
+	exp1 := newTestExporter(t, ethereumConfig(srv.URL), host)
+	require.NoError(t, exp1.pushLogs(t.Context(), makeLogs("first", "second")))
+	require.NoError(t, exp1.pushLogs(t.Context(), makeLogs("third")))
+
+	// Simulate a crash: release the storage without anchoring.
+	exp1.cancel()
+	<-exp1.done
+	require.NoError(t, exp1.storageClient.Close(t.Context()))
+
+	exp2 := startExporter(t, ethereumConfig(srv.URL), host)
+	assert.Equal(t, 3, exp2.pendingCount())
+	assert.Equal(t, exp1.pending, exp2.pending)
+
+	require.NoError(t, exp2.anchorPending(t.Context()))
+	assert.Zero(t, exp2.pendingCount())
+	assert.Len(t, srv.received(), 2)
+}
+
+func TestMaxPendingRecords(t *testing.T) {
+	srv := newLedgerServer(t, ethereumNode("0x1", 0))
+	cfg := ethereumConfig(srv.URL)
+	cfg.MaxPendingRecords = 2
+	exp := startExporter(t, cfg, storageHost())
+
+	require.NoError(t, exp.pushLogs(t.Context(), makeLogs("first", "second")))
+	err := exp.pushLogs(t.Context(), makeLogs("third"))
+	assert.ErrorIs(t, err, errPendingFull)
+	assert.False(t, consumererror.IsPermanent(err))
+
+	err = exp.pushLogs(t.Context(), makeLogs("first", "second", "third"))
+	assert.True(t, consumererror.IsPermanent(err))
+
+	// Anchoring makes room for new records.
+	require.NoError(t, exp.anchorPending(t.Context()))
+	require.NoError(t, exp.pushLogs(t.Context(), makeLogs("third")))
+}
+
+// TestMaxPendingRecordsInflight verifies that the records being anchored
+// count towards max_pending_records until their receipt is saved.
+func TestMaxPendingRecordsInflight(t *testing.T) {
+	sent := make(chan struct{})
+	release := make(chan struct{})
+	var first sync.Once
+	node := ethereumNode("0x1", 0)
+	srv := newLedgerServer(t, func(req map[string]any) any {
+		if req["method"] == "eth_sendTransaction" {
+			// Hold the first anchor in flight.
+			first.Do(func() {
+				close(sent)
+				<-release
+			})
+		}
+		return node(req)
+	})
+	cfg := ethereumConfig(srv.URL)
+	cfg.MaxPendingRecords = 2
+	exp := startExporter(t, cfg, storageHost())
+	client := exp.storageClient
+
+	require.NoError(t, exp.pushLogs(t.Context(), makeLogs("first", "second")))
+	anchored := make(chan error, 1)
+	go func() { anchored <- exp.anchorPending(context.Background()) }()
+	<-sent
+	assert.ErrorIs(t, exp.pushLogs(t.Context(), makeLogs("third")), errPendingFull)
+	close(release)
+	require.NoError(t, <-anchored)
+	require.NoError(t, exp.pushLogs(t.Context(), makeLogs("third")))
+
+	// Records whose receipt could not be saved are still in storage.
+	exp.storageClient = failingBatchClient{Client: client}
+	require.NoError(t, exp.pushLogs(t.Context(), makeLogs("fourth")))
+	assert.ErrorContains(t, exp.anchorPending(t.Context()), "failed to record anchor receipt")
+	assert.ErrorIs(t, exp.pushLogs(t.Context(), makeLogs("fifth")), errPendingFull)
+
+	exp.storageClient = client
+	require.NoError(t, exp.anchorPending(t.Context()))
+	require.NoError(t, exp.pushLogs(t.Context(), makeLogs("fifth")))
+}
+
+type failingBatchClient struct {
+	storage.Client
+}
+
+func (failingBatchClient) Batch(context.Context, ...*storage.Operation) error {
+	return errors.New("disk full")
+}
+
+// TestReceiptSaveRetried verifies that a receipt that cannot be saved is
+// retried without anchoring the records again.
+func TestReceiptSaveRetried(t *testing.T) {
+	srv := newLedgerServer(t, ethereumNode("0x1", 0))
+	exp := startExporter(t, ethereumConfig(srv.URL), storageHost())
+	client := exp.storageClient
+
+	require.NoError(t, exp.pushLogs(t.Context(), makeLogs("first")))
+	exp.storageClient = failingBatchClient{Client: client}
+	assert.ErrorContains(t, exp.anchorPending(t.Context()), "failed to record anchor receipt")
+	assert.Len(t, srv.received(), 2)
+
+	exp.storageClient = client
+	require.NoError(t, exp.anchorPending(t.Context()))
+	assert.Len(t, srv.received(), 2)
+	data, err := client.Get(t.Context(), pendingKey(0))
+	require.NoError(t, err)
+	assert.Nil(t, data)
+	data, err = client.Get(t.Context(), pendingStartKey)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1}, data)
+}
+
+func TestEncodeLeaves(t *testing.T) {
+	leaves := [][]byte{{0x00, 0xff}, {}, []byte("raw value")}
+	decoded, err := decodeLeaves(encodeLeaves(leaves))
+	require.NoError(t, err)
+	assert.Equal(t, leaves, decoded)
+
+	_, err = decodeLeaves([]byte{5, 1})
+	assert.EqualError(t, err, "truncated leaf")
+}
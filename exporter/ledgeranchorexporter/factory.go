@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgeranchorexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/ledgeranchorexporter"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/ledgeranchorexporter/internal/metadata"
+)
+
+// NewFactory returns a new factory for the ledger anchor exporter.
+func NewFactory() exporter.Factory {
+	return exporter.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		exporter.WithLogs(createLogsExporter, metadata.LogsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Interval:          time.Minute,
+		MaxPendingRecords: 100000,
+		Ethereum: configoptional.Default(EthereumConfig{
+			ClientConfig:        confighttp.NewDefaultClientConfig(),
+			Method:              "anchor(bytes32)",
+			ConfirmationTimeout: 5 * time.Minute,
+		}),
+		Fabric: configoptional.Default(FabricConfig{
+			ClientConfig: confighttp.NewDefaultClientConfig(),
+			Function:     "Anchor",
+		}),
+	}
+}
+
+func createLogsExporter(
+	ctx context.Context,
+	set exporter.Settings,
+	cfg component.Config,
+) (exporter.Logs, error) {
+	exp := newAnchorExporter(set, cfg.(*Config))
+	return exporterhelper.NewLogs(
+		ctx,
+		set,
+		cfg,
+		exp.pushLogs,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithShutdown(exp.shutdown),
+	)
+}
@@ -0,0 +1,223 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ledgeranchorexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/ledgeranchorexporter"
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ledger writes Merkle roots to a ledger backend.
+type ledger interface {
+	// name identifies the backend in receipts and logs.
+	name() string
+	// anchor records root on the ledger and returns the transaction ID.
+	anchor(ctx context.Context, root []byte) (string, error)
+}
+
+// ethereumLedger anchors roots by sending a transaction calling a contract
+// method through the eth_sendTransaction JSON-RPC method, and waits for the
+// transaction to be mined. Signing is left to the node or to a signer proxy
+// such as Clef or Web3Signer.
+type ethereumLedger struct {
+	client   *http.Client
+	cfg      *EthereumConfig
+	selector []byte
+	// pollInterval is the interval between transaction receipt requests.
+	pollInterval time.Duration
+}
+
+func newEthereumLedger(client *http.Client, cfg *EthereumConfig) *ethereumLedger {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(cfg.Method))
+	return &ethereumLedger{client: client, cfg: cfg, selector: h.Sum(nil)[:4], pollInterval: 2 * time.Second}
+}
+
+func (*ethereumLedger) name() string {
+	return "ethereum"
+}
+
+type jsonRPCRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type jsonRPCResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type ethereumTransaction struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Data string `json:"data"`
+	Gas  string `json:"gas,omitempty"`
+}
+
+func (l *ethereumLedger) anchor(ctx context.Context, root []byte) (string, error) {
+	// The call data is the method selector followed by the root, which as a
+	// bytes32 is ABI encoded as is.
+	tx := ethereumTransaction{
+		From: l.cfg.From,
+		To:   l.cfg.Contract,
+		Data: "0x" + hex.EncodeToString(l.selector) + hex.EncodeToString(root),
+	}
+	if l.cfg.Gas > 0 {
+		tx.Gas = "0x" + strconv.FormatUint(l.cfg.Gas, 16)
+	}
+
+	result, err := l.call(ctx, "eth_sendTransaction", tx)
+	if err != nil {
+		return "", err
+	}
+	var txHash string
+	if err := json.Unmarshal(result, &txHash); err != nil || txHash == "" {
+		return "", fmt.Errorf("eth_sendTransaction returned an invalid transaction hash: %s", result)
+	}
+	if err := l.waitForReceipt(ctx, txHash); err != nil {
+		return "", err
+	}
+	return txHash, nil
+}
+
+type ethereumReceipt struct {
+	Status string `json:"status"`
+}
+
+// waitForReceipt polls the transaction receipt until the transaction is
+// mined, and fails if it was reverted or is not mined within the
+// confirmation timeout.
+func (l *ethereumLedger) waitForReceipt(ctx context.Context, txHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, l.cfg.ConfirmationTimeout)
+	defer cancel()
+	ticker := time.NewTicker(l.pollInterval)
+	defer ticker.Stop()
+	for {
+		result, err := l.call(ctx, "eth_getTransactionReceipt", txHash)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("anchoring transaction %s was not mined: %w", txHash, ctx.Err())
+			}
+			return err
+		}
+		if r := bytes.TrimSpace(result); len(r) > 0 && !bytes.Equal(r, []byte("null")) {
+			var receipt ethereumReceipt
+			if err := json.Unmarshal(result, &receipt); err != nil {
+				return fmt.Errorf("eth_getTransactionReceipt returned an invalid receipt: %w", err)
+			}
+			if receipt.Status != "0x1" {
+				return fmt.Errorf("anchoring transaction %s was reverted", txHash)
+			}
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("anchoring transaction %s was not mined: %w", txHash, ctx.Err())
+		}
+	}
+}
+
+// call invokes a JSON-RPC method and returns its result.
+func (l *ethereumLedger) call(ctx context.Context, method string, params ...any) (json.RawMessage, error) {
+	var resp jsonRPCResponse
+	if err := postJSON(ctx, l.client, l.cfg.Endpoint, jsonRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  method,
+		Params:  params,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("%s failed: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+	}
+	return resp.Result, nil
+}
+
+// fabricLedger anchors roots by invoking a chaincode function through a REST
+// gateway in front of a Fabric peer, which holds the client identity, submits
+// the transaction and responds once it is committed.
+type fabricLedger struct {
+	client *http.Client
+	cfg    *FabricConfig
+}
+
+func newFabricLedger(client *http.Client, cfg *FabricConfig) *fabricLedger {
+	return &fabricLedger{client: client, cfg: cfg}
+}
+
+func (*fabricLedger) name() string {
+	return "fabric"
+}
+
+type fabricInvokeRequest struct {
+	Channel   string   `json:"channel"`
+	Chaincode string   `json:"chaincode"`
+	Function  string   `json:"function"`
+	Args      []string `json:"args"`
+}
+
+type fabricInvokeResponse struct {
+	TransactionID string `json:"transaction_id"`
+}
+
+func (l *fabricLedger) anchor(ctx context.Context, root []byte) (string, error) {
+	var resp fabricInvokeResponse
+	if err := postJSON(ctx, l.client, l.cfg.Endpoint, fabricInvokeRequest{
+		Channel:   l.cfg.Channel,
+		Chaincode: l.cfg.Chaincode,
+		Function:  l.cfg.Function,
+		Args:      []string{hex.EncodeToString(root)},
+	}, &resp); err != nil {
+		return "", err
+	}
+	if resp.TransactionID == "" {
+		return "", errors.New("fabric gateway response has no transaction_id")
+	}
+	return resp.TransactionID, nil
+}
+
+// postJSON sends body as JSON to url and decodes the JSON response into out.
+func postJSON(ctx context.Context, client *http.Client, url string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("request to %s failed with status %d: %s", url, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package microsoftsentinelexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/microsoftsentinelexporter"
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+// columnName matches the column names accepted by Log Analytics tables.
+var columnName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedColumns are the columns populated by the exporter or by Log
+// Analytics itself, which integrity fields cannot be mapped to.
+var reservedColumns = []string{
+	columnTimeGenerated,
+	columnObservedTime,
+	columnSeverityText,
+	columnSeverityNumber,
+	columnBody,
+	columnEventName,
+	columnTraceID,
+	columnSpanID,
+	columnAttributes,
+	columnResource,
+	columnScopeName,
+	columnScopeVersion,
+	"TenantId",
+	"Type",
+	"_ResourceId",
+}
+
+// Config defines configuration for the Microsoft Sentinel exporter.
+type Config struct {
+	// ClientConfig configures the HTTP client; endpoint is the logs ingestion
+	// endpoint of the data collection endpoint or data collection rule, for
+	// example https://my-dce.westeurope-1.ingest.monitor.azure.com. Requests
+	// are authenticated with the azure_auth extension.
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	QueueSettings             configoptional.Optional[exporterhelper.QueueBatchConfig] `mapstructure:"sending_queue"`
+	configretry.BackOffConfig `mapstructure:"retry_on_failure"`
+
+	// DCRImmutableID is the immutable ID of the data collection rule.
+	DCRImmutableID string `mapstructure:"dcr_immutable_id"`
+
+	// StreamName is the name of the data collection rule stream, for example
+	// Custom-OTelAudit_CL.
+	StreamName string `mapstructure:"stream_name"`
+
+	// APIVersion is the version of the Logs Ingestion API. Default: 2023-01-01.
+	APIVersion string `mapstructure:"api_version"`
+
+	// IntegrityFields maps the attributes carrying integrity metadata, such as
+	// record hashes, signatures or hash chain links, to the dedicated columns
+	// they are exported to.
+	IntegrityFields map[string]string `mapstructure:"integrity_fields"`
+
+	// RequireIntegrity rejects log records missing any of the integrity
+	// attributes instead of exporting them without. Default: false.
+	RequireIntegrity bool `mapstructure:"require_integrity"`
+}
+
+// Validate checks the Config for invalid values.
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must be specified")
+	}
+	if cfg.DCRImmutableID == "" {
+		return errors.New("dcr_immutable_id must be specified")
+	}
+	if cfg.StreamName == "" {
+		return errors.New("stream_name must be specified")
+	}
+	if cfg.APIVersion == "" {
+		return errors.New("api_version must be specified")
+	}
+	if cfg.RequireIntegrity && len(cfg.IntegrityFields) == 0 {
+		return errors.New("require_integrity requires integrity_fields")
+	}
+	columns := make(map[string]string, len(cfg.IntegrityFields))
+	for attribute, column := range cfg.IntegrityFields {
+		if attribute == "" {
+			return errors.New("integrity_fields: attribute must not be empty")
+		}
+		if !columnName.MatchString(column) {
+			return fmt.Errorf("integrity_fields: invalid column name %q for attribute %q", column, attribute)
+		}
+		if slices.Contains(reservedColumns, column) {
+			return fmt.Errorf("integrity_fields: column %q for attribute %q is reserved", column, attribute)
+		}
+		if other, ok := columns[column]; ok {
+			return fmt.Errorf("integrity_fields: attributes %q and %q are mapped to the same column %q", min(attribute, other), max(attribute, other), column)
+		}
+		columns[column] = attribute
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package microsoftsentinelexporter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/microsoftsentinelexporter/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr string
+	}{
+		{
+			id: component.NewID(metadata.Type),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.Endpoint = "https://otelcol-dce.westeurope-1.ingest.monitor.azure.com"
+				cfg.DCRImmutableID = "dcr-00000000000000000000000000000000"
+				cfg.StreamName = "Custom-OTelAudit_CL"
+				return cfg
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "integrity"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.Endpoint = "https://otelcol-dce.westeurope-1.ingest.monitor.azure.com"
+				cfg.Auth = configoptional.Some(configauth.Config{AuthenticatorID: component.MustNewID("azure_auth")})
+				cfg.DCRImmutableID = "dcr-00000000000000000000000000000000"
+				cfg.StreamName = "Custom-OTelAudit_CL"
+				cfg.IntegrityFields = map[string]string{
+					"log.record.sequence":  "Sequence",
+					"log.record.hash":      "RecordHash",
+					"log.record.signature": "Signature",
+				}
+				cfg.RequireIntegrity = true
+				return cfg
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "nodcr"),
+			expectedErr: "dcr_immutable_id must be specified",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "reserved"),
+			expectedErr: `integrity_fields: column "Body" for attribute "log.record.hash" is reserved`,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalidcolumn"),
+			expectedErr: `integrity_fields: invalid column name "record.hash" for attribute "log.record.hash"`,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "duplicate"),
+			expectedErr: `integrity_fields: attributes "log.record.chain_head" and "log.record.hash" are mapped to the same column "Hash"`,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "require"),
+			expectedErr: "require_integrity requires integrity_fields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
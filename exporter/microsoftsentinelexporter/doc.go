@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package microsoftsentinelexporter exports log records to Microsoft Sentinel
+// through the Azure Monitor Logs Ingestion API.
+package microsoftsentinelexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/microsoftsentinelexporter"
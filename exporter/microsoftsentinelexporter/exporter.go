@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package microsoftsentinelexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/microsoftsentinelexporter"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/microsoftsentinelexporter/internal/metadata"
+)
+
+// maxRequestBytes is the size limit of a Logs Ingestion API request.
+const maxRequestBytes = 1 << 20
+
+type sentinelExporter struct {
+	config    *Config
+	set       component.TelemetrySettings
+	logger    *zap.Logger
+	telemetry *metadata.TelemetryBuilder
+	mapper    *mapper
+
+	url             string
+	client          *http.Client
+	maxRequestBytes int
+}
+
+func newSentinelExporter(set exporter.Settings, cfg *Config) (*sentinelExporter, error) {
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+	return &sentinelExporter{
+		config:    cfg,
+		set:       set.TelemetrySettings,
+		logger:    set.Logger,
+		telemetry: telemetryBuilder,
+		mapper: &mapper{
+			integrityFields:  cfg.IntegrityFields,
+			requireIntegrity: cfg.RequireIntegrity,
+		},
+		url: fmt.Sprintf("%s/dataCollectionRules/%s/streams/%s?api-version=%s",
+			strings.TrimSuffix(cfg.Endpoint, "/"),
+			url.PathEscape(cfg.DCRImmutableID),
+			url.PathEscape(cfg.StreamName),
+			url.QueryEscape(cfg.APIVersion)),
+		maxRequestBytes: maxRequestBytes,
+	}, nil
+}
+
+func (e *sentinelExporter) start(ctx context.Context, host component.Host) error {
+	client, err := e.config.ToClient(ctx, host.GetExtensions(), e.set)
+	if err != nil {
+		return err
+	}
+	e.client = client
+	return nil
+}
+
+func (e *sentinelExporter) shutdown(context.Context) error {
+	if e.client != nil {
+		e.client.CloseIdleConnections()
+	}
+	e.telemetry.Shutdown()
+	return nil
+}
+
+// pushLogs maps the log records to rows and sends them in requests within
+// the size limit of the API. Records failing the integrity attribute checks
+// are not sent and reported as a permanent error once the other records were
+// sent. Requests already sent are sent again when a later request of the same batch
+// fails and is retried.
+func (e *sentinelExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	var (
+		rejected   []error
+		violations int64
+		request    bytes.Buffer
+	)
+	for _, rl := range ld.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				row, err := e.mapper.mapRecord(rl.Resource(), sl.Scope(), lr)
+				if err != nil {
+					rejected = append(rejected, err)
+					violations++
+					continue
+				}
+				if len(row)+2 > e.maxRequestBytes {
+					rejected = append(rejected, fmt.Errorf("log record of %d bytes exceeds the request size limit", len(row)))
+					continue
+				}
+				if request.Len() > 0 && request.Len()+len(row)+2 > e.maxRequestBytes {
+					if err := e.send(ctx, &request); err != nil {
+						return err
+					}
+				}
+				if request.Len() == 0 {
+					request.WriteByte('[')
+				} else {
+					request.WriteByte(',')
+				}
+				request.Write(row)
+			}
+		}
+	}
+	if request.Len() > 0 {
+		if err := e.send(ctx, &request); err != nil {
+			return err
+		}
+	}
+
+	if violations > 0 {
+		e.telemetry.ExporterMicrosoftSentinelIntegrityViolations.Add(ctx, violations)
+	}
+	if len(rejected) == 0 {
+		return nil
+	}
+	return consumererror.NewPermanent(fmt.Errorf("%d log records not exported: %w", len(rejected), errors.Join(rejected...)))
+}
+
+// send posts the rows in request, closing the JSON array, and resets it.
+func (e *sentinelExporter) send(ctx context.Context, request *bytes.Buffer) error {
+	request.WriteByte(']')
+	defer request.Reset()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(request.Bytes()))
+	if err != nil {
+		return consumererror.NewPermanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+
+	err = fmt.Errorf("logs ingestion returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		var retryAfter time.Duration
+		if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return exporterhelper.NewThrottleRetry(err, retryAfter)
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout:
+		return err
+	default:
+		return consumererror.NewPermanent(err)
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package microsoftsentinelexporter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/microsoftsentinelexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/microsoftsentinelexporter/internal/metadatatest"
+)
+
+// ingestionServer records the rows posted to the Logs Ingestion API.
+type ingestionServer struct {
+	mu       sync.Mutex
+	requests [][]map[string]any
+	status   int
+	header   http.Header
+}
+
+func newIngestionServer(t *testing.T) (*ingestionServer, string) {
+	s := &ingestionServer{status: http.StatusNoContent}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv.URL
+}
+
+func (s *ingestionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.URL.Path != "/dataCollectionRules/dcr-0123/streams/Custom-OTelAudit_CL" || r.URL.Query().Get("api-version") != "2023-01-01" {
+		http.NotFound(w, r)
+		return
+	}
+	var rows []map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&rows); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.requests = append(s.requests, rows)
+	for k, v := range s.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(s.status)
+}
+
+func (s *ingestionServer) rows() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []map[string]any
+	for _, req := range s.requests {
+		rows = append(rows, req...)
+	}
+	return rows
+}
+
+func newTestExporter(t *testing.T, endpoint string, configure func(*Config), set component.TelemetrySettings) *sentinelExporter {
+	t.Helper()
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	cfg.DCRImmutableID = "dcr-0123"
+	cfg.StreamName = "Custom-OTelAudit_CL"
+	cfg.IntegrityFields = map[string]string{
+		"log.record.hash":      "RecordHash",
+		"log.record.signature": "Signature",
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	settings := exportertest.NewNopSettings(metadata.Type)
+	settings.TelemetrySettings = set
+	exp, err := newSentinelExporter(settings, cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		require.NoError(t, exp.shutdown(context.Background()))
+	})
+	return exp
+}
+
+func newLogs(records ...func(plog.LogRecord)) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "auth")
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("audit")
+	sl.Scope().SetVersion("1.0.0")
+	for _, fill := range records {
+		fill(sl.LogRecords().AppendEmpty())
+	}
+	return ld
+}
+
+func signedRecord(body, hash string) func(plog.LogRecord) {
+	return func(lr plog.LogRecord) {
+		lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)))
+		lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Date(2026, 10, 15, 8, 0, 1, 0, time.UTC)))
+		lr.SetSeverityText("INFO")
+		lr.SetSeverityNumber(plog.SeverityNumberInfo)
+		lr.SetEventName("user.login")
+		lr.Body().SetStr(body)
+		lr.Attributes().PutStr("user.id", "alice")
+		lr.Attributes().PutStr("log.record.hash", hash)
+		lr.Attributes().PutEmptyBytes("log.record.signature").FromRaw([]byte{0xde, 0xad, 0xbe, 0xef})
+	}
+}
+
+func TestPushLogs(t *testing.T) {
+	server, endpoint := newIngestionServer(t)
+	exp := newTestExporter(t, endpoint, nil, componenttest.NewNopTelemetrySettings())
+
+	require.NoError(t, exp.pushLogs(t.Context(), newLogs(signedRecord("user logged in", "sha256:abcd"))))
+
+	assert.Equal(t, []map[string]any{{
+		"TimeGenerated":  "2026-10-15T08:00:00Z",
+		"ObservedTime":   "2026-10-15T08:00:01Z",
+		"SeverityText":   "INFO",
+		"SeverityNumber": float64(9),
+		"Body":           "user logged in",
+		"EventName":      "user.login",
+		"Attributes": map[string]any{
+			"user.id":              "alice",
+			"log.record.hash":      "sha256:abcd",
+			"log.record.signature": "3q2+7w==",
+		},
+		"Resource":     map[string]any{"service.name": "auth"},
+		"ScopeName":    "audit",
+		"ScopeVersion": "1.0.0",
+		"RecordHash":   "sha256:abcd",
+		"Signature":    "3q2+7w==",
+	}}, server.rows())
+}
+
+func TestPushLogsIntegrityViolations(t *testing.T) {
+	server, endpoint := newIngestionServer(t)
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	exp := newTestExporter(t, endpoint, func(cfg *Config) {
+		cfg.RequireIntegrity = true
+	}, metadatatest.NewSettings(tel).TelemetrySettings)
+
+	ld := newLogs(
+		signedRecord("valid", "sha256:abcd"),
+		// Invalid UTF-8 is replaced when encoded to JSON, so the exported
+		// hash would no longer match.
+		signedRecord("mangled", "sha256:\xff\xfe"),
+		func(lr plog.LogRecord) {
+			signedRecord("structured", "")(lr)
+			lr.Attributes().PutEmptyMap("log.record.hash").PutStr("sha256", "abcd")
+		},
+		func(lr plog.LogRecord) {
+			signedRecord("unsigned", "sha256:abcd")(lr)
+			lr.Attributes().Remove("log.record.signature")
+		},
+	)
+	err := exp.pushLogs(t.Context(), ld)
+	require.Error(t, err)
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.ErrorContains(t, err, "3 log records not exported")
+	assert.ErrorContains(t, err, `integrity attribute "log.record.hash" is not valid UTF-8`)
+	assert.ErrorContains(t, err, `integrity attribute "log.record.hash" has unsupported type Map`)
+	assert.ErrorContains(t, err, `missing integrity attribute "log.record.signature"`)
+
+	rows := server.rows()
+	require.Len(t, rows, 1)
+	assert.Equal(t, "valid", rows[0]["Body"])
+
+	metadatatest.AssertEqualExporterMicrosoftSentinelIntegrityViolations(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 3},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestPushLogsOptionalIntegrity(t *testing.T) {
+	server, endpoint := newIngestionServer(t)
+	exp := newTestExporter(t, endpoint, nil, componenttest.NewNopTelemetrySettings())
+
+	require.NoError(t, exp.pushLogs(t.Context(), newLogs(func(lr plog.LogRecord) {
+		lr.Body().SetStr("unsigned")
+	})))
+	rows := server.rows()
+	require.Len(t, rows, 1)
+	assert.NotContains(t, rows[0], "RecordHash")
+	assert.NotContains(t, rows[0], "Signature")
+	// Without a timestamp the observed timestamp is used.
+	assert.Equal(t, rows[0]["ObservedTime"], rows[0]["TimeGenerated"])
+}
+
+func TestPushLogsSplitsRequests(t *testing.T) {
+	server, endpoint := newIngestionServer(t)
+	exp := newTestExporter(t, endpoint, nil, componenttest.NewNopTelemetrySettings())
+	row, err := exp.mapper.mapRecord(pcommon.NewResource(), pcommon.NewInstrumentationScope(), plog.NewLogRecord())
+	require.NoError(t, err)
+	exp.maxRequestBytes = 2*len(row) + 450
+
+	records := make([]func(plog.LogRecord), 5)
+	for i := range records {
+		records[i] = signedRecord("record", "sha256:abcd")
+	}
+	require.NoError(t, exp.pushLogs(t.Context(), newLogs(records...)))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Greater(t, len(server.requests), 1)
+	total := 0
+	for _, req := range server.requests {
+		total += len(req)
+	}
+	assert.Equal(t, 5, total)
+}
+
+func TestPushLogsOversizedRecord(t *testing.T) {
+	server, endpoint := newIngestionServer(t)
+	exp := newTestExporter(t, endpoint, nil, componenttest.NewNopTelemetrySettings())
+	exp.maxRequestBytes = 100
+
+	err := exp.pushLogs(t.Context(), newLogs(signedRecord("record", "sha256:abcd")))
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.ErrorContains(t, err, "exceeds the request size limit")
+	assert.Empty(t, server.rows())
+}
+
+func TestPushLogsErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		permanent  bool
+		throttle   bool
+	}{
+		{name: "throttled", status: http.StatusTooManyRequests, retryAfter: "30", throttle: true},
+		{name: "unavailable", status: http.StatusServiceUnavailable, throttle: true},
+		{name: "server error", status: http.StatusInternalServerError},
+		{name: "bad request", status: http.StatusBadRequest, permanent: true},
+		{name: "forbidden", status: http.StatusForbidden, permanent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, endpoint := newIngestionServer(t)
+			server.status = tt.status
+			if tt.retryAfter != "" {
+				server.header = http.Header{"Retry-After": []string{tt.retryAfter}}
+			}
+			exp := newTestExporter(t, endpoint, nil, componenttest.NewNopTelemetrySettings())
+
+			err := exp.pushLogs(t.Context(), newLogs(signedRecord("record", "sha256:abcd")))
+			require.Error(t, err)
+			assert.Equal(t, tt.permanent, consumererror.IsPermanent(err))
+			if tt.throttle {
+				assert.ErrorContains(t, err, "Throttle")
+			}
+		})
+	}
+}
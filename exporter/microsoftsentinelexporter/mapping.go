@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package microsoftsentinelexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/microsoftsentinelexporter"
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Columns of the rows sent to the data collection rule stream.
+const (
+	columnTimeGenerated  = "TimeGenerated"
+	columnObservedTime   = "ObservedTime"
+	columnSeverityText   = "SeverityText"
+	columnSeverityNumber = "SeverityNumber"
+	columnBody           = "Body"
+	columnEventName      = "EventName"
+	columnTraceID        = "TraceId"
+	columnSpanID         = "SpanId"
+	columnAttributes     = "Attributes"
+	columnResource       = "Resource"
+	columnScopeName      = "ScopeName"
+	columnScopeVersion   = "ScopeVersion"
+)
+
+// mapper maps log records to rows of the data collection rule stream.
+type mapper struct {
+	integrityFields  map[string]string
+	requireIntegrity bool
+}
+
+// mapRecord returns the JSON encoded row for a log record. It fails when the
+// record misses a required integrity attribute, or when an integrity
+// attribute cannot be written to its string column unchanged; exporting such
+// a record would leave a copy in the SIEM that cannot be verified. Whether
+// the DCR stream declares the columns is not checked: the Logs Ingestion API
+// drops undeclared columns without an error.
+func (m *mapper) mapRecord(res pcommon.Resource, scope pcommon.InstrumentationScope, lr plog.LogRecord) ([]byte, error) {
+	timestamp := lr.Timestamp()
+	if timestamp == 0 {
+		timestamp = lr.ObservedTimestamp()
+	}
+	row := map[string]any{
+		columnTimeGenerated:  formatTimestamp(timestamp),
+		columnObservedTime:   formatTimestamp(lr.ObservedTimestamp()),
+		columnSeverityText:   lr.SeverityText(),
+		columnSeverityNumber: int32(lr.SeverityNumber()),
+		columnBody:           lr.Body().AsRaw(),
+		columnAttributes:     lr.Attributes().AsRaw(),
+		columnResource:       res.Attributes().AsRaw(),
+		columnScopeName:      scope.Name(),
+		columnScopeVersion:   scope.Version(),
+	}
+	if eventName := lr.EventName(); eventName != "" {
+		row[columnEventName] = eventName
+	}
+	if traceID := lr.TraceID(); !traceID.IsEmpty() {
+		row[columnTraceID] = traceID.String()
+	}
+	if spanID := lr.SpanID(); !spanID.IsEmpty() {
+		row[columnSpanID] = spanID.String()
+	}
+
+	// The values are kept in the attributes as well, so the original record
+	// can be reconstructed for verification.
+	for attribute, column := range m.integrityFields {
+		v, ok := lr.Attributes().Get(attribute)
+		if !ok {
+			if m.requireIntegrity {
+				return nil, fmt.Errorf("missing integrity attribute %q", attribute)
+			}
+			continue
+		}
+		switch v.Type() {
+		case pcommon.ValueTypeStr:
+			// encoding/json replaces invalid UTF-8, which would change the
+			// exported value.
+			if !utf8.ValidString(v.Str()) {
+				return nil, fmt.Errorf("integrity attribute %q is not valid UTF-8", attribute)
+			}
+		case pcommon.ValueTypeBytes, pcommon.ValueTypeInt, pcommon.ValueTypeDouble, pcommon.ValueTypeBool:
+		default:
+			return nil, fmt.Errorf("integrity attribute %q has unsupported type %s", attribute, v.Type())
+		}
+		row[column] = v.AsString()
+	}
+	return json.Marshal(row)
+}
+
+func formatTimestamp(ts pcommon.Timestamp) string {
+	return ts.AsTime().UTC().Format(time.RFC3339Nano)
+}
@@ -5,7 +5,9 @@ package syslogexporter // import "github.com/open-telemetry/opentelemetry-collec
 
 import (
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/config/configoptional"
@@ -20,6 +22,7 @@ var (
 	errUnsupportedNetwork  = errors.New("unsupported network: network is required, only tcp/udp/unix supported")
 	errUnsupportedProtocol = errors.New("unsupported protocol: Only rfc5424 and rfc3164 supported")
 	errOctetCounting       = errors.New("octet counting is only supported for rfc5424 protocol")
+	errSigningProtocol     = errors.New("signing is only supported for rfc5424 protocol")
 )
 
 // Config defines configuration for Syslog exporter.
@@ -41,6 +44,9 @@ type Config struct {
 	// TLS struct exposes TLS client configuration.
 	TLS configtls.ClientConfig `mapstructure:"tls"`
 
+	// Signing enables RFC 5848 signature blocks in the syslog stream.
+	Signing configoptional.Optional[SigningConfig] `mapstructure:"signing"`
+
 	QueueSettings             configoptional.Optional[exporterhelper.QueueBatchConfig] `mapstructure:"sending_queue"`
 	configretry.BackOffConfig `mapstructure:"retry_on_failure"`
 	TimeoutSettings           exporterhelper.TimeoutConfig `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
@@ -76,6 +82,10 @@ func (cfg *Config) Validate() error {
 		invalidFields = append(invalidFields, errOctetCounting)
 	}
 
+	if cfg.Signing.HasValue() && cfg.Protocol != protocolRFC5424Str {
+		invalidFields = append(invalidFields, errSigningProtocol)
+	}
+
 	if len(invalidFields) > 0 {
 		return errors.Join(invalidFields...)
 	}
@@ -83,6 +93,48 @@ func (cfg *Config) Validate() error {
 	return nil
 }
 
+// SigningConfig defines how the syslog stream is signed as described in
+// RFC 5848.
+type SigningConfig struct {
+	// Path to the PEM encoded DSA private key signing the stream, in OpenSSL
+	// traditional or PKCS #8 form.
+	KeyFile string `mapstructure:"key_file"`
+	// Path to the PEM encoded certificate of the signing key. When set, it is
+	// sent in certificate blocks; otherwise the bare public key is sent.
+	CertFile string `mapstructure:"cert_file"`
+	// Hash algorithm used for message hashes and signatures.
+	// options: sha256, sha1
+	HashAlgorithm string `mapstructure:"hash_algorithm"`
+	// Maximum number of message hashes carried by a signature block (1-99).
+	MaxHashesPerBlock int `mapstructure:"max_hashes_per_block"`
+	// Interval at which certificate blocks are resent. 0 sends them only
+	// at the start of the session.
+	CertificateInterval time.Duration `mapstructure:"certificate_interval"`
+	// Path to the file persisting the reboot session ID. When set, the ID is
+	// incremented on every start; otherwise a random ID is used.
+	RSIDFile string `mapstructure:"rsid_file"`
+}
+
+// Validate the signing configuration for errors.
+func (cfg *SigningConfig) Validate() error {
+	var errs []error
+	if cfg.KeyFile == "" {
+		errs = append(errs, errors.New("key_file is required"))
+	}
+	switch cfg.HashAlgorithm {
+	case hashAlgorithmSHA256, hashAlgorithmSHA1:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported hash_algorithm %q: only sha256 and sha1 supported", cfg.HashAlgorithm))
+	}
+	if cfg.MaxHashesPerBlock < 1 || cfg.MaxHashesPerBlock > maxHashesPerBlock {
+		errs = append(errs, fmt.Errorf("max_hashes_per_block must be in the range 1-%d", maxHashesPerBlock))
+	}
+	if cfg.CertificateInterval < 0 {
+		errs = append(errs, errors.New("certificate_interval must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
 const (
 	// Syslog Network
 	DefaultNetwork = string(confignet.TransportTypeTCP)
@@ -90,4 +142,10 @@ const (
 	DefaultPort = 514
 	// Syslog Protocol
 	DefaultProtocol = "rfc5424"
+	// Hash algorithm of signed syslog streams
+	DefaultHashAlgorithm = hashAlgorithmSHA256
+	// Maximum number of message hashes per signature block
+	DefaultMaxHashesPerBlock = 25
+	// Interval at which certificate blocks are resent
+	DefaultCertificateInterval = time.Hour
 )
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/config/configoptional"
 )
 
 func TestValidate(t *testing.T) {
@@ -65,6 +66,17 @@ func TestValidate(t *testing.T) {
 			},
 			err: "invalid endpoint: endpoint is required but it is not configured",
 		},
+		{
+			name: "signing with rfc3164",
+			cfg: &Config{
+				Port:     514,
+				Endpoint: "host.domain.com",
+				Network:  "tcp",
+				Protocol: "rfc3164",
+				Signing:  configoptional.Some(SigningConfig{KeyFile: "key.pem"}),
+			},
+			err: "signing is only supported for rfc5424 protocol",
+		},
 	}
 	for _, testInstance := range tests {
 		t.Run(testInstance.name, func(t *testing.T) {
@@ -77,3 +89,57 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateSigning(t *testing.T) {
+	validSigningConfig := func() SigningConfig {
+		return SigningConfig{
+			KeyFile:             "key.pem",
+			HashAlgorithm:       DefaultHashAlgorithm,
+			MaxHashesPerBlock:   DefaultMaxHashesPerBlock,
+			CertificateInterval: DefaultCertificateInterval,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*SigningConfig)
+		err    string
+	}{
+		{
+			name:   "valid",
+			mutate: func(*SigningConfig) {},
+		},
+		{
+			name:   "missing key",
+			mutate: func(cfg *SigningConfig) { cfg.KeyFile = "" },
+			err:    "key_file is required",
+		},
+		{
+			name:   "unsupported hash algorithm",
+			mutate: func(cfg *SigningConfig) { cfg.HashAlgorithm = "md5" },
+			err:    `unsupported hash_algorithm "md5": only sha256 and sha1 supported`,
+		},
+		{
+			name:   "too many hashes per block",
+			mutate: func(cfg *SigningConfig) { cfg.MaxHashesPerBlock = 100 },
+			err:    "max_hashes_per_block must be in the range 1-99",
+		},
+		{
+			name:   "negative certificate interval",
+			mutate: func(cfg *SigningConfig) { cfg.CertificateInterval = -1 },
+			err:    "certificate_interval must not be negative",
+		},
+	}
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			cfg := validSigningConfig()
+			testInstance.mutate(&cfg)
+			err := cfg.Validate()
+			if testInstance.err != "" {
+				assert.EqualError(t, err, testInstance.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/consumer/consumererror"
@@ -23,6 +25,9 @@ type syslogexporter struct {
 	logger    *zap.Logger
 	tlsConfig *tls.Config
 	formatter formatter
+
+	signMu sync.Mutex
+	signer *rfc5848Signer // nil unless signing is enabled
 }
 
 func initExporter(cfg *Config, createSettings exporter.Settings) (*syslogexporter, error) {
@@ -42,6 +47,14 @@ func initExporter(cfg *Config, createSettings exporter.Settings) (*syslogexporte
 		formatter: createFormatter(cfg.Protocol, cfg.EnableOctetCounting),
 	}
 
+	if signingCfg := cfg.Signing.Get(); signingCfg != nil {
+		signer, err := newRFC5848Signer(signingCfg, time.Now)
+		if err != nil {
+			return nil, err
+		}
+		s.signer = signer
+	}
+
 	s.logger.Info("Syslog Exporter configured",
 		zap.String("endpoint", cfg.Endpoint),
 		zap.String("protocol", cfg.Protocol),
@@ -84,27 +97,52 @@ func (se *syslogexporter) pushLogsData(ctx context.Context, logs plog.Logs) erro
 	return err
 }
 
-func (se *syslogexporter) exportBatch(ctx context.Context, logs plog.Logs) error {
-	var payload strings.Builder
+// formatLogs formats every log record, in order.
+func (se *syslogexporter) formatLogs(logs plog.Logs) []string {
+	var msgs []string
 	for i := 0; i < logs.ResourceLogs().Len(); i++ {
 		resourceLogs := logs.ResourceLogs().At(i)
 		for j := 0; j < resourceLogs.ScopeLogs().Len(); j++ {
 			scopeLogs := resourceLogs.ScopeLogs().At(j)
 			for k := 0; k < scopeLogs.LogRecords().Len(); k++ {
 				logRecord := scopeLogs.LogRecords().At(k)
-				formatted := se.formatter.format(logRecord)
-				payload.WriteString(formatted)
+				msgs = append(msgs, se.formatter.format(logRecord))
 			}
 		}
 	}
+	return msgs
+}
+
+// sign adds RFC 5848 signature and certificate blocks to msgs when signing is
+// enabled. For every returned message, origin holds the index of the message
+// in msgs, or -1 for blocks.
+func (se *syslogexporter) sign(msgs []string) ([]string, []int, error) {
+	if se.signer == nil || len(msgs) == 0 {
+		origin := make([]int, len(msgs))
+		for i := range origin {
+			origin[i] = i
+		}
+		return msgs, origin, nil
+	}
+	se.signMu.Lock()
+	defer se.signMu.Unlock()
+	return se.signer.sign(msgs, se.config.EnableOctetCounting)
+}
+
+func (se *syslogexporter) exportBatch(ctx context.Context, logs plog.Logs) error {
+	msgs, _, err := se.sign(se.formatLogs(logs))
+	if err != nil {
+		return consumererror.NewLogs(err, logs)
+	}
 
-	if payload.Len() > 0 {
+	payload := strings.Join(msgs, "")
+	if payload != "" {
 		sender, err := connect(ctx, se.logger, se.config, se.tlsConfig)
 		if err != nil {
 			return consumererror.NewLogs(err, logs)
 		}
 		defer sender.close()
-		err = sender.Write(ctx, payload.String())
+		err = sender.Write(ctx, payload)
 		if err != nil {
 			return consumererror.NewLogs(err, logs)
 		}
@@ -119,31 +157,50 @@ func (se *syslogexporter) exportNonBatch(ctx context.Context, logs plog.Logs) er
 	}
 	defer sender.close()
 
+	formatted := se.formatLogs(logs)
+	msgs, origin, err := se.sign(formatted)
+	if err != nil {
+		return consumererror.NewLogs(err, logs)
+	}
+
 	errs := []error{}
-	droppedLogs := plog.NewLogs()
-	for i := 0; i < logs.ResourceLogs().Len(); i++ {
-		resourceLogs := logs.ResourceLogs().At(i)
-		droppedResourceLogs := droppedLogs.ResourceLogs().AppendEmpty()
-		for j := 0; j < resourceLogs.ScopeLogs().Len(); j++ {
-			scopeLogs := resourceLogs.ScopeLogs().At(j)
-			droppedScopeLogs := droppedResourceLogs.ScopeLogs().AppendEmpty()
-			for k := 0; k < scopeLogs.LogRecords().Len(); k++ {
-				logRecord := scopeLogs.LogRecords().At(k)
-				formatted := se.formatter.format(logRecord)
-				err = sender.Write(ctx, formatted)
-				if err != nil {
-					errs = append(errs, err)
-					droppedLogRecord := droppedScopeLogs.LogRecords().AppendEmpty()
-					logRecord.CopyTo(droppedLogRecord)
-				}
+	failed := make([]bool, len(formatted))
+	for i, msg := range msgs {
+		err = sender.Write(ctx, msg)
+		if err != nil {
+			errs = append(errs, err)
+			// Signature blocks are not retried: the records they cover are
+			// signed again when they are resent.
+			if origin[i] >= 0 {
+				failed[origin[i]] = true
 			}
 		}
 	}
 
 	if len(errs) > 0 {
 		errs = deduplicateErrors(errs)
-		return consumererror.NewLogs(errors.Join(errs...), droppedLogs)
+		return consumererror.NewLogs(errors.Join(errs...), droppedLogs(logs, failed))
 	}
 
 	return nil
 }
+
+// droppedLogs returns the log records of logs flagged in failed, in the order
+// formatLogs visits them, under their original resource and scope.
+func droppedLogs(logs plog.Logs, failed []bool) plog.Logs {
+	dropped := plog.NewLogs()
+	logs.CopyTo(dropped)
+	next := 0
+	dropped.ResourceLogs().RemoveIf(func(resourceLogs plog.ResourceLogs) bool {
+		resourceLogs.ScopeLogs().RemoveIf(func(scopeLogs plog.ScopeLogs) bool {
+			scopeLogs.LogRecords().RemoveIf(func(plog.LogRecord) bool {
+				sent := !failed[next]
+				next++
+				return sent
+			})
+			return scopeLogs.LogRecords().Len() == 0
+		})
+		return resourceLogs.ScopeLogs().Len() == 0
+	})
+	return dropped
+}
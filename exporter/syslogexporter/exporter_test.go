@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -200,6 +201,62 @@ func TestTCPSyslogExportSuccess(t *testing.T) {
 	assert.Equal(t, expectedForm, string(b))
 }
 
+func TestTCPSyslogExportSigned(t *testing.T) {
+	cfg := createTCPTestConfig()
+	signingCfg := cfg.Signing.GetOrInsertDefault()
+	signingCfg.KeyFile = "testdata/dsa_key.pem"
+	test := prepareTCPExporterTest(t, cfg, false)
+	require.NotNil(t, test.exp)
+	defer test.srv.Close()
+	err := sendTestMessage(t, test.exp)
+	assert.NoError(t, err, "could not send message")
+	err = test.srv.SetDeadline(time.Now().Add(time.Second * 1))
+	require.NoError(t, err, "cannot set deadline")
+	conn, err := test.srv.AcceptTCP()
+	require.NoError(t, err, "could not accept connection")
+	defer conn.Close()
+	b, err := io.ReadAll(conn)
+	require.NoError(t, err, "could not read all")
+
+	lines := strings.SplitAfter(string(b), "\n")
+	for len(lines) > 0 && strings.Contains(lines[0], "[ssign-cert ") {
+		lines = lines[1:]
+	}
+	require.Len(t, lines, 3)
+	assert.Equal(t, expectedForm, lines[0])
+	assert.Contains(t, lines[1], `[ssign VER="0121" `)
+	assert.Contains(t, lines[1], `CNT="1" HB="`+hashOf(strings.TrimSuffix(expectedForm, "\n"))+`"`)
+	assert.Empty(t, lines[2])
+}
+
+func TestDroppedLogsKeepGrouping(t *testing.T) {
+	logs := plog.NewLogs()
+	for _, host := range []string{"a", "b"} {
+		rl := logs.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("host.name", host)
+		for _, scope := range []string{"x", "y"} {
+			sl := rl.ScopeLogs().AppendEmpty()
+			sl.Scope().SetName(scope)
+			sl.LogRecords().AppendEmpty().Body().SetStr(host + scope)
+		}
+	}
+
+	dropped := droppedLogs(logs, []bool{false, true, true, false})
+	require.Equal(t, 2, dropped.ResourceLogs().Len())
+
+	rl := dropped.ResourceLogs().At(0)
+	assert.Equal(t, map[string]any{"host.name": "a"}, rl.Resource().Attributes().AsRaw())
+	require.Equal(t, 1, rl.ScopeLogs().Len())
+	assert.Equal(t, "y", rl.ScopeLogs().At(0).Scope().Name())
+	assert.Equal(t, "ay", rl.ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+
+	rl = dropped.ResourceLogs().At(1)
+	assert.Equal(t, map[string]any{"host.name": "b"}, rl.Resource().Attributes().AsRaw())
+	require.Equal(t, 1, rl.ScopeLogs().Len())
+	assert.Equal(t, "x", rl.ScopeLogs().At(0).Scope().Name())
+	assert.Equal(t, "bx", rl.ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+}
+
 func TestTCPSyslogExportFail(t *testing.T) {
 	test := prepareTCPExporterTest(t, createTCPTestConfig(), true)
 	defer test.srv.Close()
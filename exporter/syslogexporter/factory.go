@@ -35,6 +35,11 @@ func createDefaultConfig() component.Config {
 		BackOffConfig:   configretry.NewDefaultBackOffConfig(),
 		QueueSettings:   qs,
 		TimeoutSettings: exporterhelper.NewDefaultTimeoutConfig(),
+		Signing: configoptional.Default(SigningConfig{
+			HashAlgorithm:       DefaultHashAlgorithm,
+			MaxHashesPerBlock:   DefaultMaxHashesPerBlock,
+			CertificateInterval: DefaultCertificateInterval,
+		}),
 	}
 }
 
@@ -47,5 +47,10 @@ func TestCreateDefaultConfig(t *testing.T) {
 		TimeoutSettings: exporterhelper.TimeoutConfig{
 			Timeout: 5 * time.Second,
 		},
+		Signing: configoptional.Default(SigningConfig{
+			HashAlgorithm:       "sha256",
+			MaxHashesPerBlock:   25,
+			CertificateInterval: time.Hour,
+		}),
 	}, cfg)
 }
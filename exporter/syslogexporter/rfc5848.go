@@ -0,0 +1,401 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package syslogexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/syslogexporter"
+
+import (
+	"crypto"
+	"crypto/dsa" //nolint:staticcheck // RFC 5848 only defines OpenPGP DSA signatures.
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // RFC 5848 allows SHA1 hashes for interoperability with older relays.
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leodido/go-syslog/v4/rfc5424"
+)
+
+const (
+	hashAlgorithmSHA1   = "sha1"
+	hashAlgorithmSHA256 = "sha256"
+
+	// signaturePriority is the PRI of signature and certificate blocks:
+	// facility "log audit" (13), severity "informational" (6).
+	signaturePriority = 13*8 + 6
+	signatureAppName  = "otelcol"
+
+	// maxHashesPerBlock is the largest CNT value allowed by RFC 5848.
+	maxHashesPerBlock = 99
+	// certFragmentLength bounds the size of the payload block fragment carried
+	// by a single certificate block, keeping it well below the 2048 octets
+	// every syslog transport must accept.
+	certFragmentLength = 1024
+
+	// maxRSID is the largest reboot session ID allowed by RFC 5848.
+	maxRSID = 9999999999
+
+	// keyBlobTypeCertificate and keyBlobTypePublicKey identify the content of
+	// certificate blocks, see RFC 5848 section 5.2.
+	keyBlobTypeCertificate = "C"
+	keyBlobTypePublicKey   = "K"
+)
+
+var oidPublicKeyDSA = asn1.ObjectIdentifier{1, 2, 840, 10040, 4, 1}
+
+// rfc5848Signer interleaves RFC 5848 signature blocks and certificate blocks
+// with a stream of RFC 5424 messages. It keeps the state of a single reboot
+// session and signature group (SG 0) and is not safe for concurrent use.
+type rfc5848Signer struct {
+	cfg      *SigningConfig
+	key      *dsa.PrivateKey
+	hash     crypto.Hash
+	hostname string
+	now      func() time.Time
+
+	// ver is the VER parameter: protocol version 01, hash algorithm and
+	// signature scheme 1 (OpenPGP DSA).
+	ver string
+	// rsid is the reboot session ID, see newRebootSessionID.
+	rsid string
+	// payloadBlock is the certificate payload: timestamp, key blob type and
+	// base64 encoded key blob.
+	payloadBlock string
+
+	gbc           uint64 // global block counter
+	nextMessage   uint64 // message number of the next signed message
+	lastCertBlock time.Time
+}
+
+func newRFC5848Signer(cfg *SigningConfig, now func() time.Time) (*rfc5848Signer, error) {
+	key, err := loadDSAPrivateKey(cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyBlobType string
+	var keyBlob []byte
+	if cfg.CertFile != "" {
+		keyBlobType = keyBlobTypeCertificate
+		if keyBlob, err = loadCertificate(cfg.CertFile); err != nil {
+			return nil, err
+		}
+	} else {
+		keyBlobType = keyBlobTypePublicKey
+		if keyBlob, err = marshalDSAPublicKey(&key.PublicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &rfc5848Signer{
+		cfg:         cfg,
+		key:         key,
+		hash:        crypto.SHA256,
+		now:         now,
+		ver:         "0121",
+		nextMessage: 1,
+	}
+	if cfg.HashAlgorithm == hashAlgorithmSHA1 {
+		s.hash = crypto.SHA1
+		s.ver = "0111"
+	}
+	if s.hostname, err = os.Hostname(); err != nil || s.hostname == "" {
+		s.hostname = emptyValue
+	}
+
+	rsid, err := newRebootSessionID(cfg.RSIDFile)
+	if err != nil {
+		return nil, err
+	}
+	s.rsid = strconv.FormatUint(rsid, 10)
+
+	start := s.now()
+	s.payloadBlock = fmt.Sprintf("%s %s %s",
+		start.UTC().Format(rfc5424.RFC3339MICRO), keyBlobType, base64.StdEncoding.EncodeToString(keyBlob))
+	return s, nil
+}
+
+// newRebootSessionID returns the reboot session ID of a new session. When
+// rsidFile is set, the ID is a counter persisted in that file, so that it
+// increases across restarts as required by RFC 5848. Otherwise a random ID is
+// used, which only guarantees that sessions don't reuse one another's ID.
+func newRebootSessionID(rsidFile string) (uint64, error) {
+	if rsidFile == "" {
+		n, err := rand.Int(rand.Reader, big.NewInt(maxRSID))
+		if err != nil {
+			return 0, fmt.Errorf("failed to generate reboot session ID: %w", err)
+		}
+		return n.Uint64() + 1, nil
+	}
+
+	var rsid uint64
+	data, err := os.ReadFile(rsidFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return 0, fmt.Errorf("failed to read reboot session ID: %w", err)
+	default:
+		if rsid, err = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64); err != nil {
+			return 0, fmt.Errorf("failed to parse reboot session ID in %q: %w", rsidFile, err)
+		}
+	}
+	if rsid >= maxRSID {
+		return 0, fmt.Errorf("reboot session ID in %q is exhausted, a new signing key is required", rsidFile)
+	}
+	rsid++
+
+	// Persist the new ID before it is used, through a temporary file so that
+	// a crash cannot leave a truncated counter behind.
+	tmp := rsidFile + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(rsid, 10)+"\n"), 0o600); err != nil {
+		return 0, fmt.Errorf("failed to write reboot session ID: %w", err)
+	}
+	if err := os.Rename(tmp, rsidFile); err != nil {
+		return 0, fmt.Errorf("failed to write reboot session ID: %w", err)
+	}
+	return rsid, nil
+}
+
+// sign returns msgs followed by the signature blocks covering them, and
+// preceded by certificate blocks when they are due. Every returned message
+// is formatted like msgs, with a trailing newline and, when octetCounting is
+// set, an octet count prefix. The second return value reports, for every
+// returned message, the index of the message in msgs or -1 for blocks.
+func (s *rfc5848Signer) sign(msgs []string, octetCounting bool) ([]string, []int, error) {
+	var out []string
+	var origin []int
+	appendBlock := func(block string) {
+		block += "\n"
+		if octetCounting {
+			block = fmt.Sprintf("%d %s", len(block), block)
+		}
+		out = append(out, block)
+		origin = append(origin, -1)
+	}
+
+	now := s.now()
+	if s.lastCertBlock.IsZero() || (s.cfg.CertificateInterval > 0 && now.Sub(s.lastCertBlock) >= s.cfg.CertificateInterval) {
+		blocks, err := s.certificateBlocks(now)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, block := range blocks {
+			appendBlock(block)
+		}
+		s.lastCertBlock = now
+	}
+
+	for start := 0; start < len(msgs); start += s.cfg.MaxHashesPerBlock {
+		end := min(start+s.cfg.MaxHashesPerBlock, len(msgs))
+		hashes := make([]string, 0, end-start)
+		for i := start; i < end; i++ {
+			out = append(out, msgs[i])
+			origin = append(origin, i)
+			hashes = append(hashes, s.hashMessage(msgs[i], octetCounting))
+		}
+		block, err := s.signatureBlock(now, hashes)
+		if err != nil {
+			return nil, nil, err
+		}
+		appendBlock(block)
+	}
+	return out, origin, nil
+}
+
+// hashMessage returns the base64 encoded hash of a formatted message,
+// excluding the transport framing as required by RFC 5848 section 4.2.7.
+func (s *rfc5848Signer) hashMessage(msg string, octetCounting bool) string {
+	if octetCounting {
+		if _, rest, found := strings.Cut(msg, " "); found {
+			msg = rest
+		}
+	}
+	msg = strings.TrimSuffix(msg, "\n")
+	return base64.StdEncoding.EncodeToString(s.digest([]byte(msg)))
+}
+
+func (s *rfc5848Signer) digest(data []byte) []byte {
+	if s.hash == crypto.SHA1 {
+		d := sha1.Sum(data) //nolint:gosec // see import
+		return d[:]
+	}
+	d := sha256.Sum256(data)
+	return d[:]
+}
+
+func (s *rfc5848Signer) signatureBlock(now time.Time, hashes []string) (string, error) {
+	s.gbc++
+	fmn := s.nextMessage
+	s.nextMessage += uint64(len(hashes))
+	params := fmt.Sprintf(`ssign VER="%s" RSID="%s" SG="0" SPRI="%d" GBC="%d" FMN="%d" CNT="%d" HB="%s"`,
+		s.ver, s.rsid, signaturePriority, s.gbc, fmn, len(hashes), strings.Join(hashes, " "))
+	return s.signedMessage(now, params)
+}
+
+func (s *rfc5848Signer) certificateBlocks(now time.Time) ([]string, error) {
+	var blocks []string
+	tbpl := len(s.payloadBlock)
+	for index := 0; index < tbpl; index += certFragmentLength {
+		frag := s.payloadBlock[index:min(index+certFragmentLength, tbpl)]
+		params := fmt.Sprintf(`ssign-cert VER="%s" RSID="%s" SG="0" SPRI="%d" TBPL="%d" INDEX="%d" FLEN="%d" FRAG="%s"`,
+			s.ver, s.rsid, signaturePriority, tbpl, index+1, len(frag), frag)
+		block, err := s.signedMessage(now, params)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// signedMessage formats a block message with the given structured data
+// parameters and signs it. As described in RFC 5848 section 5.1.1, the
+// signature is computed over the complete message with an empty SIGN value.
+func (s *rfc5848Signer) signedMessage(now time.Time, params string) (string, error) {
+	prefix := fmt.Sprintf(`<%d>%d %s %s %s - - [%s SIGN="`,
+		signaturePriority, versionRFC5424, now.UTC().Format(rfc5424.RFC3339MICRO), s.hostname, signatureAppName, params)
+	const suffix = `"]`
+
+	r, ss, err := dsa.Sign(rand.Reader, s.key, s.digest([]byte(prefix+suffix)))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign syslog block: %w", err)
+	}
+	return prefix + base64.StdEncoding.EncodeToString(encodeOpenPGPDSASignature(r, ss)) + suffix, nil
+}
+
+// encodeOpenPGPDSASignature encodes a DSA signature as the algorithm-specific
+// fields of an OpenPGP signature packet (RFC 4880 section 5.2.2): the
+// multiprecision integers r and s.
+func encodeOpenPGPDSASignature(r, s *big.Int) []byte {
+	var out []byte
+	for _, n := range []*big.Int{r, s} {
+		bitLen := n.BitLen()
+		out = append(out, byte(bitLen>>8), byte(bitLen))
+		out = append(out, n.Bytes()...)
+	}
+	return out
+}
+
+// dsaPrivateKey is the OpenSSL "DSA PRIVATE KEY" structure.
+type dsaPrivateKey struct {
+	Version       int
+	P, Q, G, Y, X *big.Int
+}
+
+// pkcs8 is the PKCS #8 "PRIVATE KEY" structure.
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// loadDSAPrivateKey reads a PEM encoded DSA private key, either in the
+// OpenSSL traditional format or in PKCS #8 form, neither of which crypto/x509
+// supports for DSA.
+func loadDSAPrivateKey(path string) (*dsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing key must be PEM encoded")
+	}
+
+	var key *dsa.PrivateKey
+	switch block.Type {
+	case "DSA PRIVATE KEY":
+		key, err = parseDSAPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = parsePKCS8DSAPrivateKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	return key, nil
+}
+
+func parseDSAPrivateKey(der []byte) (*dsa.PrivateKey, error) {
+	var k dsaPrivateKey
+	if _, err := asn1.Unmarshal(der, &k); err != nil {
+		return nil, err
+	}
+	return &dsa.PrivateKey{
+		PublicKey: dsa.PublicKey{
+			Parameters: dsa.Parameters{P: k.P, Q: k.Q, G: k.G},
+			Y:          k.Y,
+		},
+		X: k.X,
+	}, nil
+}
+
+func parsePKCS8DSAPrivateKey(der []byte) (*dsa.PrivateKey, error) {
+	var k pkcs8
+	if _, err := asn1.Unmarshal(der, &k); err != nil {
+		return nil, err
+	}
+	if !k.Algo.Algorithm.Equal(oidPublicKeyDSA) {
+		return nil, fmt.Errorf("not a DSA key: algorithm %s", k.Algo.Algorithm)
+	}
+	var params dsa.Parameters
+	if _, err := asn1.Unmarshal(k.Algo.Parameters.FullBytes, &params); err != nil {
+		return nil, err
+	}
+	x := new(big.Int)
+	if _, err := asn1.Unmarshal(k.PrivateKey, &x); err != nil {
+		return nil, err
+	}
+	return &dsa.PrivateKey{
+		PublicKey: dsa.PublicKey{
+			Parameters: params,
+			Y:          new(big.Int).Exp(params.G, x, params.P),
+		},
+		X: x,
+	}, nil
+}
+
+func loadCertificate(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("signing certificate must be a PEM encoded CERTIFICATE")
+	}
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		return nil, fmt.Errorf("failed to parse signing certificate: %w", err)
+	}
+	return block.Bytes, nil
+}
+
+// marshalDSAPublicKey encodes a DSA public key as a PKIX SubjectPublicKeyInfo,
+// which crypto/x509 does not support for DSA.
+func marshalDSAPublicKey(pub *dsa.PublicKey) ([]byte, error) {
+	params, err := asn1.Marshal(pub.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	y, err := asn1.Marshal(pub.Y)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyDSA, Parameters: asn1.RawValue{FullBytes: params}},
+		PublicKey: asn1.BitString{Bytes: y, BitLength: 8 * len(y)},
+	})
+}
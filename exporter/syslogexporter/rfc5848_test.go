@@ -0,0 +1,258 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package syslogexporter
+
+import (
+	"crypto/dsa" //nolint:staticcheck // RFC 5848 only defines OpenPGP DSA signatures.
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	sdParamRegexp = regexp.MustCompile(`([A-Z]+)="([^"]*)"`)
+	signTime      = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+)
+
+func newTestSigner(t *testing.T, maxHashes int) *rfc5848Signer {
+	t.Helper()
+	s, err := newRFC5848Signer(&SigningConfig{
+		KeyFile:             "testdata/dsa_key.pem",
+		HashAlgorithm:       hashAlgorithmSHA256,
+		MaxHashesPerBlock:   maxHashes,
+		CertificateInterval: time.Hour,
+	}, func() time.Time { return signTime })
+	require.NoError(t, err)
+	return s
+}
+
+// parseBlock returns the SD-ID and parameters of a signature or
+// certificate block, and verifies its signature.
+func parseBlock(t *testing.T, pub *dsa.PublicKey, block string) (string, map[string]string) {
+	t.Helper()
+	block = strings.TrimSuffix(block, "\n")
+	start := strings.Index(block, "[")
+	require.GreaterOrEqual(t, start, 0, "block has no structured data: %q", block)
+	sdID, _, _ := strings.Cut(block[start+1:], " ")
+
+	params := map[string]string{}
+	for _, m := range sdParamRegexp.FindAllStringSubmatch(block[start:], -1) {
+		params[m[1]] = m[2]
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(params["SIGN"])
+	require.NoError(t, err)
+	r, s := decodeOpenPGPDSASignature(t, sig)
+	unsigned := strings.Replace(block, `SIGN="`+params["SIGN"]+`"`, `SIGN=""`, 1)
+	digest := sha256.Sum256([]byte(unsigned))
+	assert.True(t, dsa.Verify(pub, digest[:], r, s), "invalid signature on %q", block)
+	return sdID, params
+}
+
+func decodeOpenPGPDSASignature(t *testing.T, sig []byte) (*big.Int, *big.Int) {
+	var ints []*big.Int
+	for len(sig) > 0 {
+		require.GreaterOrEqual(t, len(sig), 2)
+		n := (int(sig[0])<<8 | int(sig[1]) + 7) / 8
+		require.GreaterOrEqual(t, len(sig), 2+n)
+		ints = append(ints, new(big.Int).SetBytes(sig[2:2+n]))
+		sig = sig[2+n:]
+	}
+	require.Len(t, ints, 2)
+	return ints[0], ints[1]
+}
+
+func hashOf(msg string) string {
+	d := sha256.Sum256([]byte(msg))
+	return base64.StdEncoding.EncodeToString(d[:])
+}
+
+// certBlockCount returns the number of certificate blocks needed to carry
+// the payload block of s.
+func certBlockCount(s *rfc5848Signer) int {
+	return (len(s.payloadBlock) + certFragmentLength - 1) / certFragmentLength
+}
+
+func TestRebootSessionID(t *testing.T) {
+	rsidFile := filepath.Join(t.TempDir(), "rsid")
+	for want := uint64(1); want <= 3; want++ {
+		rsid, err := newRebootSessionID(rsidFile)
+		require.NoError(t, err)
+		assert.Equal(t, want, rsid)
+	}
+
+	require.NoError(t, os.WriteFile(rsidFile, []byte("9999999999\n"), 0o600))
+	_, err := newRebootSessionID(rsidFile)
+	assert.ErrorContains(t, err, "is exhausted")
+
+	require.NoError(t, os.WriteFile(rsidFile, []byte("abc"), 0o600))
+	_, err = newRebootSessionID(rsidFile)
+	assert.ErrorContains(t, err, "failed to parse reboot session ID")
+
+	// Without a file, sessions started within the same second still get
+	// distinct IDs.
+	first, err := newRebootSessionID("")
+	require.NoError(t, err)
+	second, err := newRebootSessionID("")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.LessOrEqual(t, first, uint64(maxRSID))
+}
+
+func TestRFC5848Sign(t *testing.T) {
+	s := newTestSigner(t, 2)
+	pub := &s.key.PublicKey
+	msgs := []string{"<165>1 - - - - - - first\n", "<165>1 - - - - - - second\n", "<165>1 - - - - - - third\n"}
+	n := certBlockCount(s)
+
+	out, origin, err := s.sign(msgs, false)
+	require.NoError(t, err)
+	require.Len(t, out, n+5)
+	for i := range n {
+		assert.Equal(t, -1, origin[i])
+	}
+	assert.Equal(t, []int{0, 1, -1, 2, -1}, origin[n:])
+
+	sdID, cert := parseBlock(t, pub, out[0])
+	assert.Equal(t, "ssign-cert", sdID)
+	assert.Equal(t, "0121", cert["VER"])
+	assert.Equal(t, s.rsid, cert["RSID"])
+	assert.Equal(t, "1", cert["INDEX"])
+	assert.Equal(t, strconv.Itoa(len(s.payloadBlock)), cert["TBPL"])
+	assert.True(t, strings.HasPrefix(out[0], "<110>1 2024-05-06T07:08:09Z "))
+
+	out = out[n:]
+	sdID, first := parseBlock(t, pub, out[2])
+	assert.Equal(t, "ssign", sdID)
+	assert.Equal(t, map[string]string{
+		"VER":  "0121",
+		"RSID": cert["RSID"],
+		"SG":   "0",
+		"SPRI": "110",
+		"GBC":  "1",
+		"FMN":  "1",
+		"CNT":  "2",
+		"HB":   hashOf("<165>1 - - - - - - first") + " " + hashOf("<165>1 - - - - - - second"),
+		"SIGN": first["SIGN"],
+	}, first)
+
+	_, second := parseBlock(t, pub, out[4])
+	assert.Equal(t, "2", second["GBC"])
+	assert.Equal(t, "3", second["FMN"])
+	assert.Equal(t, "1", second["CNT"])
+	assert.Equal(t, hashOf("<165>1 - - - - - - third"), second["HB"])
+
+	// Certificate blocks are not resent within the interval, and numbering
+	// continues across calls.
+	out, origin, err = s.sign(msgs[:1], false)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, -1}, origin)
+	_, third := parseBlock(t, pub, out[1])
+	assert.Equal(t, "3", third["GBC"])
+	assert.Equal(t, "4", third["FMN"])
+
+	s.now = func() time.Time { return signTime.Add(time.Hour) }
+	_, origin, err = s.sign(msgs[:1], false)
+	require.NoError(t, err)
+	assert.Len(t, origin, n+2)
+	assert.Equal(t, []int{0, -1}, origin[n:])
+}
+
+func TestRFC5848SignOctetCounting(t *testing.T) {
+	s := newTestSigner(t, 25)
+	msg := "<165>1 - - - - - - first\n"
+
+	out, origin, err := s.sign([]string{"25 " + msg}, true)
+	require.NoError(t, err)
+	require.Len(t, out, certBlockCount(s)+2)
+
+	for i, block := range out {
+		if origin[i] >= 0 {
+			continue
+		}
+		count, rest, found := strings.Cut(block, " ")
+		require.True(t, found)
+		assert.Equal(t, strconv.Itoa(len(rest)), count)
+	}
+	_, params := parseBlock(t, &s.key.PublicKey, strings.SplitN(out[len(out)-1], " ", 2)[1])
+	assert.Equal(t, hashOf(strings.TrimSuffix(msg, "\n")), params["HB"])
+}
+
+func TestRFC5848CertificatePayload(t *testing.T) {
+	s := newTestSigner(t, 25)
+	s.payloadBlock = strings.Repeat("x", 2*certFragmentLength+10)
+
+	blocks, err := s.certificateBlocks(signTime)
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+
+	var payload strings.Builder
+	for i, block := range blocks {
+		_, params := parseBlock(t, &s.key.PublicKey, block)
+		assert.Equal(t, strconv.Itoa(i*certFragmentLength+1), params["INDEX"])
+		assert.Equal(t, strconv.Itoa(len(params["FRAG"])), params["FLEN"])
+		payload.WriteString(params["FRAG"])
+	}
+	assert.Equal(t, s.payloadBlock, payload.String())
+}
+
+func TestRFC5848PublicKeyBlob(t *testing.T) {
+	s := newTestSigner(t, 25)
+
+	fields := strings.Fields(s.payloadBlock)
+	require.Len(t, fields, 3)
+	assert.Equal(t, "2024-05-06T07:08:09Z", fields[0])
+	assert.Equal(t, keyBlobTypePublicKey, fields[1])
+
+	der, err := base64.StdEncoding.DecodeString(fields[2])
+	require.NoError(t, err)
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	_, err = asn1.Unmarshal(der, &spki)
+	require.NoError(t, err)
+	assert.True(t, spki.Algorithm.Algorithm.Equal(oidPublicKeyDSA))
+	var y *big.Int
+	_, err = asn1.Unmarshal(spki.PublicKey.Bytes, &y)
+	require.NoError(t, err)
+	assert.Equal(t, 0, y.Cmp(s.key.Y))
+}
+
+func TestLoadDSAPrivateKey(t *testing.T) {
+	traditional, err := loadDSAPrivateKey("testdata/dsa_key.pem")
+	require.NoError(t, err)
+	pkcs8Key, err := loadDSAPrivateKey("testdata/dsa_key_pkcs8.pem")
+	require.NoError(t, err)
+	assert.Equal(t, traditional, pkcs8Key)
+
+	_, err = loadDSAPrivateKey("testdata/missing.pem")
+	assert.ErrorContains(t, err, "failed to read signing key")
+
+	_, err = loadDSAPrivateKey("rfc5848.go")
+	assert.EqualError(t, err, "signing key must be PEM encoded")
+}
+
+func TestRFC5848SHA1(t *testing.T) {
+	s, err := newRFC5848Signer(&SigningConfig{
+		KeyFile:           "testdata/dsa_key.pem",
+		HashAlgorithm:     hashAlgorithmSHA1,
+		MaxHashesPerBlock: 25,
+	}, time.Now)
+	require.NoError(t, err)
+	assert.Equal(t, "0111", s.ver)
+	assert.Len(t, s.digest([]byte("message")), 20)
+}
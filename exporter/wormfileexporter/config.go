@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package wormfileexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/wormfileexporter"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	formatJSON  = "json"
+	formatProto = "proto"
+)
+
+// Config defines configuration for the WORM file exporter.
+type Config struct {
+	// Path of the file being written. Rotated files are renamed next to it
+	// with the rotation time inserted before the extension.
+	Path string `mapstructure:"path"`
+
+	// Format of the batches carried by every line.
+	// Options:
+	// - json[default]: OTLP JSON, embedded as is.
+	// - proto: OTLP binary protobuf, embedded as a base64 string.
+	Format string `mapstructure:"format"`
+
+	// Rotation defines when the file is sealed and a new one started.
+	Rotation Rotation `mapstructure:"rotation"`
+}
+
+// Rotation defines when files are rotated.
+type Rotation struct {
+	// MaxMegabytes is the size in megabytes after which the file is rotated.
+	// 0 disables size based rotation.
+	MaxMegabytes int `mapstructure:"max_megabytes"`
+
+	// Interval is the time after which the file is rotated, counted from
+	// when the exporter opened it. 0 disables time based rotation.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Validate checks if the exporter configuration is valid.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.Path == "" {
+		errs = append(errs, errors.New("path must be non-empty"))
+	}
+	if cfg.Format != formatJSON && cfg.Format != formatProto {
+		errs = append(errs, fmt.Errorf("format %q is not supported, must be %q or %q", cfg.Format, formatJSON, formatProto))
+	}
+	if cfg.Rotation.MaxMegabytes < 0 {
+		errs = append(errs, errors.New("rotation::max_megabytes must not be negative"))
+	}
+	if cfg.Rotation.Interval < 0 {
+		errs = append(errs, errors.New("rotation::interval must not be negative"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,7 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package wormfileexporter writes logs to hash chained, write-once files.
+package wormfileexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/wormfileexporter"
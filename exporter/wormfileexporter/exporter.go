@@ -0,0 +1,277 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package wormfileexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/wormfileexporter"
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/hashchain"
+)
+
+const (
+	filePermissions   = 0o640
+	sealedPermissions = 0o440
+	rotationTimestamp = "20060102T150405.000000000Z"
+)
+
+type wormFileExporter struct {
+	config *Config
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu sync.Mutex
+	// started is set between start and shutdown. While started, a nil file
+	// means the last write failed and the file is reopened on the next one.
+	started  bool
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	state    hashchain.State
+}
+
+func newWormFileExporter(logger *zap.Logger, cfg *Config) *wormFileExporter {
+	return &wormFileExporter{
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+		state:  hashchain.Genesis(),
+	}
+}
+
+func (e *wormFileExporter) start(context.Context, component.Host) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.open(); err != nil {
+		return err
+	}
+	e.started = true
+	return nil
+}
+
+func (e *wormFileExporter) shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.started = false
+	if e.file == nil {
+		return nil
+	}
+	err := e.file.Close()
+	e.file = nil
+	return err
+}
+
+// open opens the file at the configured path for appending. An existing file
+// is verified first: appending is refused if its chain is broken or doesn't
+// continue from the seal of the last rotated file, and a sealed file is
+// rotated away.
+func (e *wormFileExporter) open() error {
+	last, err := e.lastSeal()
+	if err != nil {
+		return err
+	}
+	e.state = last
+
+	f, err := os.Open(e.config.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return e.create()
+	case err != nil:
+		return err
+	}
+	state, err := hashchain.Verify(f, last, nil)
+	_ = f.Close()
+	if err != nil {
+		return fmt.Errorf("refusing to append to %s, hash chain verification failed: %w", e.config.Path, err)
+	}
+	e.state = state
+	if state.Sealed {
+		e.logger.Info("file is sealed, rotating", zap.String("path", e.config.Path))
+		if err := e.moveSealed(); err != nil {
+			return err
+		}
+		return e.create()
+	}
+
+	if e.file, err = os.OpenFile(e.config.Path, os.O_WRONLY|os.O_APPEND, 0); err != nil {
+		return err
+	}
+	fi, err := e.file.Stat()
+	if err != nil {
+		return err
+	}
+	e.size = fi.Size()
+	e.openedAt = e.now()
+	return nil
+}
+
+// lastSeal returns the chain state at the seal of the most recently rotated
+// file, or the genesis state when no file was rotated yet.
+func (e *wormFileExporter) lastSeal() (hashchain.State, error) {
+	dir, base := filepath.Split(e.config.Path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return hashchain.State{}, err
+	}
+	// Rotated file names sort in rotation order.
+	var last string
+	for _, entry := range entries {
+		name := entry.Name()
+		ts, ok := strings.CutPrefix(name, prefix)
+		if !ok || !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		if _, err := time.Parse(rotationTimestamp, strings.TrimSuffix(ts, ext)); err == nil && name > last {
+			last = name
+		}
+	}
+	if last == "" {
+		return hashchain.Genesis(), nil
+	}
+
+	path := filepath.Join(dir, last)
+	f, err := os.Open(path)
+	if err != nil {
+		return hashchain.State{}, err
+	}
+	defer f.Close()
+	state, err := hashchain.ReadSeal(f)
+	if err != nil {
+		return hashchain.State{}, fmt.Errorf("refusing to append to %s, cannot read the seal of %s: %w", e.config.Path, path, err)
+	}
+	return state, nil
+}
+
+// create starts a new file. Its first entry links to the last entry written,
+// usually the seal of the previous file.
+func (e *wormFileExporter) create() error {
+	f, err := os.OpenFile(e.config.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_EXCL, filePermissions)
+	if err != nil {
+		return err
+	}
+	e.file = f
+	e.size = 0
+	e.openedAt = e.now()
+	e.state.Entries = 0
+	e.state.Sealed = false
+	return nil
+}
+
+func (e *wormFileExporter) consumeLogs(_ context.Context, ld plog.Logs) error {
+	data, err := e.marshal(ld)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.started {
+		return errors.New("exporter is not started")
+	}
+	if e.file == nil {
+		// The previous write failed: the file is verified again, which fails
+		// if that write left a partial line.
+		if err := e.open(); err != nil {
+			return err
+		}
+	}
+	if e.rotationDue() {
+		if err := e.rotate(); err != nil {
+			return fmt.Errorf("failed to rotate %s: %w", e.config.Path, err)
+		}
+	}
+	return e.write(e.state.Append(hashchain.EntryTypeLogs, data))
+}
+
+func (e *wormFileExporter) marshal(ld plog.Logs) ([]byte, error) {
+	if e.config.Format == formatProto {
+		data, err := (&plog.ProtoMarshaler{}).MarshalLogs(ld)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(base64.StdEncoding.EncodeToString(data))
+	}
+	return (&plog.JSONMarshaler{}).MarshalLogs(ld)
+}
+
+// write appends a line and syncs it to disk. After a failed write, the file
+// is closed and reopened by the next write, which also restores the chain
+// state. A partial line fails verification, so the file is not appended to
+// anymore.
+func (e *wormFileExporter) write(line []byte) error {
+	n, err := e.file.Write(line)
+	e.size += int64(n)
+	if err == nil {
+		err = e.file.Sync()
+	}
+	if err != nil {
+		_ = e.file.Close()
+		e.file = nil
+		return fmt.Errorf("failed to write to %s, the file will be verified again: %w", e.config.Path, err)
+	}
+	return nil
+}
+
+func (e *wormFileExporter) rotationDue() bool {
+	if e.state.Entries == 0 {
+		return false
+	}
+	rotation := e.config.Rotation
+	if rotation.MaxMegabytes > 0 && e.size >= int64(rotation.MaxMegabytes)<<20 {
+		return true
+	}
+	return rotation.Interval > 0 && e.now().Sub(e.openedAt) >= rotation.Interval
+}
+
+// rotate seals the current file, makes it read-only, moves it aside and
+// starts a new one.
+func (e *wormFileExporter) rotate() error {
+	data, err := json.Marshal(hashchain.Seal{Entries: e.state.Entries, SealedAt: e.now().UTC()})
+	if err != nil {
+		return err
+	}
+	if err := e.write(e.state.Append(hashchain.EntryTypeSeal, data)); err != nil {
+		return err
+	}
+	if err := e.file.Close(); err != nil {
+		return err
+	}
+	e.file = nil
+	if err := e.moveSealed(); err != nil {
+		return err
+	}
+	return e.create()
+}
+
+// moveSealed makes the sealed file read-only and renames it with the current
+// time inserted before its extension.
+func (e *wormFileExporter) moveSealed() error {
+	if err := os.Chmod(e.config.Path, sealedPermissions); err != nil {
+		return err
+	}
+	ext := filepath.Ext(e.config.Path)
+	rotated := strings.TrimSuffix(e.config.Path, ext) + "-" + e.now().UTC().Format(rotationTimestamp) + ext
+	if _, err := os.Stat(rotated); err == nil {
+		return fmt.Errorf("rotated file %s already exists", rotated)
+	}
+	if err := os.Rename(e.config.Path, rotated); err != nil {
+		return err
+	}
+	e.logger.Info("sealed file", zap.String("path", rotated), zap.Uint64("last_seq", e.state.Seq))
+	return nil
+}
@@ -0,0 +1,262 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package wormfileexporter
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/hashchain"
+)
+
+var testTime = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+func testConfig(t *testing.T) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Path = filepath.Join(t.TempDir(), "audit.ndjson")
+	return cfg
+}
+
+func startExporter(t *testing.T, cfg *Config, now *time.Time) *wormFileExporter {
+	t.Helper()
+	exp := newWormFileExporter(zap.NewNop(), cfg)
+	exp.now = func() time.Time { return *now }
+	require.NoError(t, exp.start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { assert.NoError(t, exp.shutdown(context.Background())) })
+	return exp
+}
+
+func makeLogs(body string) plog.Logs {
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr(body)
+	return ld
+}
+
+func readEntries(t *testing.T, path string) []hashchain.Entry {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var entries []hashchain.Entry
+	for line := range strings.SplitSeq(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var e hashchain.Entry
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func bodyOf(t *testing.T, data []byte) string {
+	t.Helper()
+	ld, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(data)
+	require.NoError(t, err)
+	return ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str()
+}
+
+func TestConsumeLogs(t *testing.T) {
+	now := testTime
+	cfg := testConfig(t)
+	exp := startExporter(t, cfg, &now)
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("first")))
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("second")))
+
+	entries := readEntries(t, cfg.Path)
+	require.Len(t, entries, 2)
+	assert.Equal(t, uint64(1), entries[0].Seq)
+	assert.Equal(t, hashchain.EntryTypeLogs, entries[0].Type)
+	assert.Equal(t, "first", bodyOf(t, entries[0].Data))
+	assert.Equal(t, entries[0].Hash, entries[1].Prev)
+	assert.Equal(t, "second", bodyOf(t, entries[1].Data))
+
+	// A restarted exporter continues the chain.
+	require.NoError(t, exp.shutdown(t.Context()))
+	exp = startExporter(t, cfg, &now)
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("third")))
+
+	f, err := os.Open(cfg.Path)
+	require.NoError(t, err)
+	defer f.Close()
+	state, err := hashchain.Verify(f, hashchain.Genesis(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), state.Seq)
+	assert.Equal(t, uint64(3), state.Entries)
+}
+
+func TestConsumeLogsProto(t *testing.T) {
+	now := testTime
+	cfg := testConfig(t)
+	cfg.Format = formatProto
+	exp := startExporter(t, cfg, &now)
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("first")))
+
+	entries := readEntries(t, cfg.Path)
+	require.Len(t, entries, 1)
+	var encoded string
+	require.NoError(t, json.Unmarshal(entries[0].Data, &encoded))
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	ld, err := (&plog.ProtoUnmarshaler{}).UnmarshalLogs(data)
+	require.NoError(t, err)
+	assert.Equal(t, "first", ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+}
+
+func TestRotation(t *testing.T) {
+	now := testTime
+	cfg := testConfig(t)
+	cfg.Rotation = Rotation{MaxMegabytes: 1, Interval: time.Hour}
+	exp := startExporter(t, cfg, &now)
+	rotatedPath := func(ts time.Time) string {
+		return filepath.Join(filepath.Dir(cfg.Path), "audit-"+ts.Format(rotationTimestamp)+".ndjson")
+	}
+
+	// Size based rotation happens before the write following the one
+	// exceeding the limit.
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs(strings.Repeat("x", 1<<20))))
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("after size rotation")))
+
+	sealed := readEntries(t, rotatedPath(testTime))
+	require.Len(t, sealed, 2)
+	assert.Equal(t, hashchain.EntryTypeSeal, sealed[1].Type)
+	assert.JSONEq(t, `{"entries":1,"sealed_at":"2024-05-06T07:08:09Z"}`, string(sealed[1].Data))
+	fi, err := os.Stat(rotatedPath(testTime))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(sealedPermissions), fi.Mode().Perm())
+
+	// The new file continues the chain from the seal.
+	current := readEntries(t, cfg.Path)
+	require.Len(t, current, 1)
+	assert.Equal(t, uint64(3), current[0].Seq)
+	assert.Equal(t, sealed[1].Hash, current[0].Prev)
+
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("not rotated yet")))
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("after time rotation")))
+	assert.Len(t, readEntries(t, rotatedPath(now)), 3)
+	assert.Len(t, readEntries(t, cfg.Path), 1)
+}
+
+func TestRefuseTamperedFile(t *testing.T) {
+	now := testTime
+	cfg := testConfig(t)
+	exp := startExporter(t, cfg, &now)
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("first")))
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("second")))
+	require.NoError(t, exp.shutdown(t.Context()))
+
+	data, err := os.ReadFile(cfg.Path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(cfg.Path, []byte(strings.Replace(string(data), "first", "forged", 1)), 0o600))
+
+	exp = newWormFileExporter(zap.NewNop(), cfg)
+	err = exp.start(t.Context(), componenttest.NewNopHost())
+	assert.EqualError(t, err, "refusing to append to "+cfg.Path+", hash chain verification failed: line 1: hash mismatch")
+}
+
+func TestOpenSealedFile(t *testing.T) {
+	cfg := testConfig(t)
+	state := hashchain.Genesis()
+	file := state.Append(hashchain.EntryTypeLogs, []byte(`{"a":1}`))
+	file = append(file, state.Append(hashchain.EntryTypeSeal, []byte(`{"entries":1}`))...)
+	require.NoError(t, os.WriteFile(cfg.Path, file, 0o600))
+
+	now := testTime
+	exp := startExporter(t, cfg, &now)
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("first")))
+
+	rotated := filepath.Join(filepath.Dir(cfg.Path), "audit-"+testTime.Format(rotationTimestamp)+".ndjson")
+	assert.Len(t, readEntries(t, rotated), 2)
+	entries := readEntries(t, cfg.Path)
+	require.Len(t, entries, 1)
+	assert.Equal(t, state.Seq+1, entries[0].Seq)
+}
+
+// TestRefuseTruncatedFile verifies that removing entries from the start of
+// the file is detected, both for the first file and after a rotation.
+func TestRefuseTruncatedFile(t *testing.T) {
+	now := testTime
+	cfg := testConfig(t)
+	cfg.Rotation = Rotation{Interval: time.Hour}
+	exp := startExporter(t, cfg, &now)
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("first")))
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("second")))
+	require.NoError(t, exp.shutdown(t.Context()))
+
+	data, err := os.ReadFile(cfg.Path)
+	require.NoError(t, err)
+	_, rest, _ := strings.Cut(string(data), "\n")
+	require.NoError(t, os.WriteFile(cfg.Path, []byte(rest), 0o600))
+	exp = newWormFileExporter(zap.NewNop(), cfg)
+	err = exp.start(t.Context(), componenttest.NewNopHost())
+	assert.EqualError(t, err, "refusing to append to "+cfg.Path+", hash chain verification failed: line 1: prev does not match the hash of the previous file's seal")
+
+	// After a rotation, the new file must continue from the seal.
+	require.NoError(t, os.WriteFile(cfg.Path, data, 0o600))
+	exp = startExporter(t, cfg, &now)
+	now = now.Add(time.Hour)
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("third")))
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("fourth")))
+	require.NoError(t, exp.shutdown(t.Context()))
+
+	data, err = os.ReadFile(cfg.Path)
+	require.NoError(t, err)
+	_, rest, _ = strings.Cut(string(data), "\n")
+	require.NoError(t, os.WriteFile(cfg.Path, []byte(rest), 0o600))
+	exp = newWormFileExporter(zap.NewNop(), cfg)
+	err = exp.start(t.Context(), componenttest.NewNopHost())
+	assert.ErrorContains(t, err, "line 1: prev does not match the hash of the previous file's seal")
+
+	// An emptied file is not mistaken for the start of the chain either:
+	// new entries continue from the seal.
+	require.NoError(t, os.WriteFile(cfg.Path, nil, 0o600))
+	exp = startExporter(t, cfg, &now)
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("fifth")))
+	entries := readEntries(t, cfg.Path)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint64(4), entries[0].Seq)
+}
+
+// TestWriteFailureReopens verifies that a failed write does not leave the
+// exporter unusable: the file is reopened and verified by the next write.
+func TestWriteFailureReopens(t *testing.T) {
+	now := testTime
+	cfg := testConfig(t)
+	exp := startExporter(t, cfg, &now)
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("first")))
+
+	// Closing the file makes the next write fail without writing anything.
+	require.NoError(t, exp.file.Close())
+	err := exp.consumeLogs(t.Context(), makeLogs("lost"))
+	assert.ErrorContains(t, err, "failed to write to "+cfg.Path)
+
+	require.NoError(t, exp.consumeLogs(t.Context(), makeLogs("second")))
+	entries := readEntries(t, cfg.Path)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "second", bodyOf(t, entries[1].Data))
+	assert.Equal(t, uint64(2), entries[1].Seq)
+
+	// A partial line is reported by the writes following the failure.
+	f, err := os.OpenFile(cfg.Path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"seq":3,`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, exp.file.Close())
+	assert.ErrorContains(t, exp.consumeLogs(t.Context(), makeLogs("lost")), "failed to write to "+cfg.Path)
+	for range 2 {
+		err = exp.consumeLogs(t.Context(), makeLogs("third"))
+		assert.EqualError(t, err, "refusing to append to "+cfg.Path+", hash chain verification failed: line 3: incomplete entry")
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package collectoridentityextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/collectoridentityextension"
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configoptional"
+)
+
+// Config defines configuration for the collector identity extension.
+type Config struct {
+	// Storage is the ID of a storage extension in which the identity key is
+	// kept across restarts. Without it, a new identity is generated on every
+	// start.
+	Storage *component.ID `mapstructure:"storage"`
+
+	// Seal encrypts the identity key with the OpenBao Transit secrets engine
+	// before it is written to storage, so that the storage alone does not
+	// reveal it. Requires storage.
+	Seal configoptional.Optional[TransitConfig] `mapstructure:"seal"`
+}
+
+// TransitConfig defines how to reach the Transit secrets engine.
+type TransitConfig struct {
+	// ClientConfig configures the HTTP client; endpoint is the address of the
+	// OpenBao server, for example https://openbao.example.com:8200.
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	// Token is the token used to authenticate, sent as X-Vault-Token.
+	Token configopaque.String `mapstructure:"token"`
+
+	// Namespace is the namespace of the mount, sent as X-Vault-Namespace.
+	// Optional.
+	Namespace string `mapstructure:"namespace"`
+
+	// MountPath is the path the Transit engine is mounted at. Default: "transit".
+	MountPath string `mapstructure:"mount_path"`
+
+	// KeyName is the name of the Transit key sealing the identity key.
+	KeyName string `mapstructure:"key_name"`
+}
+
+// Validate checks if the extension configuration is valid.
+func (cfg *Config) Validate() error {
+	if cfg.Seal.HasValue() && cfg.Storage == nil {
+		return errors.New("seal requires storage")
+	}
+	return nil
+}
+
+// Validate checks if the Transit configuration is valid.
+func (cfg *TransitConfig) Validate() error {
+	var errs []error
+	if cfg.Endpoint == "" {
+		errs = append(errs, errors.New("seal::endpoint must be specified"))
+	}
+	if cfg.MountPath == "" {
+		errs = append(errs, errors.New("seal::mount_path must be specified"))
+	}
+	if cfg.KeyName == "" {
+		errs = append(errs, errors.New("seal::key_name must be specified"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package collectoridentityextension
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/collectoridentityextension/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	storageID := component.MustNewID("file_storage")
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr string
+	}{
+		{
+			id: component.NewID(metadata.Type),
+			expected: func() *Config {
+				return createDefaultConfig().(*Config)
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "sealed"),
+			expected: func() *Config {
+				clientConfig := confighttp.NewDefaultClientConfig()
+				clientConfig.Endpoint = "https://openbao.example.com:8200"
+				return &Config{
+					Storage: &storageID,
+					Seal: configoptional.Some(TransitConfig{
+						ClientConfig: clientConfig,
+						Token:        "s.token",
+						Namespace:    "audit",
+						MountPath:    "transit",
+						KeyName:      "collector-identity",
+					}),
+				}
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "nostorage"),
+			expectedErr: "seal requires storage",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "nokey"),
+			expectedErr: "seal::key_name must be specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package collectoridentityextension establishes a persistent identity key for
+// the collector and signs self-attestation records with it.
+package collectoridentityextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/collectoridentityextension"
@@ -0,0 +1,283 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package collectoridentityextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/collectoridentityextension"
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/extensioncapabilities"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/collectoridentityextension/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/transit"
+)
+
+const (
+	// AttestationEventName is the event name of self-attestation records.
+	AttestationEventName = "collector.attestation"
+	// AttestationSignatureAttribute is the attribute holding the signature
+	// of the record body by the identity key.
+	AttestationSignatureAttribute = "collector.attestation.signature"
+	// IdentityAttribute is the attribute holding the collector identity ID.
+	IdentityAttribute = "collector.identity.id"
+)
+
+// Identity is the identity of the collector.
+type Identity struct {
+	// ID identifies the collector: the first 16 bytes of the SHA-256 hash of
+	// the PKIX public key of its identity key, hex encoded.
+	ID string
+	// Signer signs with the Ed25519 identity key.
+	Signer crypto.Signer
+}
+
+// IdentityProvider is implemented by the extension. Components find it among
+// the host extensions.
+type IdentityProvider interface {
+	// Identity returns the identity of the collector.
+	Identity() Identity
+	// Attest returns a self-attestation record describing the collector,
+	// signed with the identity key.
+	Attest() (plog.Logs, error)
+}
+
+var (
+	_ IdentityProvider                    = (*identityExtension)(nil)
+	_ extensioncapabilities.ConfigWatcher = (*identityExtension)(nil)
+)
+
+type identityExtension struct {
+	config *Config
+	set    extension.Settings
+	logger *zap.Logger
+	now    func() time.Time
+
+	storageClient storage.Client
+	transit       *transit.Client
+	identity      Identity
+	publicKey     []byte
+
+	mu         sync.Mutex
+	configHash string
+}
+
+func newIdentityExtension(cfg *Config, set extension.Settings) *identityExtension {
+	return &identityExtension{
+		config: cfg,
+		set:    set,
+		logger: set.Logger,
+		now:    time.Now,
+	}
+}
+
+func (e *identityExtension) Start(ctx context.Context, host component.Host) error {
+	if sealCfg := e.config.Seal.Get(); sealCfg != nil {
+		client, err := sealCfg.ToClient(ctx, host.GetExtensions(), e.set.TelemetrySettings)
+		if err != nil {
+			return err
+		}
+		e.transit = transit.NewClient(client, transit.Settings{
+			Endpoint:  sealCfg.Endpoint,
+			Token:     string(sealCfg.Token),
+			Namespace: sealCfg.Namespace,
+			MountPath: sealCfg.MountPath,
+			KeyName:   sealCfg.KeyName,
+		})
+	}
+
+	var der []byte
+	if e.config.Storage != nil {
+		client, err := getStorageClient(ctx, host, e.config.Storage, e.set.ID)
+		if err != nil {
+			return err
+		}
+		e.storageClient = client
+		if der, err = e.loadKey(ctx); err != nil {
+			return err
+		}
+	} else {
+		e.logger.Warn("No storage configured, the collector identity changes on every start")
+	}
+
+	var key ed25519.PrivateKey
+	if der == nil {
+		var err error
+		if _, key, err = ed25519.GenerateKey(rand.Reader); err != nil {
+			return err
+		}
+		if e.storageClient != nil {
+			if der, err = x509.MarshalPKCS8PrivateKey(key); err != nil {
+				return err
+			}
+			if err = e.storeKey(ctx, der); err != nil {
+				return err
+			}
+		}
+	} else {
+		parsed, err := x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return fmt.Errorf("failed to parse identity key: %w", err)
+		}
+		var ok bool
+		if key, ok = parsed.(ed25519.PrivateKey); !ok {
+			return fmt.Errorf("unsupported identity key type %T", parsed)
+		}
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(publicKey)
+	e.identity = Identity{ID: hex.EncodeToString(sum[:16]), Signer: key}
+	e.publicKey = publicKey
+	e.logger.Info("Collector identity established", zap.String("identity", e.identity.ID))
+	return nil
+}
+
+func (e *identityExtension) Shutdown(ctx context.Context) error {
+	if e.storageClient != nil {
+		return e.storageClient.Close(ctx)
+	}
+	return nil
+}
+
+// NotifyConfig records the hash of the effective configuration reported in
+// attestations.
+func (e *identityExtension) NotifyConfig(_ context.Context, conf *confmap.Conf) error {
+	// Map keys are sorted by encoding/json, making the hash stable.
+	data, err := json.Marshal(conf.ToStringMap())
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	e.mu.Lock()
+	e.configHash = "sha256:" + hex.EncodeToString(sum[:])
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *identityExtension) Identity() Identity {
+	return e.identity
+}
+
+// attestation is the statement signed in self-attestation records.
+type attestation struct {
+	CollectorID    string    `json:"collector_id"`
+	PublicKey      []byte    `json:"public_key"`
+	ServiceName    string    `json:"service_name"`
+	ServiceVersion string    `json:"service_version"`
+	ConfigHash     string    `json:"config_hash,omitempty"`
+	HostName       string    `json:"host_name"`
+	OSType         string    `json:"os_type"`
+	HostArch       string    `json:"host_arch"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+func (e *identityExtension) Attest() (plog.Logs, error) {
+	if e.identity.Signer == nil {
+		return plog.Logs{}, errors.New("collector identity is not established")
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return plog.Logs{}, fmt.Errorf("failed to get host name: %w", err)
+	}
+	e.mu.Lock()
+	configHash := e.configHash
+	e.mu.Unlock()
+
+	now := e.now()
+	statement := attestation{
+		CollectorID:    e.identity.ID,
+		PublicKey:      e.publicKey,
+		ServiceName:    e.set.BuildInfo.Command,
+		ServiceVersion: e.set.BuildInfo.Version,
+		ConfigHash:     configHash,
+		HostName:       hostname,
+		OSType:         runtime.GOOS,
+		HostArch:       runtime.GOARCH,
+		Timestamp:      now.UTC(),
+	}
+	body, err := json.Marshal(statement)
+	if err != nil {
+		return plog.Logs{}, err
+	}
+	signature, err := e.identity.Signer.Sign(rand.Reader, body, crypto.Hash(0))
+	if err != nil {
+		return plog.Logs{}, fmt.Errorf("failed to sign attestation: %w", err)
+	}
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	res := rl.Resource().Attributes()
+	res.PutStr("service.name", statement.ServiceName)
+	res.PutStr("service.version", statement.ServiceVersion)
+	res.PutStr("host.name", statement.HostName)
+	res.PutStr("os.type", statement.OSType)
+	res.PutStr("host.arch", statement.HostArch)
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName(metadata.ScopeName)
+	lr := sl.LogRecords().AppendEmpty()
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(now))
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(now))
+	lr.SetEventName(AttestationEventName)
+	lr.SetSeverityNumber(plog.SeverityNumberInfo)
+	lr.Body().SetStr(string(body))
+	lr.Attributes().PutStr(IdentityAttribute, statement.CollectorID)
+	if configHash != "" {
+		lr.Attributes().PutStr("collector.config.hash", configHash)
+	}
+	lr.Attributes().PutEmptyBytes(AttestationSignatureAttribute).FromRaw(signature)
+	return ld, nil
+}
+
+// VerifyAttestation checks the signature of a self-attestation record against
+// the public key it carries and returns the ID of the attesting collector.
+// Callers must check that the ID is one they trust.
+func VerifyAttestation(lr plog.LogRecord) (string, error) {
+	signature, ok := lr.Attributes().Get(AttestationSignatureAttribute)
+	if !ok || signature.Type() != pcommon.ValueTypeBytes {
+		return "", errors.New("attestation is not signed")
+	}
+	body := []byte(lr.Body().Str())
+	var statement attestation
+	if err := json.Unmarshal(body, &statement); err != nil {
+		return "", fmt.Errorf("failed to decode attestation: %w", err)
+	}
+	pub, err := x509.ParsePKIXPublicKey(statement.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse attestation public key: %w", err)
+	}
+	edPub, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("unsupported attestation public key type %T", pub)
+	}
+	sum := sha256.Sum256(statement.PublicKey)
+	if hex.EncodeToString(sum[:16]) != statement.CollectorID {
+		return "", errors.New("attestation public key does not match the collector ID")
+	}
+	if !ed25519.Verify(edPub, body, signature.Bytes().AsRaw()) {
+		return "", errors.New("invalid attestation signature")
+	}
+	return statement.CollectorID, nil
+}
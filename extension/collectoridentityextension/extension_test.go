@@ -0,0 +1,191 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package collectoridentityextension
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/collectoridentityextension/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+var startTime = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+func newTestExtension(t *testing.T, cfg *Config) *identityExtension {
+	t.Helper()
+	set := extensiontest.NewNopSettings(metadata.Type)
+	// The ID must be stable for the storage client to be reopened.
+	set.ID = component.NewID(metadata.Type)
+	set.BuildInfo.Command = "otelcol-contrib"
+	set.BuildInfo.Version = "1.2.3"
+	ext := newIdentityExtension(cfg, set)
+	ext.now = func() time.Time { return startTime }
+	return ext
+}
+
+func storageConfig() *Config {
+	storageID := storagetest.NewStorageID("test")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = &storageID
+	return cfg
+}
+
+// newTransitServer starts a fake Transit engine "sealing" plaintexts by
+// prefixing them.
+func newTransitServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		var req map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/identity":
+			_, _ = w.Write([]byte(`{"data":{"ciphertext":"vault:v1:` + req["plaintext"] + `"}}`))
+		case "/v1/transit/decrypt/identity":
+			_, _ = w.Write([]byte(`{"data":{"plaintext":"` + strings.TrimPrefix(req["ciphertext"], "vault:v1:") + `"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestIdentityPersistsAcrossRestarts(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+
+	ext := newTestExtension(t, storageConfig())
+	require.NoError(t, ext.Start(t.Context(), host))
+	first := ext.Identity()
+	assert.Len(t, first.ID, 32)
+	require.NoError(t, ext.Shutdown(t.Context()))
+
+	ext = newTestExtension(t, storageConfig())
+	require.NoError(t, ext.Start(t.Context(), host))
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+	assert.Equal(t, first.ID, ext.Identity().ID)
+}
+
+func TestIdentityWithoutStorage(t *testing.T) {
+	first := newTestExtension(t, createDefaultConfig().(*Config))
+	require.NoError(t, first.Start(t.Context(), componenttest.NewNopHost()))
+	second := newTestExtension(t, createDefaultConfig().(*Config))
+	require.NoError(t, second.Start(t.Context(), componenttest.NewNopHost()))
+	assert.NotEqual(t, first.Identity().ID, second.Identity().ID)
+}
+
+func TestSealedIdentity(t *testing.T) {
+	srv := newTransitServer(t)
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+
+	clientConfig := confighttp.NewDefaultClientConfig()
+	clientConfig.Endpoint = srv.URL
+	sealedConfig := storageConfig()
+	sealedConfig.Seal = configoptional.Some(TransitConfig{
+		ClientConfig: clientConfig,
+		Token:        "s.token",
+		MountPath:    "transit",
+		KeyName:      "identity",
+	})
+
+	ext := newTestExtension(t, sealedConfig)
+	require.NoError(t, ext.Start(t.Context(), host))
+	first := ext.Identity()
+
+	data, err := ext.storageClient.Get(t.Context(), storageKey)
+	require.NoError(t, err)
+	var stored storedIdentity
+	require.NoError(t, json.Unmarshal(data, &stored))
+	assert.Empty(t, stored.PrivateKey)
+	assert.True(t, strings.HasPrefix(stored.Sealed, "vault:v1:"))
+	require.NoError(t, ext.Shutdown(t.Context()))
+
+	ext = newTestExtension(t, sealedConfig)
+	require.NoError(t, ext.Start(t.Context(), host))
+	assert.Equal(t, first.ID, ext.Identity().ID)
+	require.NoError(t, ext.Shutdown(t.Context()))
+
+	// A sealed identity is never replaced by a new one.
+	ext = newTestExtension(t, storageConfig())
+	assert.EqualError(t, ext.Start(t.Context(), host), "stored identity is sealed but no seal is configured")
+
+	sealedConfig.Seal.Get().Token = "s.wrong"
+	ext = newTestExtension(t, sealedConfig)
+	assert.ErrorContains(t, ext.Start(t.Context(), host), "failed to unseal identity: transit returned 403 Forbidden: permission denied")
+}
+
+func TestAttest(t *testing.T) {
+	ext := newTestExtension(t, createDefaultConfig().(*Config))
+	_, err := ext.Attest()
+	assert.EqualError(t, err, "collector identity is not established")
+
+	require.NoError(t, ext.Start(t.Context(), componenttest.NewNopHost()))
+	require.NoError(t, ext.NotifyConfig(t.Context(), confmap.NewFromStringMap(map[string]any{
+		"receivers": map[string]any{"otlp": nil},
+	})))
+
+	ld, err := ext.Attest()
+	require.NoError(t, err)
+	rl := ld.ResourceLogs().At(0)
+	version, _ := rl.Resource().Attributes().Get("service.version")
+	assert.Equal(t, "1.2.3", version.Str())
+	lr := rl.ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, AttestationEventName, lr.EventName())
+	hash, _ := lr.Attributes().Get("collector.config.hash")
+	assert.Equal(t, "sha256:166ce3d0600a00414299f6dfe4359e418919528bedac2af0a9d15abee6bc7ce4", hash.Str())
+
+	var statement attestation
+	require.NoError(t, json.Unmarshal([]byte(lr.Body().Str()), &statement))
+	assert.Equal(t, ext.Identity().ID, statement.CollectorID)
+	assert.Equal(t, "otelcol-contrib", statement.ServiceName)
+	assert.Equal(t, startTime, statement.Timestamp)
+
+	id, err := VerifyAttestation(lr)
+	require.NoError(t, err)
+	assert.Equal(t, ext.Identity().ID, id)
+
+	tampered := plog.NewLogRecord()
+	lr.CopyTo(tampered)
+	tampered.Body().SetStr(strings.Replace(lr.Body().Str(), "1.2.3", "1.2.4", 1))
+	_, err = VerifyAttestation(tampered)
+	assert.EqualError(t, err, "invalid attestation signature")
+
+	// A record signed by another collector cannot claim this identity.
+	other := newTestExtension(t, createDefaultConfig().(*Config))
+	require.NoError(t, other.Start(t.Context(), componenttest.NewNopHost()))
+	otherLogs, err := other.Attest()
+	require.NoError(t, err)
+	statement.PublicKey = other.publicKey
+	body, err := json.Marshal(statement)
+	require.NoError(t, err)
+	tampered.Body().SetStr(string(body))
+	otherLogs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().CopyTo(tampered.Attributes())
+	_, err = VerifyAttestation(tampered)
+	assert.EqualError(t, err, "attestation public key does not match the collector ID")
+}
+
+func TestStorageNotFound(t *testing.T) {
+	ext, err := NewFactory().Create(t.Context(), extensiontest.NewNopSettings(metadata.Type), storageConfig())
+	require.NoError(t, err)
+	assert.EqualError(t, ext.Start(t.Context(), componenttest.NewNopHost()), `storage extension "test_storage/test" not found`)
+}
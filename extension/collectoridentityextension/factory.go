@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package collectoridentityextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/collectoridentityextension"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/extension"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/collectoridentityextension/internal/metadata"
+)
+
+// NewFactory creates a factory for the collector identity extension.
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		createExtension,
+		metadata.ExtensionStability,
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Seal: configoptional.Default(TransitConfig{
+			ClientConfig: confighttp.NewDefaultClientConfig(),
+			MountPath:    "transit",
+		}),
+	}
+}
+
+func createExtension(_ context.Context, set extension.Settings, cfg component.Config) (extension.Extension, error) {
+	return newIdentityExtension(cfg.(*Config), set), nil
+}
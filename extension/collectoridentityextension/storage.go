@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package collectoridentityextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/collectoridentityextension"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+)
+
+const storageKey = "identity"
+
+// storedIdentity is the representation of the identity key in storage:
+// either its PKCS #8 encoding or the Transit ciphertext of it.
+type storedIdentity struct {
+	PrivateKey []byte `json:"private_key,omitempty"`
+	Sealed     string `json:"sealed,omitempty"`
+}
+
+// getStorageClient resolves a storage.Client for the extension.
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", storageID)
+	}
+
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", storageID)
+	}
+
+	return storageExt.GetClient(ctx, component.KindExtension, componentID, "")
+}
+
+// loadKey restores the PKCS #8 encoded identity key from storage, unsealing
+// it when sealed. It returns nil when no identity was stored yet.
+func (e *identityExtension) loadKey(ctx context.Context) ([]byte, error) {
+	data, err := e.storageClient.Get(ctx, storageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity from storage: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var stored storedIdentity
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode stored identity: %w", err)
+	}
+	switch {
+	case stored.Sealed != "" && e.transit == nil:
+		// Generating a new identity would silently replace the sealed one.
+		return nil, errors.New("stored identity is sealed but no seal is configured")
+	case stored.Sealed != "":
+		der, err := e.transit.Decrypt(ctx, stored.Sealed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unseal identity: %w", err)
+		}
+		return der, nil
+	default:
+		return stored.PrivateKey, nil
+	}
+}
+
+// storeKey writes the PKCS #8 encoded identity key to storage, sealed when a
+// seal is configured.
+func (e *identityExtension) storeKey(ctx context.Context, der []byte) error {
+	var stored storedIdentity
+	if e.transit != nil {
+		sealed, err := e.transit.Encrypt(ctx, der)
+		if err != nil {
+			return fmt.Errorf("failed to seal identity: %w", err)
+		}
+		stored.Sealed = sealed
+	} else {
+		stored.PrivateKey = der
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	return e.storageClient.Set(ctx, storageKey, data)
+}
@@ -0,0 +1,122 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package keyrotationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configoptional"
+)
+
+const (
+	AlgorithmEd25519   = "ed25519"
+	AlgorithmECDSAP256 = "ecdsa_p256"
+	AlgorithmECDSAP384 = "ecdsa_p384"
+)
+
+// Config defines configuration for the key rotation extension.
+type Config struct {
+	// Algorithm of the generated or requested keys.
+	Algorithm string `mapstructure:"algorithm"`
+
+	// Interval between two rotations.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// GracePeriod during which a replaced key is still offered to
+	// subscribers for verification, before it is retired.
+	GracePeriod time.Duration `mapstructure:"grace_period"`
+
+	// Request obtains new keys from a key issuing service instead of
+	// generating them locally.
+	Request configoptional.Optional[RequestConfig] `mapstructure:"request"`
+
+	// Storage is the ID of a storage extension in which the keys are kept
+	// across restarts. Requires seal.
+	Storage *component.ID `mapstructure:"storage"`
+
+	// Seal encrypts the keys with the OpenBao Transit secrets engine before
+	// they are written to storage, so that the storage alone does not reveal
+	// them.
+	Seal configoptional.Optional[TransitConfig] `mapstructure:"seal"`
+}
+
+// TransitConfig defines how to reach the Transit secrets engine.
+type TransitConfig struct {
+	// ClientConfig configures the HTTP client; endpoint is the address of the
+	// OpenBao server, for example https://openbao.example.com:8200.
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	// Token is the token used to authenticate, sent as X-Vault-Token.
+	Token configopaque.String `mapstructure:"token"`
+
+	// Namespace is the namespace of the mount, sent as X-Vault-Namespace.
+	// Optional.
+	Namespace string `mapstructure:"namespace"`
+
+	// MountPath is the path the Transit engine is mounted at. Default: "transit".
+	MountPath string `mapstructure:"mount_path"`
+
+	// KeyName is the name of the Transit key sealing the keys.
+	KeyName string `mapstructure:"key_name"`
+}
+
+// RequestConfig defines the key issuing service new keys are requested from.
+type RequestConfig struct {
+	confighttp.ClientConfig `mapstructure:",squash"`
+}
+
+// Validate checks if the extension configuration is valid.
+func (cfg *Config) Validate() error {
+	var errs []error
+	switch cfg.Algorithm {
+	case AlgorithmEd25519, AlgorithmECDSAP256, AlgorithmECDSAP384:
+	default:
+		errs = append(errs, fmt.Errorf("algorithm %q is not supported, must be one of %q, %q or %q",
+			cfg.Algorithm, AlgorithmEd25519, AlgorithmECDSAP256, AlgorithmECDSAP384))
+	}
+	if cfg.Interval <= 0 {
+		errs = append(errs, errors.New("interval must be positive"))
+	}
+	if cfg.GracePeriod < 0 {
+		errs = append(errs, errors.New("grace_period must not be negative"))
+	}
+	if cfg.GracePeriod >= cfg.Interval && cfg.Interval > 0 {
+		errs = append(errs, errors.New("grace_period must be shorter than interval"))
+	}
+	switch {
+	case cfg.Storage != nil && !cfg.Seal.HasValue():
+		errs = append(errs, errors.New("storage requires seal, private keys are not stored unencrypted"))
+	case cfg.Storage == nil && cfg.Seal.HasValue():
+		errs = append(errs, errors.New("seal requires storage"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks if the Transit configuration is valid.
+func (cfg *TransitConfig) Validate() error {
+	var errs []error
+	if cfg.Endpoint == "" {
+		errs = append(errs, errors.New("seal::endpoint must be specified"))
+	}
+	if cfg.MountPath == "" {
+		errs = append(errs, errors.New("seal::mount_path must be specified"))
+	}
+	if cfg.KeyName == "" {
+		errs = append(errs, errors.New("seal::key_name must be specified"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks if the key issuing service configuration is valid.
+func (cfg *RequestConfig) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("request::endpoint must be specified")
+	}
+	return nil
+}
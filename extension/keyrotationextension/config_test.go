@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package keyrotationextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	storageID := component.MustNewID("file_storage")
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr string
+	}{
+		{
+			id: component.NewID(metadata.Type),
+			expected: func() *Config {
+				return createDefaultConfig().(*Config)
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "custom"),
+			expected: func() *Config {
+				clientConfig := confighttp.NewDefaultClientConfig()
+				clientConfig.Endpoint = "https://keys.example.com/issue"
+				sealConfig := confighttp.NewDefaultClientConfig()
+				sealConfig.Endpoint = "https://openbao.example.com:8200"
+				return &Config{
+					Algorithm:   AlgorithmECDSAP256,
+					Interval:    7 * 24 * time.Hour,
+					GracePeriod: 24 * time.Hour,
+					Request:     configoptional.Some(RequestConfig{ClientConfig: clientConfig}),
+					Storage:     &storageID,
+					Seal: configoptional.Some(TransitConfig{
+						ClientConfig: sealConfig,
+						Token:        "s.token",
+						Namespace:    "ns1",
+						MountPath:    "transit",
+						KeyName:      "signing-keys",
+					}),
+				}
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid"),
+			expectedErr: `algorithm "rsa" is not supported, must be one of "ed25519", "ecdsa_p256" or "ecdsa_p384"` + "\n" + "grace_period must be shorter than interval",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "noendpoint"),
+			expectedErr: "request::endpoint must be specified",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "unsealed"),
+			expectedErr: "storage requires seal, private keys are not stored unencrypted",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "nosealkey"),
+			expectedErr: "seal::key_name must be specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package keyrotationextension rotates signing keys on a schedule and hands
+// them out to the components subscribing to it.
+package keyrotationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension"
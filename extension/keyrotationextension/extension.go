@@ -0,0 +1,310 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package keyrotationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension"
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/transit"
+)
+
+const (
+	storageKey = "keys"
+
+	// Rotation events, see recordEvent.
+	eventActivated = "activated"
+	eventRetired   = "retired"
+	eventFailed    = "failed"
+
+	// retryDelay is the delay before retrying a failed rotation.
+	retryDelay = time.Minute
+)
+
+var _ KeyProvider = (*keyRotationExtension)(nil)
+
+type keyRotationExtension struct {
+	config    *Config
+	set       extension.Settings
+	logger    *zap.Logger
+	telemetry *metadata.TelemetryBuilder
+	now       func() time.Time
+
+	source        keySource
+	storageClient storage.Client
+	transit       *transit.Client
+
+	// rotateMu serializes rotations, mu guards the key set and subscribers.
+	rotateMu sync.Mutex
+	mu       sync.Mutex
+	keys     KeySet
+	hasKey   bool
+	// generation is incremented on every change of keys.
+	generation  uint64
+	subscribers map[int]*subscriber
+	nextSubID   int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newKeyRotationExtension(cfg *Config, set extension.Settings) (*keyRotationExtension, error) {
+	tel, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+	return &keyRotationExtension{
+		config:      cfg,
+		set:         set,
+		logger:      set.Logger,
+		telemetry:   tel,
+		now:         time.Now,
+		source:      generateKey(cfg.Algorithm),
+		subscribers: map[int]*subscriber{},
+	}, nil
+}
+
+func (e *keyRotationExtension) Start(ctx context.Context, host component.Host) error {
+	if reqCfg := e.config.Request.Get(); reqCfg != nil {
+		client, err := reqCfg.ToClient(ctx, host.GetExtensions(), e.set.TelemetrySettings)
+		if err != nil {
+			return err
+		}
+		e.source = requestKey(client, reqCfg.Endpoint, e.config.Algorithm)
+	}
+	if sealCfg := e.config.Seal.Get(); sealCfg != nil {
+		client, err := sealCfg.ToClient(ctx, host.GetExtensions(), e.set.TelemetrySettings)
+		if err != nil {
+			return err
+		}
+		e.transit = transit.NewClient(client, transit.Settings{
+			Endpoint:  sealCfg.Endpoint,
+			Token:     string(sealCfg.Token),
+			Namespace: sealCfg.Namespace,
+			MountPath: sealCfg.MountPath,
+			KeyName:   sealCfg.KeyName,
+		})
+	}
+	if e.config.Storage != nil {
+		client, err := getStorageClient(ctx, host, e.config.Storage, e.set.ID)
+		if err != nil {
+			return err
+		}
+		e.storageClient = client
+		if err := e.loadKeys(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Without a key, the subscribing components cannot work: the first
+	// rotation must succeed.
+	if _, err := e.rotateIfDue(ctx); err != nil {
+		return fmt.Errorf("failed to obtain a signing key: %w", err)
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	go e.rotationLoop(loopCtx)
+	return nil
+}
+
+func (e *keyRotationExtension) Shutdown(ctx context.Context) error {
+	if e.cancel != nil {
+		e.cancel()
+		<-e.done
+	}
+	e.telemetry.Shutdown()
+	if e.storageClient != nil {
+		return e.storageClient.Close(ctx)
+	}
+	return nil
+}
+
+func (e *keyRotationExtension) Keys() KeySet {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.keys
+}
+
+func (e *keyRotationExtension) Subscribe(fn func(KeySet)) func() {
+	sub := &subscriber{fn: fn}
+	e.mu.Lock()
+	id := e.nextSubID
+	e.nextSubID++
+	e.subscribers[id] = sub
+	keys, generation := e.keys, e.generation
+	e.mu.Unlock()
+
+	// No lock is held while fn runs, so that it may call back into the
+	// extension.
+	sub.notify(keys, generation)
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.subscribers, id)
+	}
+}
+
+// subscriber is a function subscribed to key set changes.
+type subscriber struct {
+	fn func(KeySet)
+
+	mu         sync.Mutex
+	notified   bool
+	generation uint64
+}
+
+// notify calls fn with keys unless it was already called with the same or a
+// newer key set, which happens when a rotation races with Subscribe.
+func (s *subscriber) notify(keys KeySet, generation uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.notified && generation <= s.generation {
+		return
+	}
+	s.notified = true
+	s.generation = generation
+	s.fn(keys)
+}
+
+func (e *keyRotationExtension) rotationLoop(ctx context.Context) {
+	defer close(e.done)
+	timer := time.NewTimer(e.nextEvent(e.Keys(), e.now()))
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			delay, err := e.rotateIfDue(ctx)
+			if err != nil {
+				e.logger.Warn("key rotation failed, retrying", zap.Duration("retry_in", retryDelay), zap.Error(err))
+				delay = retryDelay
+			}
+			timer.Reset(delay)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// nextEvent returns the time until the next rotation or retirement.
+func (e *keyRotationExtension) nextEvent(keys KeySet, now time.Time) time.Duration {
+	next := keys.Active.CreatedAt.Add(e.config.Interval)
+	for _, k := range keys.Previous {
+		if k.RetireAt.Before(next) {
+			next = k.RetireAt
+		}
+	}
+	return max(next.Sub(now), 0)
+}
+
+// rotateIfDue retires the previous keys whose grace period ended and
+// replaces the active key when the rotation interval elapsed. Subscribers are
+// notified when the key set changed. It returns the time until the next
+// event.
+func (e *keyRotationExtension) rotateIfDue(ctx context.Context) (time.Duration, error) {
+	e.rotateMu.Lock()
+	defer e.rotateMu.Unlock()
+
+	e.mu.Lock()
+	keys := KeySet{Active: e.keys.Active, Previous: slices.Clone(e.keys.Previous)}
+	hasKey := e.hasKey
+	e.mu.Unlock()
+
+	now := e.now()
+	changed := false
+	var previous []Key
+	for _, k := range keys.Previous {
+		if now.Before(k.RetireAt) {
+			previous = append(previous, k)
+			continue
+		}
+		e.recordEvent(ctx, eventRetired, k)
+		changed = true
+	}
+	keys.Previous = previous
+
+	var err error
+	if !hasKey || !now.Before(keys.Active.CreatedAt.Add(e.config.Interval)) {
+		var key Key
+		if key, err = e.newKey(ctx, now); err != nil {
+			e.telemetry.ExtensionKeyRotationEvents.Add(ctx, 1, metric.WithAttributes(attribute.String("event", eventFailed)))
+			e.logger.Error("Key rotation event", zap.String("event", eventFailed), zap.Error(err))
+		} else {
+			if hasKey {
+				old := keys.Active
+				old.RetireAt = now.Add(e.config.GracePeriod)
+				if e.config.GracePeriod > 0 {
+					keys.Previous = slices.Insert(keys.Previous, 0, old)
+				} else {
+					e.recordEvent(ctx, eventRetired, old)
+				}
+			}
+			keys.Active = key
+			hasKey = true
+			e.recordEvent(ctx, eventActivated, key)
+			changed = true
+		}
+	}
+
+	if changed {
+		e.mu.Lock()
+		e.keys = keys
+		e.hasKey = hasKey
+		e.generation++
+		generation := e.generation
+		subscribers := make([]*subscriber, 0, len(e.subscribers))
+		for _, sub := range e.subscribers {
+			subscribers = append(subscribers, sub)
+		}
+		e.mu.Unlock()
+
+		e.saveKeys(ctx, keys)
+		for _, sub := range subscribers {
+			sub.notify(keys, generation)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return e.nextEvent(keys, now), nil
+}
+
+func (e *keyRotationExtension) newKey(ctx context.Context, now time.Time) (Key, error) {
+	signer, err := e.source(ctx)
+	if err != nil {
+		return Key{}, err
+	}
+	id, err := keyID(signer)
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{ID: id, Signer: signer, CreatedAt: now}, nil
+}
+
+// recordEvent records a rotation event in the collector's own logs and metrics.
+func (e *keyRotationExtension) recordEvent(ctx context.Context, event string, key Key) {
+	e.telemetry.ExtensionKeyRotationEvents.Add(ctx, 1, metric.WithAttributes(attribute.String("event", event)))
+	fields := []zap.Field{
+		zap.String("event", event),
+		zap.String("key_id", key.ID),
+		zap.String("algorithm", algorithmOf(key.Signer)),
+		zap.Time("created_at", key.CreatedAt),
+	}
+	if !key.RetireAt.IsZero() {
+		fields = append(fields, zap.Time("retire_at", key.RetireAt))
+	}
+	e.logger.Info("Key rotation event", fields...)
+}
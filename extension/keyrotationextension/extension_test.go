@@ -0,0 +1,306 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package keyrotationextension
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension/internal/metadatatest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+var startTime = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+func newTestExtension(t *testing.T, cfg *Config, now *time.Time) (*keyRotationExtension, *componenttest.Telemetry) {
+	t.Helper()
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	ext, err := newKeyRotationExtension(cfg, metadatatest.NewSettings(tel))
+	require.NoError(t, err)
+	ext.now = func() time.Time { return *now }
+	return ext, tel
+}
+
+func keyIDs(keys []Key) []string {
+	ids := make([]string, len(keys))
+	for i, k := range keys {
+		ids[i] = k.ID
+	}
+	return ids
+}
+
+func TestRotation(t *testing.T) {
+	now := startTime
+	cfg := createDefaultConfig().(*Config)
+	ext, tel := newTestExtension(t, cfg, &now)
+	require.NoError(t, ext.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+
+	var notified []KeySet
+	unsubscribe := ext.Subscribe(func(keys KeySet) { notified = append(notified, keys) })
+	first := ext.Keys().Active
+	require.Len(t, notified, 1)
+	assert.Equal(t, first.ID, notified[0].Active.ID)
+	assert.Equal(t, startTime, first.CreatedAt)
+	assert.Equal(t, AlgorithmEd25519, algorithmOf(first.Signer))
+	id, err := keyID(first.Signer)
+	require.NoError(t, err)
+	assert.Equal(t, id, first.ID)
+
+	// Nothing is due before the interval elapsed.
+	now = startTime.Add(23 * time.Hour)
+	next, err := ext.rotateIfDue(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, next)
+	assert.Len(t, notified, 1)
+
+	// The replaced key stays available during the grace period.
+	now = startTime.Add(24 * time.Hour)
+	next, err = ext.rotateIfDue(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, next)
+	require.Len(t, notified, 2)
+	second := notified[1].Active
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, []string{first.ID}, keyIDs(notified[1].Previous))
+	assert.Equal(t, now.Add(time.Hour), notified[1].Previous[0].RetireAt)
+
+	now = now.Add(time.Hour)
+	next, err = ext.rotateIfDue(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 23*time.Hour, next)
+	require.Len(t, notified, 3)
+	assert.Equal(t, second.ID, notified[2].Active.ID)
+	assert.Empty(t, notified[2].Previous)
+
+	unsubscribe()
+	now = now.Add(23 * time.Hour)
+	_, err = ext.rotateIfDue(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, notified, 3)
+	assert.NotEqual(t, second.ID, ext.Keys().Active.ID)
+
+	metadatatest.AssertEqualExtensionKeyRotationEvents(t, tel, []metricdata.DataPoint[int64]{
+		{Attributes: attribute.NewSet(attribute.String("event", eventActivated)), Value: 3},
+		{Attributes: attribute.NewSet(attribute.String("event", eventRetired)), Value: 1},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestRotationWithoutGracePeriod(t *testing.T) {
+	now := startTime
+	cfg := createDefaultConfig().(*Config)
+	cfg.Algorithm = AlgorithmECDSAP384
+	cfg.GracePeriod = 0
+	ext, _ := newTestExtension(t, cfg, &now)
+	require.NoError(t, ext.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+	first := ext.Keys().Active
+	assert.Equal(t, AlgorithmECDSAP384, algorithmOf(first.Signer))
+
+	now = now.Add(cfg.Interval)
+	_, err := ext.rotateIfDue(t.Context())
+	require.NoError(t, err)
+	keys := ext.Keys()
+	assert.NotEqual(t, first.ID, keys.Active.ID)
+	assert.Empty(t, keys.Previous)
+}
+
+// newTransitServer starts a fake Transit engine whose ciphertexts are the
+// base64 plaintext behind a "vault:v1:" prefix.
+func newTransitServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		var req map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/keys":
+			_, _ = w.Write([]byte(`{"data":{"ciphertext":"vault:v1:` + req["plaintext"] + `"}}`))
+		case "/v1/transit/decrypt/keys":
+			_, _ = w.Write([]byte(`{"data":{"plaintext":"` + strings.TrimPrefix(req["ciphertext"], "vault:v1:") + `"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func storageConfig(t *testing.T) *Config {
+	storageID := storagetest.NewStorageID("test")
+	clientConfig := confighttp.NewDefaultClientConfig()
+	clientConfig.Endpoint = newTransitServer(t).URL
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = &storageID
+	cfg.Seal = configoptional.Some(TransitConfig{
+		ClientConfig: clientConfig,
+		Token:        "s.token",
+		MountPath:    "transit",
+		KeyName:      "keys",
+	})
+	return cfg
+}
+
+func TestKeysPersistAcrossRestarts(t *testing.T) {
+	now := startTime
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := storageConfig(t)
+
+	ext, _ := newTestExtension(t, cfg, &now)
+	require.NoError(t, ext.Start(t.Context(), host))
+	now = now.Add(cfg.Interval)
+	_, err := ext.rotateIfDue(t.Context())
+	require.NoError(t, err)
+	before := ext.Keys()
+	require.NoError(t, ext.Shutdown(t.Context()))
+
+	// Only the sealed key set is written to storage.
+	client, err := host.GetExtensions()[*cfg.Storage].(storage.Extension).GetClient(t.Context(), component.KindExtension, ext.set.ID, "")
+	require.NoError(t, err)
+	sealed, err := client.Get(t.Context(), storageKey)
+	require.NoError(t, err)
+	require.NoError(t, client.Close(t.Context()))
+	assert.True(t, strings.HasPrefix(string(sealed), "vault:v1:"))
+
+	ext, _ = newTestExtension(t, cfg, &now)
+	require.NoError(t, ext.Start(t.Context(), host))
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+	after := ext.Keys()
+	assert.Equal(t, before.Active.ID, after.Active.ID)
+	assert.True(t, before.Active.CreatedAt.Equal(after.Active.CreatedAt))
+	assert.Equal(t, keyIDs(before.Previous), keyIDs(after.Previous))
+	assert.True(t, before.Previous[0].RetireAt.Equal(after.Previous[0].RetireAt))
+}
+
+func TestUnsealFailure(t *testing.T) {
+	now := startTime
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := storageConfig(t)
+
+	ext, _ := newTestExtension(t, cfg, &now)
+	require.NoError(t, ext.Start(t.Context(), host))
+	first := ext.Keys().Active
+	require.NoError(t, ext.Shutdown(t.Context()))
+
+	// Keys that cannot be unsealed are not replaced: the extension does not
+	// start, and the stored keys are loaded once Transit unseals them again.
+	cfg.Seal.Get().Token = "s.other"
+	ext, _ = newTestExtension(t, cfg, &now)
+	assert.ErrorContains(t, ext.Start(t.Context(), host), "failed to unseal keys from storage: transit returned 403 Forbidden")
+	require.NoError(t, ext.Shutdown(t.Context()))
+
+	cfg.Seal.Get().Token = "s.token"
+	ext, _ = newTestExtension(t, cfg, &now)
+	require.NoError(t, ext.Start(t.Context(), host))
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+	assert.Equal(t, first.ID, ext.Keys().Active.ID)
+}
+
+func TestSubscribeFromCallback(t *testing.T) {
+	now := startTime
+	ext, _ := newTestExtension(t, createDefaultConfig().(*Config), &now)
+	require.NoError(t, ext.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+
+	var nested []KeySet
+	var once sync.Once
+	ext.Subscribe(func(KeySet) {
+		once.Do(func() {
+			ext.Subscribe(func(keys KeySet) { nested = append(nested, keys) })
+		})
+	})
+	require.Len(t, nested, 1)
+
+	now = now.Add(ext.config.Interval)
+	_, err := ext.rotateIfDue(t.Context())
+	require.NoError(t, err)
+	require.Len(t, nested, 2)
+	assert.Equal(t, ext.Keys().Active.ID, nested[1].Active.ID)
+}
+
+func TestSubscriberSkipsStaleKeys(t *testing.T) {
+	var notified []string
+	sub := &subscriber{fn: func(keys KeySet) { notified = append(notified, keys.Active.ID) }}
+	sub.notify(KeySet{Active: Key{ID: "b"}}, 2)
+	sub.notify(KeySet{Active: Key{ID: "a"}}, 1)
+	sub.notify(KeySet{Active: Key{ID: "c"}}, 3)
+	assert.Equal(t, []string{"b", "c"}, notified)
+}
+
+func TestRequestKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req keyRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Algorithm != AlgorithmECDSAP256 {
+			http.Error(w, "unsupported algorithm", http.StatusBadRequest)
+			return
+		}
+		_ = pem.Encode(w, &pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}))
+	defer srv.Close()
+
+	clientConfig := confighttp.NewDefaultClientConfig()
+	clientConfig.Endpoint = srv.URL
+	now := startTime
+	cfg := createDefaultConfig().(*Config)
+	cfg.Algorithm = AlgorithmECDSAP256
+	cfg.Request = configoptional.Some(RequestConfig{ClientConfig: clientConfig})
+	ext, _ := newTestExtension(t, cfg, &now)
+	require.NoError(t, ext.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+	assert.True(t, key.Equal(ext.Keys().Active.Signer))
+
+	// The returned key must match the configured algorithm.
+	cfg.Algorithm = AlgorithmECDSAP384
+	failing, tel := newTestExtension(t, cfg, &now)
+	err = failing.Start(t.Context(), componenttest.NewNopHost())
+	assert.EqualError(t, err, "failed to obtain a signing key: key request failed with status 400: unsupported algorithm")
+	metadatatest.AssertEqualExtensionKeyRotationEvents(t, tel, []metricdata.DataPoint[int64]{
+		{Attributes: attribute.NewSet(attribute.String("event", eventFailed)), Value: 1},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestStorageNotFound(t *testing.T) {
+	now := startTime
+	storageID := component.MustNewID("file_storage")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = &storageID
+	ext, err := NewFactory().Create(t.Context(), extensiontest.NewNopSettings(metadata.Type), cfg)
+	require.NoError(t, err)
+	ext.(*keyRotationExtension).now = func() time.Time { return now }
+	assert.EqualError(t, ext.Start(t.Context(), componenttest.NewNopHost()), `storage extension "file_storage" not found`)
+}
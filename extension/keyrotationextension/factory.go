@@ -0,0 +1,45 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package keyrotationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/extension"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension/internal/metadata"
+)
+
+// NewFactory creates a factory for the key rotation extension.
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		createExtension,
+		metadata.ExtensionStability,
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Algorithm:   AlgorithmEd25519,
+		Interval:    24 * time.Hour,
+		GracePeriod: time.Hour,
+		Request: configoptional.Default(RequestConfig{
+			ClientConfig: confighttp.NewDefaultClientConfig(),
+		}),
+		Seal: configoptional.Default(TransitConfig{
+			ClientConfig: confighttp.NewDefaultClientConfig(),
+			MountPath:    "transit",
+		}),
+	}
+}
+
+func createExtension(_ context.Context, set extension.Settings, cfg component.Config) (extension.Extension, error) {
+	return newKeyRotationExtension(cfg.(*Config), set)
+}
@@ -0,0 +1,165 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package keyrotationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension"
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// keySource obtains new signing keys.
+type keySource func(ctx context.Context) (crypto.Signer, error)
+
+func generateKey(algorithm string) keySource {
+	return func(context.Context) (crypto.Signer, error) {
+		switch algorithm {
+		case AlgorithmEd25519:
+			_, key, err := ed25519.GenerateKey(rand.Reader)
+			return key, err
+		case AlgorithmECDSAP256:
+			return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		case AlgorithmECDSAP384:
+			return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		}
+		return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+}
+
+type keyRequest struct {
+	Algorithm string `json:"algorithm"`
+}
+
+// requestKey asks a key issuing service for a new key. The service is sent
+// the algorithm as JSON and answers with a PEM encoded PKCS #8 private key.
+func requestKey(client *http.Client, endpoint, algorithm string) keySource {
+	return func(ctx context.Context) (crypto.Signer, error) {
+		body, err := json.Marshal(keyRequest{Algorithm: algorithm})
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("key request failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil || block.Type != "PRIVATE KEY" {
+			return nil, errors.New("key issuing service did not return a PEM encoded PRIVATE KEY")
+		}
+		signer, err := parsePrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if got := algorithmOf(signer); got != algorithm {
+			return nil, fmt.Errorf("key issuing service returned a %q key, expected %q", got, algorithm)
+		}
+		return signer, nil
+	}
+}
+
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+func algorithmOf(signer crypto.Signer) string {
+	switch k := signer.(type) {
+	case ed25519.PrivateKey:
+		return AlgorithmEd25519
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return AlgorithmECDSAP256
+		case elliptic.P384():
+			return AlgorithmECDSAP384
+		}
+	}
+	return fmt.Sprintf("%T", signer)
+}
+
+// keyID returns the first 16 bytes of the SHA-256 hash of the PKIX public
+// key of signer, hex encoded.
+func keyID(signer crypto.Signer) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// storedKey is the representation of a key in storage.
+type storedKey struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	RetireAt   time.Time `json:"retire_at,omitzero"`
+	PrivateKey []byte    `json:"private_key"`
+}
+
+// marshalKeySet encodes a key set for storage, active key first.
+func marshalKeySet(set KeySet) ([]byte, error) {
+	keys := make([]storedKey, 0, 1+len(set.Previous))
+	for _, k := range append([]Key{set.Active}, set.Previous...) {
+		der, err := x509.MarshalPKCS8PrivateKey(k.Signer)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, storedKey{ID: k.ID, CreatedAt: k.CreatedAt, RetireAt: k.RetireAt, PrivateKey: der})
+	}
+	return json.Marshal(keys)
+}
+
+func unmarshalKeySet(data []byte) (KeySet, error) {
+	var stored []storedKey
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return KeySet{}, err
+	}
+	if len(stored) == 0 {
+		return KeySet{}, errors.New("no keys stored")
+	}
+	keys := make([]Key, len(stored))
+	for i, s := range stored {
+		signer, err := parsePrivateKey(s.PrivateKey)
+		if err != nil {
+			return KeySet{}, err
+		}
+		keys[i] = Key{ID: s.ID, Signer: signer, CreatedAt: s.CreatedAt, RetireAt: s.RetireAt}
+	}
+	return KeySet{Active: keys[0], Previous: keys[1:]}, nil
+}
@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package keyrotationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension"
+
+import (
+	"crypto"
+	"time"
+)
+
+// Key is a signing key handed out by the extension.
+type Key struct {
+	// ID identifies the key: the first 16 bytes of the SHA-256 hash of its
+	// PKIX public key, hex encoded.
+	ID string
+	// Signer signs with the private key.
+	Signer crypto.Signer
+	// CreatedAt is when the key was generated or obtained.
+	CreatedAt time.Time
+	// RetireAt is when a replaced key stops being offered. It is zero for
+	// the active key.
+	RetireAt time.Time
+}
+
+// KeySet is the set of keys offered at a point in time.
+type KeySet struct {
+	// Active is the key new signatures must be made with.
+	Active Key
+	// Previous are the replaced keys still in their grace period, most recent
+	// first. Signatures made with them remain valid until they are retired.
+	Previous []Key
+}
+
+// KeyProvider is implemented by the extension. Components find it among the
+// host extensions and subscribe to key rotations.
+type KeyProvider interface {
+	// Keys returns the current key set.
+	Keys() KeySet
+	// Subscribe registers fn to be called with the current key set right
+	// away and with the new key set after every change. The returned
+	// function cancels the subscription.
+	Subscribe(fn func(KeySet)) (unsubscribe func())
+}
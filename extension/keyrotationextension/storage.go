@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package keyrotationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/keyrotationextension"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.uber.org/zap"
+)
+
+// getStorageClient resolves a storage.Client for the extension.
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", storageID)
+	}
+
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", storageID)
+	}
+
+	return storageExt.GetClient(ctx, component.KindExtension, componentID, "")
+}
+
+// loadKeys restores the key set from storage, if one was stored. A key set
+// that cannot be read or unsealed, for example while Transit is unavailable,
+// is an error: replacing it would lose the keys still needed to verify or
+// decrypt what they protected.
+func (e *keyRotationExtension) loadKeys(ctx context.Context) error {
+	sealed, err := e.storageClient.Get(ctx, storageKey)
+	if err != nil {
+		return fmt.Errorf("failed to read keys from storage: %w", err)
+	}
+	if len(sealed) == 0 {
+		return nil
+	}
+	data, err := e.transit.Decrypt(ctx, string(sealed))
+	if err != nil {
+		return fmt.Errorf("failed to unseal keys from storage: %w", err)
+	}
+	keys, err := unmarshalKeySet(data)
+	if err != nil {
+		return fmt.Errorf("failed to load keys from storage: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.keys = keys
+	e.hasKey = true
+	e.logger.Info("loaded keys from storage", zap.String("active_key_id", keys.Active.ID), zap.Int("previous_keys", len(keys.Previous)))
+	return nil
+}
+
+// saveKeys seals the key set with Transit and writes it to storage. Failures
+// are logged: the keys in memory remain valid, they would only be replaced
+// after a restart.
+func (e *keyRotationExtension) saveKeys(ctx context.Context, keys KeySet) {
+	if e.storageClient == nil {
+		return
+	}
+	data, err := marshalKeySet(keys)
+	var sealed string
+	if err == nil {
+		sealed, err = e.transit.Encrypt(ctx, data)
+	}
+	if err == nil {
+		err = e.storageClient.Set(ctx, storageKey, []byte(sealed))
+	}
+	if err != nil {
+		e.logger.Warn("failed to write keys to storage", zap.Error(err))
+	}
+}
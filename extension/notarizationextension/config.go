@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package notarizationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+)
+
+// Config defines configuration for the notarization extension.
+type Config struct {
+	// Storage is the ID of the storage extension the proofs are kept in.
+	Storage component.ID `mapstructure:"storage"`
+
+	// Interval at which the hashes submitted since the previous batch are
+	// notarized.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// MaxBatchSize is the number of pending hashes that triggers notarizing a
+	// batch before the interval elapsed.
+	MaxBatchSize int `mapstructure:"max_batch_size"`
+
+	// MaxPendingHashes bounds the number of hashes waiting to be notarized.
+	// Once reached, Submit fails until the backends catch up.
+	MaxPendingHashes int `mapstructure:"max_pending_hashes"`
+
+	// TSA obtains RFC 3161 timestamp tokens for the batch roots.
+	TSA configoptional.Optional[TSAConfig] `mapstructure:"tsa"`
+
+	// Rekor records the batch roots in a Rekor transparency log.
+	Rekor configoptional.Optional[RekorConfig] `mapstructure:"rekor"`
+
+	// Server serves the proofs over HTTP. Optional.
+	Server configoptional.Optional[confighttp.ServerConfig] `mapstructure:"server"`
+}
+
+// TSAConfig defines the RFC 3161 time-stamp authority batch roots are sent to.
+type TSAConfig struct {
+	confighttp.ClientConfig `mapstructure:",squash"`
+}
+
+// RekorConfig defines the Rekor transparency log batch roots are recorded in.
+type RekorConfig struct {
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	// PrivateKeyFile is the path of the PEM encoded ECDSA private key signing
+	// the roots, which Rekor requires for every entry.
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+}
+
+// Validate checks the Config for invalid values.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.Storage.Type().String() == "" {
+		errs = append(errs, errors.New("storage must be specified"))
+	}
+	if cfg.Interval <= 0 {
+		errs = append(errs, fmt.Errorf("interval must be positive, got %s", cfg.Interval))
+	}
+	if cfg.MaxBatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("max_batch_size must be positive, got %d", cfg.MaxBatchSize))
+	}
+	if cfg.MaxPendingHashes <= 0 {
+		errs = append(errs, fmt.Errorf("max_pending_hashes must be positive, got %d", cfg.MaxPendingHashes))
+	}
+	if !cfg.TSA.HasValue() && !cfg.Rekor.HasValue() {
+		errs = append(errs, errors.New("at least one of tsa or rekor must be configured"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the TSAConfig for invalid values.
+func (cfg *TSAConfig) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must be specified")
+	}
+	return nil
+}
+
+// Validate checks the RekorConfig for invalid values.
+func (cfg *RekorConfig) Validate() error {
+	var errs []error
+	if cfg.Endpoint == "" {
+		errs = append(errs, errors.New("endpoint must be specified"))
+	}
+	if cfg.PrivateKeyFile == "" {
+		errs = append(errs, errors.New("private_key_file must be specified"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package notarizationextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/confignet"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr []string
+	}{
+		{
+			id: component.NewIDWithName(metadata.Type, "all"),
+			expected: func() *Config {
+				tsaClient := confighttp.NewDefaultClientConfig()
+				tsaClient.Endpoint = "https://freetsa.org/tsr"
+				rekorClient := confighttp.NewDefaultClientConfig()
+				rekorClient.Endpoint = "https://rekor.sigstore.dev"
+				serverConfig := confighttp.NewDefaultServerConfig()
+				serverConfig.NetAddr = confignet.AddrConfig{
+					Transport: confignet.TransportTypeTCP,
+					Endpoint:  "localhost:55690",
+				}
+				return &Config{
+					Storage:          component.MustNewID("file_storage"),
+					Interval:         30 * time.Second,
+					MaxBatchSize:     500,
+					MaxPendingHashes: 50000,
+					TSA:              configoptional.Some(TSAConfig{ClientConfig: tsaClient}),
+					Rekor: configoptional.Some(RekorConfig{
+						ClientConfig:   rekorClient,
+						PrivateKeyFile: "/etc/otelcol/rekor.key",
+					}),
+					Server: configoptional.Some(serverConfig),
+				}
+			},
+		},
+		{
+			id:          component.NewID(metadata.Type),
+			expectedErr: []string{"storage must be specified", "at least one of tsa or rekor must be configured"},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "nobackend"),
+			expectedErr: []string{"at least one of tsa or rekor must be configured"},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "invalid"),
+			expectedErr: []string{
+				"interval must be positive, got 0s",
+				"max_batch_size must be positive, got 0",
+				"max_pending_hashes must be positive, got -1",
+				"tsa: endpoint must be specified",
+				"rekor: endpoint must be specified",
+				"private_key_file must be specified",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if len(tt.expectedErr) > 0 {
+				err := xconfmap.Validate(cfg)
+				for _, expected := range tt.expectedErr {
+					assert.ErrorContains(t, err, expected)
+				}
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package notarizationextension batches hashes submitted by other components,
+// notarizes the Merkle root of every batch with external services and keeps
+// the resulting proofs in a storage extension.
+package notarizationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension"
@@ -0,0 +1,401 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package notarizationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension"
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/merkle"
+)
+
+const (
+	outcomeNotarized = "notarized"
+	outcomeFailed    = "failed"
+)
+
+// ErrProofNotFound is returned by Notary.Proof for hashes that were not
+// notarized, or not yet.
+var ErrProofNotFound = errors.New("no proof found for hash")
+
+// errPendingFull is returned by Submit while max_pending_hashes hashes are
+// waiting to be notarized.
+var errPendingFull = errors.New("max_pending_hashes reached, waiting for the notarization backends to catch up")
+
+// Notary is implemented by the notarization extension. Components look it up
+// among the host extensions to submit the hashes of the data they process and
+// to retrieve their proofs.
+type Notary interface {
+	// Submit queues a SHA-256 hash for notarization with the next batch.
+	Submit(ctx context.Context, hash []byte) error
+	// Proof returns the proof of a notarized hash, or ErrProofNotFound.
+	Proof(ctx context.Context, hash []byte) (*Proof, error)
+}
+
+// Notarization is the evidence obtained from an external service for the
+// root of a batch.
+type Notarization struct {
+	// Backend is the service the evidence was obtained from: tsa or rekor.
+	Backend string `json:"backend"`
+	// Time is the time attested by the service.
+	Time time.Time `json:"time"`
+	// Evidence is the DER encoded RFC 3161 timestamp token for tsa, and the
+	// JSON log entry returned by Rekor for rekor.
+	Evidence []byte `json:"evidence"`
+}
+
+// Proof proves that a hash was notarized: the audit path links the hash to
+// the Merkle root of its batch, which the notarizations attest.
+type Proof struct {
+	// Hash is the hex encoded notarized hash.
+	Hash string `json:"hash"`
+	// Root is the hex encoded root of the RFC 6962 Merkle tree over the
+	// hashes of the batch.
+	Root string `json:"root"`
+	// LeafIndex is the position of the hash in the batch.
+	LeafIndex int `json:"leaf_index"`
+	// TreeSize is the number of hashes in the batch.
+	TreeSize int `json:"tree_size"`
+	// AuditPath is the hex encoded RFC 6962 inclusion proof of the hash.
+	AuditPath     []string       `json:"audit_path"`
+	Notarizations []Notarization `json:"notarizations"`
+}
+
+// Verify checks that the audit path links the hash to the root. The
+// notarizations of the root are verified with the tooling of their services.
+func (p *Proof) Verify() error {
+	hash, err := hex.DecodeString(p.Hash)
+	if err != nil {
+		return fmt.Errorf("invalid hash: %w", err)
+	}
+	root, err := hex.DecodeString(p.Root)
+	if err != nil {
+		return fmt.Errorf("invalid root: %w", err)
+	}
+	path := make([][]byte, len(p.AuditPath))
+	for i, node := range p.AuditPath {
+		if path[i], err = hex.DecodeString(node); err != nil {
+			return fmt.Errorf("invalid audit path: %w", err)
+		}
+	}
+	computed, ok := merkle.RootFromAuditPath(hash, p.LeafIndex, p.TreeSize, path)
+	if !ok || !bytes.Equal(computed, root) {
+		return errors.New("audit path does not lead to the root")
+	}
+	return nil
+}
+
+// notarizer obtains evidence for batch roots from an external service.
+type notarizer interface {
+	name() string
+	notarize(ctx context.Context, root []byte) (Notarization, error)
+}
+
+type notaryExtension struct {
+	config    *Config
+	set       extension.Settings
+	logger    *zap.Logger
+	telemetry *metadata.TelemetryBuilder
+
+	notarizers    []notarizer
+	storageClient storage.Client
+	server        *http.Server
+	serverDone    chan struct{}
+
+	// mu guards the pending hashes and their sequence numbers in storage:
+	// keys pendingStart to pendingNext-1 hold the hashes in pending.
+	mu           sync.Mutex
+	pending      [][]byte
+	pendingStart uint64
+	pendingNext  uint64
+	trigger      chan struct{}
+
+	// notarizeMu serializes notarization between the loop and shutdown.
+	notarizeMu sync.Mutex
+	cancel     context.CancelFunc
+	loopWG     sync.WaitGroup
+}
+
+var _ Notary = (*notaryExtension)(nil)
+
+func newNotaryExtension(cfg *Config, set extension.Settings) (*notaryExtension, error) {
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+	return &notaryExtension{
+		config:    cfg,
+		set:       set,
+		logger:    set.Logger,
+		telemetry: telemetryBuilder,
+		trigger:   make(chan struct{}, 1),
+	}, nil
+}
+
+func (e *notaryExtension) Start(ctx context.Context, host component.Host) error {
+	if tsaCfg := e.config.TSA.Get(); tsaCfg != nil {
+		client, err := tsaCfg.ToClient(ctx, host.GetExtensions(), e.set.TelemetrySettings)
+		if err != nil {
+			return err
+		}
+		e.notarizers = append(e.notarizers, &tsaNotarizer{client: client, cfg: tsaCfg})
+	}
+	if rekorCfg := e.config.Rekor.Get(); rekorCfg != nil {
+		client, err := rekorCfg.ToClient(ctx, host.GetExtensions(), e.set.TelemetrySettings)
+		if err != nil {
+			return err
+		}
+		rekor, err := newRekorNotarizer(client, rekorCfg)
+		if err != nil {
+			return err
+		}
+		e.notarizers = append(e.notarizers, rekor)
+	}
+
+	client, err := getStorageClient(ctx, host, e.config.Storage, e.set.ID)
+	if err != nil {
+		return err
+	}
+	e.storageClient = client
+	if err := e.loadPending(ctx); err != nil {
+		return err
+	}
+
+	if serverCfg := e.config.Server.Get(); serverCfg != nil {
+		if err := e.startServer(ctx, host, serverCfg); err != nil {
+			return err
+		}
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.loopWG.Go(func() { e.notarizeLoop(loopCtx) })
+	return nil
+}
+
+// Shutdown notarizes the hashes still pending before stopping. Hashes that
+// could not be notarized stay in storage and are notarized after a restart.
+func (e *notaryExtension) Shutdown(ctx context.Context) error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.loopWG.Wait()
+
+	var errs []error
+	if e.storageClient != nil {
+		e.notarizePending(ctx)
+		if pending := e.pendingCount(); pending > 0 {
+			e.logger.Warn("Hashes were not notarized before shutdown, they are notarized after restart", zap.Int("hashes", pending))
+		}
+		errs = append(errs, e.storageClient.Close(ctx))
+	}
+	if e.server != nil {
+		errs = append(errs, e.server.Close())
+		<-e.serverDone
+	}
+	e.telemetry.Shutdown()
+	return errors.Join(errs...)
+}
+
+// Submit queues a SHA-256 hash for notarization with the next batch. The hash
+// is written to storage before Submit returns, so that it is notarized after
+// a restart.
+func (e *notaryExtension) Submit(ctx context.Context, hash []byte) error {
+	if len(hash) != sha256.Size {
+		return fmt.Errorf("hash must be %d bytes, got %d", sha256.Size, len(hash))
+	}
+	e.mu.Lock()
+	if len(e.pending) >= e.config.MaxPendingHashes {
+		e.mu.Unlock()
+		return errPendingFull
+	}
+	if err := e.storageClient.Set(ctx, pendingKey(e.pendingNext), hash); err != nil {
+		e.mu.Unlock()
+		return fmt.Errorf("failed to write pending hash to storage: %w", err)
+	}
+	e.pendingNext++
+	e.pending = append(e.pending, bytes.Clone(hash))
+	full := len(e.pending) >= e.config.MaxBatchSize
+	e.mu.Unlock()
+	if full {
+		select {
+		case e.trigger <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (e *notaryExtension) pendingCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.pending)
+}
+
+func (e *notaryExtension) notarizeLoop(ctx context.Context) {
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-e.trigger:
+		}
+		e.notarizePending(ctx)
+	}
+}
+
+// notarizePending notarizes the pending hashes in batches of at most
+// max_batch_size hashes. A batch that fails stays pending and is retried with
+// the next one.
+func (e *notaryExtension) notarizePending(ctx context.Context) {
+	e.notarizeMu.Lock()
+	defer e.notarizeMu.Unlock()
+	for {
+		e.mu.Lock()
+		n := min(len(e.pending), e.config.MaxBatchSize)
+		leaves := e.pending[:n:n]
+		start := e.pendingStart
+		e.mu.Unlock()
+		if n == 0 {
+			return
+		}
+
+		if err := e.notarizeBatch(ctx, leaves, start); err != nil {
+			e.telemetry.ExtensionNotarizationBatches.Add(ctx, 1, metric.WithAttributeSet(attribute.NewSet(attribute.String("outcome", outcomeFailed))))
+			e.logger.Warn("Failed to notarize batch, retrying with the next one", zap.Int("hashes", n), zap.Error(err))
+			return
+		}
+		e.mu.Lock()
+		e.pending = e.pending[n:]
+		e.pendingStart += uint64(n)
+		e.mu.Unlock()
+		e.telemetry.ExtensionNotarizationBatches.Add(ctx, 1, metric.WithAttributeSet(attribute.NewSet(attribute.String("outcome", outcomeNotarized))))
+		e.telemetry.ExtensionNotarizationHashes.Add(ctx, int64(n))
+	}
+}
+
+// notarizeBatch obtains evidence for the root of the tree over leaves from
+// every backend and stores it, removing the leaves from the pending hashes
+// in storage. start is the sequence number of the first leaf.
+func (e *notaryExtension) notarizeBatch(ctx context.Context, leaves [][]byte, start uint64) error {
+	root := merkle.Root(leaves)
+	record := batchRecord{
+		Root:   hex.EncodeToString(root),
+		Leaves: make([]string, len(leaves)),
+	}
+	for i, leaf := range leaves {
+		record.Leaves[i] = hex.EncodeToString(leaf)
+	}
+	for _, n := range e.notarizers {
+		notarization, err := n.notarize(ctx, root)
+		if err != nil {
+			return fmt.Errorf("%s: %w", n.name(), err)
+		}
+		record.Notarizations = append(record.Notarizations, notarization)
+	}
+	if err := e.storeBatch(ctx, record, start, start+uint64(len(leaves))); err != nil {
+		return err
+	}
+	e.logger.Debug("Batch notarized", zap.String("root", record.Root), zap.Int("hashes", len(leaves)))
+	return nil
+}
+
+// Proof returns the proof of a notarized hash, or ErrProofNotFound.
+func (e *notaryExtension) Proof(ctx context.Context, hash []byte) (*Proof, error) {
+	record, err := e.loadBatch(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make([][]byte, len(record.Leaves))
+	index := -1
+	for i, leaf := range record.Leaves {
+		if leaves[i], err = hex.DecodeString(leaf); err != nil {
+			return nil, fmt.Errorf("invalid stored batch: %w", err)
+		}
+		if index < 0 && bytes.Equal(leaves[i], hash) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, ErrProofNotFound
+	}
+
+	path := merkle.AuditPath(index, leaves)
+	proof := &Proof{
+		Hash:          hex.EncodeToString(hash),
+		Root:          record.Root,
+		LeafIndex:     index,
+		TreeSize:      len(leaves),
+		AuditPath:     make([]string, len(path)),
+		Notarizations: record.Notarizations,
+	}
+	for i, node := range path {
+		proof.AuditPath[i] = hex.EncodeToString(node)
+	}
+	return proof, nil
+}
+
+func (e *notaryExtension) startServer(ctx context.Context, host component.Host, cfg *confighttp.ServerConfig) error {
+	ln, err := cfg.ToListener(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", cfg.NetAddr.Endpoint, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /proofs/{hash}", e.handleProof)
+	e.server, err = cfg.ToServer(ctx, host.GetExtensions(), e.set.TelemetrySettings, mux)
+	if err != nil {
+		_ = ln.Close()
+		return err
+	}
+
+	e.serverDone = make(chan struct{})
+	go func() {
+		defer close(e.serverDone)
+		if err := e.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(err))
+		}
+	}()
+	return nil
+}
+
+// handleProof serves the proof of the hex encoded hash in the path as JSON.
+func (e *notaryExtension) handleProof(w http.ResponseWriter, r *http.Request) {
+	hash, err := hex.DecodeString(r.PathValue("hash"))
+	if err != nil || len(hash) != sha256.Size {
+		http.Error(w, "hash must be a hex encoded SHA-256 hash", http.StatusBadRequest)
+		return
+	}
+	proof, err := e.Proof(r.Context(), hash)
+	switch {
+	case errors.Is(err, ErrProofNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		e.logger.Warn("Failed to load proof", zap.Error(err))
+		http.Error(w, "failed to load proof", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(proof)
+}
@@ -0,0 +1,428 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package notarizationextension
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension/internal/metadatatest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/testutil"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/merkle"
+)
+
+var genTime = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+type pkiStatusInfo struct {
+	Status int
+}
+
+type timeStampResp struct {
+	Status pkiStatusInfo
+	Token  asn1.RawValue `asn1:"optional"`
+}
+
+// newTSAServer starts a fake time-stamp authority returning unsigned tokens
+// over the requested imprint. Requests fail while failures is positive.
+func newTSAServer(t *testing.T, failures *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures != nil && failures.Add(-1) >= 0 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "application/timestamp-query", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var req timeStampReq
+		_, err = asn1.Unmarshal(body, &req)
+		assert.NoError(t, err)
+
+		info, err := asn1.Marshal(tstInfo{
+			Version:        1,
+			Policy:         asn1.ObjectIdentifier{1, 2, 3},
+			MessageImprint: req.MessageImprint,
+			SerialNumber:   big.NewInt(42),
+			GenTime:        genTime,
+			Nonce:          req.Nonce,
+		})
+		assert.NoError(t, err)
+		sd, err := asn1.Marshal(signedData{
+			Version:          3,
+			DigestAlgorithms: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true},
+			EncapContentInfo: encapsulatedContentInfo{EContentType: oidTSTInfo, EContent: info},
+		})
+		assert.NoError(t, err)
+		token, err := asn1.Marshal(contentInfo{
+			ContentType: oidSignedData,
+			Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+		})
+		assert.NoError(t, err)
+		resp, err := asn1.Marshal(timeStampResp{Token: asn1.RawValue{FullBytes: token}})
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		_, _ = w.Write(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newRekorServer starts a fake Rekor log checking the signature of every
+// entry.
+func newRekorServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var index atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/log/entries" {
+			http.NotFound(w, r)
+			return
+		}
+		var entry hashedRekord
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&entry))
+		assert.Equal(t, "hashedrekord", entry.Kind)
+		digest, err := hex.DecodeString(entry.Spec.Data.Hash.Value)
+		assert.NoError(t, err)
+		signature, err := base64.StdEncoding.DecodeString(entry.Spec.Signature.Content)
+		assert.NoError(t, err)
+		publicKey, err := base64.StdEncoding.DecodeString(entry.Spec.Signature.PublicKey.Content)
+		assert.NoError(t, err)
+		block, _ := pem.Decode(publicKey)
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		assert.NoError(t, err)
+		if !ecdsa.VerifyASN1(key.(*ecdsa.PublicKey), digest, signature) {
+			http.Error(w, "invalid signature", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			hex.EncodeToString(digest): map[string]any{
+				"integratedTime": genTime.Unix(),
+				"logIndex":       index.Add(1),
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func writeRekorKey(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "rekor.key")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func testLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		sum := sha256.Sum256(fmt.Appendf(nil, "record %d", i))
+		leaves[i] = sum[:]
+	}
+	return leaves
+}
+
+func testConfig(t *testing.T, failures *atomic.Int32) *Config {
+	t.Helper()
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = storagetest.NewStorageID("test")
+	cfg.Interval = time.Hour
+	cfg.Server = configoptional.None[confighttp.ServerConfig]()
+
+	tsaClient := confighttp.NewDefaultClientConfig()
+	tsaClient.Endpoint = newTSAServer(t, failures).URL
+	cfg.TSA = configoptional.Some(TSAConfig{ClientConfig: tsaClient})
+	rekorClient := confighttp.NewDefaultClientConfig()
+	rekorClient.Endpoint = newRekorServer(t).URL
+	cfg.Rekor = configoptional.Some(RekorConfig{ClientConfig: rekorClient, PrivateKeyFile: writeRekorKey(t)})
+	return cfg
+}
+
+func startTestExtension(t *testing.T, cfg *Config, host component.Host, tel *componenttest.Telemetry) *notaryExtension {
+	t.Helper()
+	set := extensiontest.NewNopSettings(metadata.Type)
+	// The ID must be stable for the storage client to be reopened.
+	set.ID = component.NewID(metadata.Type)
+	if tel != nil {
+		set.TelemetrySettings = tel.NewTelemetrySettings()
+	}
+	ext, err := newNotaryExtension(cfg, set)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(t.Context(), host))
+	return ext
+}
+
+func TestNotarizeAndProof(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	ext := startTestExtension(t, testConfig(t, nil), storagetest.NewStorageHost().WithInMemoryStorageExtension("test"), tel)
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	leaves := testLeaves(5)
+	for _, leaf := range leaves {
+		require.NoError(t, ext.Submit(t.Context(), leaf))
+	}
+	_, err := ext.Proof(t.Context(), leaves[0])
+	require.ErrorIs(t, err, ErrProofNotFound)
+
+	ext.notarizePending(t.Context())
+	assert.Zero(t, ext.pendingCount())
+
+	root := hex.EncodeToString(merkle.Root(leaves))
+	for i, leaf := range leaves {
+		proof, err := ext.Proof(t.Context(), leaf)
+		require.NoError(t, err)
+		require.NoError(t, proof.Verify())
+		assert.Equal(t, root, proof.Root)
+		assert.Equal(t, i, proof.LeafIndex)
+		assert.Equal(t, 5, proof.TreeSize)
+
+		require.Len(t, proof.Notarizations, 2)
+		assert.Equal(t, backendTSA, proof.Notarizations[0].Backend)
+		assert.Equal(t, genTime, proof.Notarizations[0].Time)
+		_, info, err := parseTimeStampResp(mustMarshalResp(t, proof.Notarizations[0].Evidence))
+		require.NoError(t, err)
+		assert.Equal(t, root, hex.EncodeToString(info.MessageImprint.HashedMessage))
+		assert.Equal(t, backendRekor, proof.Notarizations[1].Backend)
+		assert.Equal(t, genTime, proof.Notarizations[1].Time)
+		assert.Contains(t, string(proof.Notarizations[1].Evidence), root)
+	}
+
+	metadatatest.AssertEqualExtensionNotarizationBatches(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 1, Attributes: attribute.NewSet(attribute.String("outcome", outcomeNotarized))},
+	}, metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualExtensionNotarizationHashes(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 5},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func mustMarshalResp(t *testing.T, token []byte) []byte {
+	t.Helper()
+	resp, err := asn1.Marshal(timeStampResp{Token: asn1.RawValue{FullBytes: token}})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestProofVerifyRejectsTampering(t *testing.T) {
+	ext := startTestExtension(t, testConfig(t, nil), storagetest.NewStorageHost().WithInMemoryStorageExtension("test"), nil)
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	leaves := testLeaves(4)
+	for _, leaf := range leaves {
+		require.NoError(t, ext.Submit(t.Context(), leaf))
+	}
+	ext.notarizePending(t.Context())
+
+	proof, err := ext.Proof(t.Context(), leaves[1])
+	require.NoError(t, err)
+	proof.Hash = hex.EncodeToString(leaves[2])
+	assert.EqualError(t, proof.Verify(), "audit path does not lead to the root")
+}
+
+func TestNotarizeRetriesFailedBatch(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	var failures atomic.Int32
+	failures.Store(1)
+	ext := startTestExtension(t, testConfig(t, &failures), storagetest.NewStorageHost().WithInMemoryStorageExtension("test"), tel)
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	leaves := testLeaves(3)
+	for _, leaf := range leaves[:2] {
+		require.NoError(t, ext.Submit(t.Context(), leaf))
+	}
+	ext.notarizePending(t.Context())
+	assert.Equal(t, 2, ext.pendingCount())
+	_, err := ext.Proof(t.Context(), leaves[0])
+	require.ErrorIs(t, err, ErrProofNotFound)
+
+	require.NoError(t, ext.Submit(t.Context(), leaves[2]))
+	ext.notarizePending(t.Context())
+	assert.Zero(t, ext.pendingCount())
+	for _, leaf := range leaves {
+		proof, err := ext.Proof(t.Context(), leaf)
+		require.NoError(t, err)
+		assert.Equal(t, 3, proof.TreeSize)
+	}
+
+	metadatatest.AssertEqualExtensionNotarizationBatches(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 1, Attributes: attribute.NewSet(attribute.String("outcome", outcomeFailed))},
+		{Value: 1, Attributes: attribute.NewSet(attribute.String("outcome", outcomeNotarized))},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestMaxBatchSize(t *testing.T) {
+	cfg := testConfig(t, nil)
+	cfg.MaxBatchSize = 2
+	ext := startTestExtension(t, cfg, storagetest.NewStorageHost().WithInMemoryStorageExtension("test"), nil)
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	leaves := testLeaves(2)
+	for _, leaf := range leaves {
+		require.NoError(t, ext.Submit(t.Context(), leaf))
+	}
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		_, err := ext.Proof(t.Context(), leaves[1])
+		assert.NoError(c, err)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	proof, err := ext.Proof(t.Context(), leaves[1])
+	require.NoError(t, err)
+	assert.Equal(t, 2, proof.TreeSize)
+}
+
+func TestShutdownNotarizesPending(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := testConfig(t, nil)
+	leaf := testLeaves(1)[0]
+
+	ext := startTestExtension(t, cfg, host, nil)
+	require.NoError(t, ext.Submit(t.Context(), leaf))
+	require.NoError(t, ext.Shutdown(t.Context()))
+
+	ext = startTestExtension(t, cfg, host, nil)
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+	proof, err := ext.Proof(t.Context(), leaf)
+	require.NoError(t, err)
+	assert.NoError(t, proof.Verify())
+}
+
+func TestPendingSurvivesRestart(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	var failures atomic.Int32
+	failures.Store(1 << 20)
+	leaves := testLeaves(3)
+
+	ext := startTestExtension(t, testConfig(t, &failures), host, nil)
+	for _, leaf := range leaves {
+		require.NoError(t, ext.Submit(t.Context(), leaf))
+	}
+	require.NoError(t, ext.Shutdown(t.Context()))
+
+	ext = startTestExtension(t, testConfig(t, nil), host, nil)
+	assert.Equal(t, 3, ext.pendingCount())
+	ext.notarizePending(t.Context())
+	assert.Zero(t, ext.pendingCount())
+	for i, leaf := range leaves {
+		proof, err := ext.Proof(t.Context(), leaf)
+		require.NoError(t, err)
+		assert.Equal(t, i, proof.LeafIndex)
+		assert.Equal(t, 3, proof.TreeSize)
+	}
+	require.NoError(t, ext.Shutdown(t.Context()))
+
+	// Notarized hashes are removed from the pending ones in storage.
+	ext = startTestExtension(t, testConfig(t, &failures), host, nil)
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+	assert.Zero(t, ext.pendingCount())
+}
+
+func TestMaxPendingHashes(t *testing.T) {
+	var failures atomic.Int32
+	failures.Store(1 << 20)
+	cfg := testConfig(t, &failures)
+	cfg.MaxPendingHashes = 2
+	ext := startTestExtension(t, cfg, storagetest.NewStorageHost().WithInMemoryStorageExtension("test"), nil)
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	leaves := testLeaves(3)
+	require.NoError(t, ext.Submit(t.Context(), leaves[0]))
+	require.NoError(t, ext.Submit(t.Context(), leaves[1]))
+	require.ErrorIs(t, ext.Submit(t.Context(), leaves[2]), errPendingFull)
+	ext.notarizePending(t.Context())
+	assert.Equal(t, 2, ext.pendingCount())
+}
+
+func TestSubmitRejectsInvalidHash(t *testing.T) {
+	ext := startTestExtension(t, testConfig(t, nil), storagetest.NewStorageHost().WithInMemoryStorageExtension("test"), nil)
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	assert.EqualError(t, ext.Submit(t.Context(), []byte("short")), "hash must be 32 bytes, got 5")
+	assert.Zero(t, ext.pendingCount())
+}
+
+func TestStartErrors(t *testing.T) {
+	cfg := testConfig(t, nil)
+	set := extensiontest.NewNopSettings(metadata.Type)
+	ext, err := newNotaryExtension(cfg, set)
+	require.NoError(t, err)
+	assert.EqualError(t, ext.Start(t.Context(), componenttest.NewNopHost()), `storage extension "test_storage/test" not found`)
+	require.NoError(t, ext.Shutdown(t.Context()))
+
+	cfg.Rekor.Get().PrivateKeyFile = filepath.Join(t.TempDir(), "missing.key")
+	ext, err = newNotaryExtension(cfg, set)
+	require.NoError(t, err)
+	assert.ErrorContains(t, ext.Start(t.Context(), componenttest.NewNopHost()), "failed to read rekor private key")
+	require.NoError(t, ext.Shutdown(t.Context()))
+}
+
+func TestProofServer(t *testing.T) {
+	cfg := testConfig(t, nil)
+	serverConfig := confighttp.NewDefaultServerConfig()
+	serverConfig.NetAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+	cfg.Server = configoptional.Some(serverConfig)
+	ext := startTestExtension(t, cfg, storagetest.NewStorageHost().WithInMemoryStorageExtension("test"), nil)
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	leaves := testLeaves(3)
+	for _, leaf := range leaves[:2] {
+		require.NoError(t, ext.Submit(t.Context(), leaf))
+	}
+	ext.notarizePending(t.Context())
+
+	get := func(hash string) *http.Response {
+		resp, err := http.Get("http://" + serverConfig.NetAddr.Endpoint + "/proofs/" + hash)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := get(hex.EncodeToString(leaves[1]))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var proof Proof
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&proof))
+	assert.NoError(t, proof.Verify())
+	assert.Equal(t, 1, proof.LeafIndex)
+
+	assert.Equal(t, http.StatusNotFound, get(hex.EncodeToString(leaves[2])).StatusCode)
+	sum := sha256.Sum256([]byte("unknown"))
+	assert.Equal(t, http.StatusNotFound, get(hex.EncodeToString(sum[:])).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, get("not-a-hash").StatusCode)
+}
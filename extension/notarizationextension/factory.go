@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package notarizationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/confignet"
+	"go.opentelemetry.io/collector/config/configoptional"
+	"go.opentelemetry.io/collector/extension"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension/internal/metadata"
+)
+
+// NewFactory creates a factory for the notarization extension.
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		createExtension,
+		metadata.ExtensionStability,
+	)
+}
+
+func createDefaultConfig() component.Config {
+	rekorClient := confighttp.NewDefaultClientConfig()
+	rekorClient.Endpoint = "https://rekor.sigstore.dev"
+	serverConfig := confighttp.NewDefaultServerConfig()
+	serverConfig.NetAddr = confignet.AddrConfig{
+		Transport: confignet.TransportTypeTCP,
+		Endpoint:  "localhost:55682",
+	}
+	return &Config{
+		Interval:         time.Minute,
+		MaxBatchSize:     10000,
+		MaxPendingHashes: 1000000,
+		TSA: configoptional.Default(TSAConfig{
+			ClientConfig: confighttp.NewDefaultClientConfig(),
+		}),
+		Rekor: configoptional.Default(RekorConfig{
+			ClientConfig: rekorClient,
+		}),
+		Server: configoptional.Default(serverConfig),
+	}
+}
+
+func createExtension(_ context.Context, set extension.Settings, cfg component.Config) (extension.Extension, error) {
+	return newNotaryExtension(cfg.(*Config), set)
+}
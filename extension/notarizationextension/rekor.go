@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package notarizationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension"
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const backendRekor = "rekor"
+
+// rekorNotarizer records batch roots as hashedrekord entries in a Rekor
+// transparency log.
+type rekorNotarizer struct {
+	client    *http.Client
+	cfg       *RekorConfig
+	key       *ecdsa.PrivateKey
+	publicKey []byte
+}
+
+func newRekorNotarizer(client *http.Client, cfg *RekorConfig) (*rekorNotarizer, error) {
+	data, err := os.ReadFile(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rekor private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("rekor private key is not PEM encoded")
+	}
+	var key *ecdsa.PrivateKey
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		var parsed any
+		if parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+			var ok bool
+			if key, ok = parsed.(*ecdsa.PrivateKey); !ok {
+				return nil, fmt.Errorf("unsupported rekor private key type %T", parsed)
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rekor private key: %w", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &rekorNotarizer{
+		client:    client,
+		cfg:       cfg,
+		key:       key,
+		publicKey: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+	}, nil
+}
+
+func (*rekorNotarizer) name() string {
+	return backendRekor
+}
+
+type hashedRekord struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Spec       hashedRekordSpec `json:"spec"`
+}
+
+type hashedRekordSpec struct {
+	Data struct {
+		Hash struct {
+			Algorithm string `json:"algorithm"`
+			Value     string `json:"value"`
+		} `json:"hash"`
+	} `json:"data"`
+	Signature struct {
+		Content   string `json:"content"`
+		PublicKey struct {
+			Content string `json:"content"`
+		} `json:"publicKey"`
+	} `json:"signature"`
+}
+
+type rekorLogEntry struct {
+	IntegratedTime int64 `json:"integratedTime"`
+}
+
+// notarize signs root and records it in the log. The evidence is the log
+// entry returned by Rekor, keyed by its UUID, including the signed entry
+// timestamp and inclusion proof when the log provides them.
+func (n *rekorNotarizer) notarize(ctx context.Context, root []byte) (Notarization, error) {
+	signature, err := ecdsa.SignASN1(rand.Reader, n.key, root)
+	if err != nil {
+		return Notarization{}, err
+	}
+	var entry hashedRekord
+	entry.APIVersion = "0.0.1"
+	entry.Kind = "hashedrekord"
+	entry.Spec.Data.Hash.Algorithm = "sha256"
+	entry.Spec.Data.Hash.Value = hex.EncodeToString(root)
+	entry.Spec.Signature.Content = base64.StdEncoding.EncodeToString(signature)
+	entry.Spec.Signature.PublicKey.Content = base64.StdEncoding.EncodeToString(n.publicKey)
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return Notarization{}, err
+	}
+
+	url := strings.TrimSuffix(n.cfg.Endpoint, "/") + "/api/v1/log/entries"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return Notarization{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return Notarization{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Notarization{}, err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return Notarization{}, fmt.Errorf("rekor returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var entries map[string]rekorLogEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return Notarization{}, fmt.Errorf("failed to decode rekor response: %w", err)
+	}
+	if len(entries) != 1 {
+		return Notarization{}, fmt.Errorf("rekor returned %d log entries, expected 1", len(entries))
+	}
+	var integrated int64
+	for _, e := range entries {
+		integrated = e.IntegratedTime
+	}
+	return Notarization{Backend: backendRekor, Time: time.Unix(integrated, 0).UTC(), Evidence: body}, nil
+}
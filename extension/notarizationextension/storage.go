@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package notarizationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension"
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	batchKeyPrefix = "batch/"
+	hashKeyPrefix  = "hash/"
+	// Pending hashes are stored one per key, under consecutive sequence
+	// numbers starting at the one in pendingStartKey.
+	pendingKeyPrefix = "pending/"
+	pendingStartKey  = "pending_start"
+)
+
+// batchRecord is a notarized batch as kept in storage under its root. Every
+// hash of the batch is stored under its own key pointing to the root.
+type batchRecord struct {
+	Root          string         `json:"root"`
+	Leaves        []string       `json:"leaves"`
+	Notarizations []Notarization `json:"notarizations"`
+}
+
+// getStorageClient resolves a storage.Client for the extension.
+func getStorageClient(ctx context.Context, host component.Host, storageID, componentID component.ID) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", storageID)
+	}
+
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", storageID)
+	}
+
+	return storageExt.GetClient(ctx, component.KindExtension, componentID, "")
+}
+
+// storeBatch writes a batch and the index of its hashes, and removes the
+// pending hashes start to end-1 it covers, in one operation.
+func (e *notaryExtension) storeBatch(ctx context.Context, record batchRecord, start, end uint64) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	ops := make([]*storage.Operation, 0, 2*len(record.Leaves)+2)
+	ops = append(ops,
+		storage.SetOperation(batchKeyPrefix+record.Root, data),
+		storage.SetOperation(pendingStartKey, binary.BigEndian.AppendUint64(nil, end)))
+	for _, leaf := range record.Leaves {
+		ops = append(ops, storage.SetOperation(hashKeyPrefix+leaf, []byte(record.Root)))
+	}
+	for seq := start; seq < end; seq++ {
+		ops = append(ops, storage.DeleteOperation(pendingKey(seq)))
+	}
+	if err := e.storageClient.Batch(ctx, ops...); err != nil {
+		return fmt.Errorf("failed to store proofs: %w", err)
+	}
+	return nil
+}
+
+// loadBatch reads the batch a hash was notarized with.
+func (e *notaryExtension) loadBatch(ctx context.Context, hash []byte) (batchRecord, error) {
+	var record batchRecord
+	root, err := e.storageClient.Get(ctx, hashKeyPrefix+hex.EncodeToString(hash))
+	if err != nil {
+		return record, fmt.Errorf("failed to read proof: %w", err)
+	}
+	if root == nil {
+		return record, ErrProofNotFound
+	}
+	data, err := e.storageClient.Get(ctx, batchKeyPrefix+string(root))
+	if err != nil {
+		return record, fmt.Errorf("failed to read proof: %w", err)
+	}
+	if data == nil {
+		return record, fmt.Errorf("batch %s of hash not found", root)
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, fmt.Errorf("failed to decode stored batch: %w", err)
+	}
+	return record, nil
+}
+
+// loadPending restores the hashes that were not notarized before the
+// extension was stopped.
+func (e *notaryExtension) loadPending(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	start, err := e.storageClient.Get(ctx, pendingStartKey)
+	if err != nil {
+		return fmt.Errorf("failed to read pending hashes: %w", err)
+	}
+	if len(start) == 8 {
+		e.pendingStart = binary.BigEndian.Uint64(start)
+	}
+	for e.pendingNext = e.pendingStart; ; e.pendingNext++ {
+		hash, err := e.storageClient.Get(ctx, pendingKey(e.pendingNext))
+		if err != nil {
+			return fmt.Errorf("failed to read pending hashes: %w", err)
+		}
+		if hash == nil {
+			break
+		}
+		e.pending = append(e.pending, hash)
+	}
+	if len(e.pending) > 0 {
+		e.logger.Info("Loaded pending hashes from storage", zap.Int("hashes", len(e.pending)))
+	}
+	return nil
+}
+
+func pendingKey(seq uint64) string {
+	return pendingKeyPrefix + strconv.FormatUint(seq, 10)
+}
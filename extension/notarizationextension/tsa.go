@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package notarizationextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/notarizationextension"
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const backendTSA = "tsa"
+
+var (
+	oidSHA256     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+)
+
+// The RFC 3161 structures, limited to the fields the extension needs.
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int
+	CertReq        bool
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo encapsulatedContentInfo
+}
+
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,tag:0"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Accuracy       accuracy  `asn1:"optional"`
+	Ordering       bool      `asn1:"optional"`
+	Nonce          *big.Int  `asn1:"optional"`
+}
+
+// tsaNotarizer obtains RFC 3161 timestamp tokens over batch roots.
+type tsaNotarizer struct {
+	client *http.Client
+	cfg    *TSAConfig
+}
+
+func (*tsaNotarizer) name() string {
+	return backendTSA
+}
+
+// notarize requests a timestamp token over root. The token is checked to
+// cover root and the request nonce; its signature is verified by whoever
+// relies on the proof, against the certificate of the time-stamp authority.
+func (n *tsaNotarizer) notarize(ctx context.Context, root []byte) (Notarization, error) {
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return Notarization{}, err
+	}
+	imprint := messageImprint{
+		HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+		HashedMessage: root,
+	}
+	req, err := asn1.Marshal(timeStampReq{Version: 1, MessageImprint: imprint, Nonce: nonce, CertReq: true})
+	if err != nil {
+		return Notarization{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.Endpoint, bytes.NewReader(req))
+	if err != nil {
+		return Notarization{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/timestamp-query")
+	resp, err := n.client.Do(httpReq)
+	if err != nil {
+		return Notarization{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Notarization{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Notarization{}, fmt.Errorf("time-stamp authority returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	token, info, err := parseTimeStampResp(body)
+	if err != nil {
+		return Notarization{}, err
+	}
+	if !info.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) || !bytes.Equal(info.MessageImprint.HashedMessage, root) {
+		return Notarization{}, errors.New("timestamp token does not cover the batch root")
+	}
+	if info.Nonce == nil || info.Nonce.Cmp(nonce) != 0 {
+		return Notarization{}, errors.New("timestamp token does not match the request nonce")
+	}
+	return Notarization{Backend: backendTSA, Time: info.GenTime, Evidence: token}, nil
+}
+
+// parseTimeStampResp returns the DER encoded timestamp token of a
+// TimeStampResp and the TSTInfo it contains.
+func parseTimeStampResp(der []byte) ([]byte, tstInfo, error) {
+	var resp asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &resp); err != nil {
+		return nil, tstInfo{}, fmt.Errorf("invalid timestamp response: %w", err)
+	}
+	var statusInfo asn1.RawValue
+	token, err := asn1.Unmarshal(resp.Bytes, &statusInfo)
+	if err != nil {
+		return nil, tstInfo{}, fmt.Errorf("invalid timestamp response status: %w", err)
+	}
+	var status int
+	if _, err = asn1.Unmarshal(statusInfo.Bytes, &status); err != nil {
+		return nil, tstInfo{}, fmt.Errorf("invalid timestamp response status: %w", err)
+	}
+	// 0 is granted, 1 granted with modifications.
+	if status != 0 && status != 1 {
+		return nil, tstInfo{}, fmt.Errorf("time-stamp authority rejected the request with status %d", status)
+	}
+	if len(token) == 0 {
+		return nil, tstInfo{}, errors.New("timestamp response contains no token")
+	}
+
+	var ci contentInfo
+	if _, err = asn1.Unmarshal(token, &ci); err != nil {
+		return nil, tstInfo{}, fmt.Errorf("invalid timestamp token: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, tstInfo{}, fmt.Errorf("unexpected timestamp token content type %s", ci.ContentType)
+	}
+	var sd signedData
+	if _, err = asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, tstInfo{}, fmt.Errorf("invalid timestamp token: %w", err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, tstInfo{}, fmt.Errorf("unexpected timestamp token content type %s", sd.EncapContentInfo.EContentType)
+	}
+	var info tstInfo
+	if _, err = asn1.Unmarshal(sd.EncapContentInfo.EContent, &info); err != nil {
+		return nil, tstInfo{}, fmt.Errorf("invalid timestamp token info: %w", err)
+	}
+	return token, info, nil
+}
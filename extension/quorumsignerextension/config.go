@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package quorumsignerextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/quorumsignerextension"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// Config defines configuration for the quorum signer extension.
+type Config struct {
+	// Signers are the remote signers signature shares are requested from.
+	Signers []SignerConfig `mapstructure:"signers"`
+
+	// Quorum is the number of valid signature shares a combined signature
+	// requires. It must not exceed the number of signers.
+	Quorum int `mapstructure:"quorum"`
+}
+
+// SignerConfig defines a remote signer.
+type SignerConfig struct {
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	// Name identifies the signer in combined signatures.
+	Name string `mapstructure:"name"`
+
+	// PublicKeyFile is the path of the PEM encoded PKIX public key of the
+	// signer, used to verify its signature shares. Ed25519 and ECDSA keys are
+	// supported. Every signer must have its own key.
+	PublicKeyFile string `mapstructure:"public_key_file"`
+}
+
+// Validate checks if the extension configuration is valid.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if len(cfg.Signers) == 0 {
+		errs = append(errs, errors.New("signers must not be empty"))
+	}
+	names := map[string]bool{}
+	keyFiles := map[string]bool{}
+	for i, signer := range cfg.Signers {
+		if signer.Name == "" {
+			errs = append(errs, fmt.Errorf("signers[%d]: name must be specified", i))
+		} else if names[signer.Name] {
+			errs = append(errs, fmt.Errorf("signers[%d]: duplicate name %q", i, signer.Name))
+		}
+		names[signer.Name] = true
+		if signer.Endpoint == "" {
+			errs = append(errs, fmt.Errorf("signers[%d]: endpoint must be specified", i))
+		}
+		if signer.PublicKeyFile == "" {
+			errs = append(errs, fmt.Errorf("signers[%d]: public_key_file must be specified", i))
+		} else if keyFiles[signer.PublicKeyFile] {
+			// Shares made with the same key count once towards the quorum.
+			errs = append(errs, fmt.Errorf("signers[%d]: duplicate public_key_file %q", i, signer.PublicKeyFile))
+		}
+		keyFiles[signer.PublicKeyFile] = true
+	}
+	if cfg.Quorum < 1 || cfg.Quorum > len(cfg.Signers) {
+		errs = append(errs, fmt.Errorf("quorum must be between 1 and the number of signers (%d)", len(cfg.Signers)))
+	}
+	return errors.Join(errs...)
+}
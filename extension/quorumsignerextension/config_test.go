@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package quorumsignerextension
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/quorumsignerextension/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	signer := func(name string) SignerConfig {
+		return SignerConfig{
+			ClientConfig:  confighttp.ClientConfig{Endpoint: "https://signer-" + name + ".example.com/sign"},
+			Name:          name,
+			PublicKeyFile: "/etc/otelcol/keys/" + name + ".pem",
+		}
+	}
+	tests := []struct {
+		id          component.ID
+		expected    *Config
+		expectedErr string
+	}{
+		{
+			id: component.NewID(metadata.Type),
+			expected: &Config{
+				Signers: []SignerConfig{signer("security"), signer("compliance"), signer("operations")},
+				Quorum:  2,
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "empty"),
+			expectedErr: "signers must not be empty\nquorum must be between 1 and the number of signers (0)",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "invalid"),
+			expectedErr: `signers[1]: duplicate name "security"` + "\n" +
+				"signers[1]: endpoint must be specified\n" +
+				`signers[1]: duplicate public_key_file "/etc/otelcol/keys/security.pem"` + "\n" +
+				"quorum must be between 1 and the number of signers (2)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package quorumsignerextension signs digests with a quorum of remote signers
+// and combines their signatures, so that no single signer controls the
+// signing capability.
+package quorumsignerextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/quorumsignerextension"
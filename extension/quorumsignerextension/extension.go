@@ -0,0 +1,218 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package quorumsignerextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/quorumsignerextension"
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/quorumsignerextension/internal/metadata"
+)
+
+const (
+	outcomeSigned  = "signed"
+	outcomeInvalid = "invalid"
+	outcomeFailed  = "failed"
+)
+
+// QuorumSigner is implemented by the extension. Signing components find it
+// among the host extensions.
+type QuorumSigner interface {
+	// Sign requests a signature of digest from every signer and returns the
+	// JSON encoded CombinedSignature as soon as a quorum of valid shares
+	// was collected. It fails when the quorum cannot be reached anymore.
+	Sign(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+var _ QuorumSigner = (*quorumSignerExtension)(nil)
+
+// remoteSigner is a configured signer and its verification key.
+type remoteSigner struct {
+	config    SignerConfig
+	publicKey crypto.PublicKey
+	keyID     string
+	client    *http.Client
+}
+
+type quorumSignerExtension struct {
+	config    *Config
+	set       extension.Settings
+	logger    *zap.Logger
+	telemetry *metadata.TelemetryBuilder
+	signers   []*remoteSigner
+}
+
+func newQuorumSignerExtension(cfg *Config, set extension.Settings) (*quorumSignerExtension, error) {
+	signers := make([]*remoteSigner, len(cfg.Signers))
+	keyIDs := map[string]string{}
+	for i, signerCfg := range cfg.Signers {
+		data, err := os.ReadFile(signerCfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("signer %q: %w", signerCfg.Name, err)
+		}
+		pub, err := parsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("signer %q: %w", signerCfg.Name, err)
+		}
+		id, err := keyID(pub)
+		if err != nil {
+			return nil, fmt.Errorf("signer %q: %w", signerCfg.Name, err)
+		}
+		if other, ok := keyIDs[id]; ok {
+			// Verify counts the shares of a key once, so would Sign.
+			return nil, fmt.Errorf("signer %q: same public key as signer %q", signerCfg.Name, other)
+		}
+		keyIDs[id] = signerCfg.Name
+		signers[i] = &remoteSigner{config: signerCfg, publicKey: pub, keyID: id}
+	}
+
+	tel, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+	return &quorumSignerExtension{
+		config:    cfg,
+		set:       set,
+		logger:    set.Logger,
+		telemetry: tel,
+		signers:   signers,
+	}, nil
+}
+
+func (e *quorumSignerExtension) Start(ctx context.Context, host component.Host) error {
+	for _, signer := range e.signers {
+		client, err := signer.config.ToClient(ctx, host.GetExtensions(), e.set.TelemetrySettings)
+		if err != nil {
+			return fmt.Errorf("signer %q: %w", signer.config.Name, err)
+		}
+		signer.client = client
+	}
+	return nil
+}
+
+func (e *quorumSignerExtension) Shutdown(context.Context) error {
+	e.telemetry.Shutdown()
+	return nil
+}
+
+type shareResult struct {
+	index int
+	share Share
+	err   error
+}
+
+func (e *quorumSignerExtension) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	// Pending requests are abandoned once the outcome is known.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan shareResult, len(e.signers))
+	for i, signer := range e.signers {
+		go func() {
+			share, err := e.requestShare(ctx, signer, digest)
+			results <- shareResult{index: i, share: share, err: err}
+		}()
+	}
+
+	// Valid shares are counted by key, as Verify does.
+	shares := make([]*Share, len(e.signers))
+	signed := map[string]bool{}
+	var errs []error
+	for range e.signers {
+		res := <-results
+		if res.err != nil {
+			errs = append(errs, res.err)
+			if len(errs) > len(e.signers)-e.config.Quorum {
+				return nil, fmt.Errorf("quorum of %d cannot be reached: %w", e.config.Quorum, errors.Join(errs...))
+			}
+			continue
+		}
+		shares[res.index] = &res.share
+		signed[res.share.KeyID] = true
+		if len(signed) == e.config.Quorum {
+			break
+		}
+	}
+
+	combined := CombinedSignature{Quorum: e.config.Quorum}
+	for _, share := range shares {
+		if share != nil {
+			combined.Shares = append(combined.Shares, *share)
+		}
+	}
+	return json.Marshal(combined)
+}
+
+type shareRequest struct {
+	Digest []byte `json:"digest"`
+}
+
+type shareResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+// requestShare obtains the share of a signer and records its outcome.
+func (e *quorumSignerExtension) requestShare(ctx context.Context, signer *remoteSigner, digest []byte) (Share, error) {
+	share, outcome, err := signer.sign(ctx, digest)
+	if err != nil && ctx.Err() != nil {
+		// The quorum was reached or the caller gave up: the share is not
+		// missing.
+		return share, err
+	}
+	e.telemetry.ExtensionQuorumSignerShares.Add(ctx, 1, metric.WithAttributeSet(attribute.NewSet(attribute.String("outcome", outcome))))
+	if err != nil {
+		e.logger.Warn("Failed to obtain a signature share", zap.String("signer", signer.config.Name), zap.Error(err))
+		return share, fmt.Errorf("signer %q: %w", signer.config.Name, err)
+	}
+	return share, nil
+}
+
+// sign asks the signer for its signature of digest and verifies it. The
+// signer is sent the digest as base64 in JSON and answers with the base64
+// signature in JSON.
+func (s *remoteSigner) sign(ctx context.Context, digest []byte) (Share, string, error) {
+	body, err := json.Marshal(shareRequest{Digest: digest})
+	if err != nil {
+		return Share{}, outcomeFailed, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Share{}, outcomeFailed, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Share{}, outcomeFailed, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return Share{}, outcomeFailed, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Share{}, outcomeFailed, fmt.Errorf("signature request failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	var res shareResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return Share{}, outcomeInvalid, fmt.Errorf("failed to decode signature response: %w", err)
+	}
+	if err := verifyShare(s.publicKey, digest, res.Signature); err != nil {
+		return Share{}, outcomeInvalid, err
+	}
+	return Share{Signer: s.config.Name, KeyID: s.keyID, Signature: res.Signature}, outcomeSigned, nil
+}
@@ -0,0 +1,223 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package quorumsignerextension
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/quorumsignerextension/internal/metadatatest"
+)
+
+var digest = sha256.Sum256([]byte("audit batch"))
+
+// testSigner is a remote signer holding a private key.
+type testSigner struct {
+	config    SignerConfig
+	publicKey crypto.PublicKey
+}
+
+// newTestSigner starts a signer answering with the signature made by sign, or
+// failing with status when sign is nil.
+func newTestSigner(t *testing.T, name string, pub crypto.PublicKey, sign func([]byte) []byte) testSigner {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req shareRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || sign == nil {
+			http.Error(w, "signer unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(shareResponse{Signature: sign(req.Digest)})
+	}))
+	t.Cleanup(server.Close)
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), name+".pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	clientConfig := confighttp.NewDefaultClientConfig()
+	clientConfig.Endpoint = server.URL
+	return testSigner{
+		config:    SignerConfig{ClientConfig: clientConfig, Name: name, PublicKeyFile: keyFile},
+		publicKey: pub,
+	}
+}
+
+func newEd25519Signer(t *testing.T, name string) testSigner {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return newTestSigner(t, name, pub, func(d []byte) []byte { return ed25519.Sign(priv, d) })
+}
+
+func newECDSASigner(t *testing.T, name string) testSigner {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return newTestSigner(t, name, priv.Public(), func(d []byte) []byte {
+		sig, err := ecdsa.SignASN1(rand.Reader, priv, d)
+		require.NoError(t, err)
+		return sig
+	})
+}
+
+// newFailingSigner starts a signer answering with an error.
+func newFailingSigner(t *testing.T, name string) testSigner {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return newTestSigner(t, name, pub, nil)
+}
+
+// newImpostorSigner starts a signer signing with another key than its
+// configured public key.
+func newImpostorSigner(t *testing.T, name string) testSigner {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, other, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return newTestSigner(t, name, pub, func(d []byte) []byte { return ed25519.Sign(other, d) })
+}
+
+func startExtension(t *testing.T, quorum int, signers ...testSigner) (*quorumSignerExtension, *componenttest.Telemetry) {
+	t.Helper()
+	cfg := &Config{Quorum: quorum}
+	for _, s := range signers {
+		cfg.Signers = append(cfg.Signers, s.config)
+	}
+	require.NoError(t, cfg.Validate())
+
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	ext, err := newQuorumSignerExtension(cfg, metadatatest.NewSettings(tel))
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+	return ext, tel
+}
+
+func publicKeys(signers ...testSigner) []crypto.PublicKey {
+	keys := make([]crypto.PublicKey, len(signers))
+	for i, s := range signers {
+		keys[i] = s.publicKey
+	}
+	return keys
+}
+
+func TestSign(t *testing.T) {
+	signers := []testSigner{newEd25519Signer(t, "security"), newECDSASigner(t, "compliance"), newEd25519Signer(t, "operations")}
+	ext, tel := startExtension(t, 3, signers...)
+
+	combined, err := ext.Sign(t.Context(), digest[:])
+	require.NoError(t, err)
+
+	var sig CombinedSignature
+	require.NoError(t, json.Unmarshal(combined, &sig))
+	assert.Equal(t, 3, sig.Quorum)
+	require.Len(t, sig.Shares, 3)
+	for i, share := range sig.Shares {
+		assert.Equal(t, signers[i].config.Name, share.Signer)
+		id, err := keyID(signers[i].publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, id, share.KeyID)
+	}
+
+	assert.NoError(t, Verify(combined, digest[:], publicKeys(signers...), 3))
+	other := sha256.Sum256([]byte("tampered batch"))
+	assert.EqualError(t, Verify(combined, other[:], publicKeys(signers...), 3), "0 valid signature shares, 3 required")
+	assert.EqualError(t, Verify(combined, digest[:], publicKeys(signers[0]), 2), "1 valid signature shares, 2 required")
+
+	metadatatest.AssertEqualExtensionQuorumSignerShares(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 3, Attributes: attribute.NewSet(attribute.String("outcome", "signed"))},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestSignToleratesFailures(t *testing.T) {
+	security := newEd25519Signer(t, "security")
+	compliance := newEd25519Signer(t, "compliance")
+	ext, _ := startExtension(t, 2, newFailingSigner(t, "operations"), security, newImpostorSigner(t, "legal"), compliance)
+
+	combined, err := ext.Sign(t.Context(), digest[:])
+	require.NoError(t, err)
+
+	var sig CombinedSignature
+	require.NoError(t, json.Unmarshal(combined, &sig))
+	require.Len(t, sig.Shares, 2)
+	assert.Equal(t, "security", sig.Shares[0].Signer)
+	assert.Equal(t, "compliance", sig.Shares[1].Signer)
+	assert.NoError(t, Verify(combined, digest[:], publicKeys(security, compliance), 2))
+}
+
+func TestSignWithoutQuorum(t *testing.T) {
+	ext, tel := startExtension(t, 1, newFailingSigner(t, "operations"), newImpostorSigner(t, "legal"))
+
+	_, err := ext.Sign(t.Context(), digest[:])
+	assert.ErrorContains(t, err, "quorum of 1 cannot be reached")
+	assert.ErrorContains(t, err, `signer "operations": signature request failed with status 503: signer unavailable`)
+	assert.ErrorContains(t, err, `signer "legal": invalid signature`)
+
+	metadatatest.AssertEqualExtensionQuorumSignerShares(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 1, Attributes: attribute.NewSet(attribute.String("outcome", "failed"))},
+		{Value: 1, Attributes: attribute.NewSet(attribute.String("outcome", "invalid"))},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestVerifyCountsKeysOnce(t *testing.T) {
+	security := newEd25519Signer(t, "security")
+	ext, _ := startExtension(t, 1, security)
+	combined, err := ext.Sign(t.Context(), digest[:])
+	require.NoError(t, err)
+
+	var sig CombinedSignature
+	require.NoError(t, json.Unmarshal(combined, &sig))
+	sig.Quorum = 2
+	sig.Shares = append(sig.Shares, sig.Shares[0])
+	duplicated, err := json.Marshal(sig)
+	require.NoError(t, err)
+	assert.EqualError(t, Verify(duplicated, digest[:], publicKeys(security), 2), "1 valid signature shares, 2 required")
+}
+
+func TestInvalidPublicKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(keyFile, []byte("not a key"), 0o600))
+	cfg := &Config{Quorum: 1, Signers: []SignerConfig{{Name: "security", PublicKeyFile: keyFile}}}
+
+	_, err := newQuorumSignerExtension(cfg, metadatatest.NewSettings(componenttest.NewTelemetry()))
+	assert.EqualError(t, err, `signer "security": not a PEM encoded PUBLIC KEY`)
+}
+
+func TestDuplicatePublicKey(t *testing.T) {
+	security := newEd25519Signer(t, "security")
+	data, err := os.ReadFile(security.config.PublicKeyFile)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "copy.pem")
+	require.NoError(t, os.WriteFile(keyFile, data, 0o600))
+	copied := security.config
+	copied.Name = "compliance"
+	copied.PublicKeyFile = keyFile
+	cfg := &Config{Quorum: 2, Signers: []SignerConfig{security.config, copied}}
+	require.NoError(t, cfg.Validate())
+
+	_, err = newQuorumSignerExtension(cfg, metadatatest.NewSettings(componenttest.NewTelemetry()))
+	assert.EqualError(t, err, `signer "compliance": same public key as signer "security"`)
+}
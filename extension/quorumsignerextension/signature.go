@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package quorumsignerextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/quorumsignerextension"
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// CombinedSignature is a signature made by a quorum of signers. It is
+// encoded as JSON.
+type CombinedSignature struct {
+	// Quorum is the number of valid shares the signature requires.
+	Quorum int `json:"quorum"`
+	// Shares are the signatures of the individual signers.
+	Shares []Share `json:"shares"`
+}
+
+// Share is the signature of a digest by one signer.
+type Share struct {
+	// Signer is the configured name of the signer.
+	Signer string `json:"signer"`
+	// KeyID identifies the public key of the signer: the first 16 bytes of
+	// the SHA-256 hash of its PKIX encoding, hex encoded.
+	KeyID string `json:"key_id"`
+	// Signature is the signature of the digest by the signer.
+	Signature []byte `json:"signature"`
+}
+
+// Verify checks that combined holds at least quorum valid signatures of
+// digest made by distinct keys among publicKeys. The quorum recorded in the
+// signature is not trusted.
+func Verify(combined, digest []byte, publicKeys []crypto.PublicKey, quorum int) error {
+	var sig CombinedSignature
+	if err := json.Unmarshal(combined, &sig); err != nil {
+		return fmt.Errorf("failed to decode combined signature: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(publicKeys))
+	for _, pub := range publicKeys {
+		id, err := keyID(pub)
+		if err != nil {
+			return err
+		}
+		keys[id] = pub
+	}
+
+	valid := map[string]bool{}
+	for _, share := range sig.Shares {
+		pub, ok := keys[share.KeyID]
+		if !ok || valid[share.KeyID] {
+			continue
+		}
+		if verifyShare(pub, digest, share.Signature) == nil {
+			valid[share.KeyID] = true
+		}
+	}
+	if len(valid) < quorum {
+		return fmt.Errorf("%d valid signature shares, %d required", len(valid), quorum)
+	}
+	return nil
+}
+
+// verifyShare checks the signature of digest by the key pub.
+func verifyShare(pub crypto.PublicKey, digest, signature []byte) error {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, digest, signature) {
+			return errors.New("invalid signature")
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest, signature) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return nil
+}
+
+// keyID returns the first 16 bytes of the SHA-256 hash of the PKIX encoding
+// of pub, hex encoded.
+func keyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// parsePublicKey decodes a PEM encoded PKIX public key.
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("not a PEM encoded PUBLIC KEY")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	switch pub.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+		return pub, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
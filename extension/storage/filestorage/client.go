@@ -167,6 +167,41 @@ func (c *fileStorageClient) Walk(ctx context.Context, fn storage.WalkFunc) error
 	}))
 }
 
+// ForEach calls fn for every entry of the storage in a read-only transaction.
+// Unlike Walk, it does not hold the write lock of the database, so writers are
+// not blocked during the iteration. The value passed to fn is only valid until
+// fn returns.
+func (c *fileStorageClient) ForEach(ctx context.Context, fn func(key string, value []byte) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.compactionMutex.RLock()
+	defer c.compactionMutex.RUnlock()
+
+	if c.closed {
+		return errors.New("storage is closed")
+	}
+
+	return normalizeStorageError(c.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(defaultBucket)
+		if bucket == nil {
+			return errors.New("storage not initialized")
+		}
+
+		cur := bucket.Cursor()
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(string(k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
 // updateBucket executes the specified operations in order for a given bucket. Get operation results are updated in place
 // The function caller must hold a read lock on compactionMutex.
 func updateBucket(bucket *bbolt.Bucket, ops ...*storage.Operation) error {
@@ -214,6 +249,12 @@ func (c *fileStorageClient) Close(_ context.Context) error {
 	return c.db.Close()
 }
 
+func (c *fileStorageClient) isClosed() bool {
+	c.compactionMutex.RLock()
+	defer c.compactionMutex.RUnlock()
+	return c.closed
+}
+
 // Compact database. Use temporary file as helper as we cannot replace database in-place
 func (c *fileStorageClient) Compact(compactionDirectory string, timeout time.Duration, maxTransactionSize int64) error {
 	var err error
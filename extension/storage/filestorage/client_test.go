@@ -1111,3 +1111,39 @@ func TestWalk(t *testing.T) {
 		require.Equal(t, map[string]string{"a": "val-a", "b": "val-b"}, got)
 	})
 }
+
+func TestForEach(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "db")
+	client, err := newTestClient(zap.NewNop(), dbFile, time.Second, 0, &CompactionConfig{})
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	require.NoError(t, client.Set(ctx, "b", []byte("2")))
+	require.NoError(t, client.Set(ctx, "a", []byte("1")))
+
+	entries := map[string]string{}
+	err = client.ForEach(ctx, func(key string, value []byte) error {
+		entries[key] = string(value)
+		if key == "a" {
+			// Writers are not blocked by the read-only iteration.
+			done := make(chan error)
+			go func() { done <- client.Set(ctx, "c", []byte("3")) }()
+			select {
+			case err := <-done:
+				require.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("write blocked by ForEach")
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	// The iteration sees the storage as it was when it started.
+	require.Equal(t, map[string]string{"a": "1", "b": "2"}, entries)
+
+	testErr := errors.New("cb")
+	require.ErrorIs(t, client.ForEach(ctx, func(string, []byte) error { return testErr }), testErr)
+
+	require.NoError(t, client.Close(ctx))
+	require.EqualError(t, client.ForEach(ctx, func(string, []byte) error { return nil }), "storage is closed")
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -24,6 +25,9 @@ import (
 type localFileStorage struct {
 	cfg    *Config
 	logger *zap.Logger
+
+	clientsMu sync.Mutex
+	clients   map[string]*fileStorageClient
 }
 
 // Ensure this storage extension implements the appropriate interface
@@ -44,8 +48,9 @@ func newLocalFileStorage(logger *zap.Logger, config *Config) (extension.Extensio
 		}
 	}
 	return &localFileStorage{
-		cfg:    config,
-		logger: logger,
+		cfg:     config,
+		logger:  logger,
+		clients: make(map[string]*fileStorageClient),
 	}, nil
 }
 
@@ -100,9 +105,31 @@ func (lfs *localFileStorage) GetClient(_ context.Context, kind component.Kind, e
 		}
 	}
 
+	lfs.clientsMu.Lock()
+	lfs.clients[rawName] = client
+	lfs.clientsMu.Unlock()
+
 	return client, nil
 }
 
+// OpenClients returns the clients handed out by this extension that have not
+// been closed yet, keyed by the name of the component owning them. It allows
+// debugging extensions to inspect the contents of the storage.
+func (lfs *localFileStorage) OpenClients() map[string]storage.Client {
+	lfs.clientsMu.Lock()
+	defer lfs.clientsMu.Unlock()
+
+	open := make(map[string]storage.Client, len(lfs.clients))
+	for name, client := range lfs.clients {
+		if client.isClosed() {
+			delete(lfs.clients, name)
+			continue
+		}
+		open[name] = client
+	}
+	return open
+}
+
 // createClientWithPanicRecovery attempts to create a client, and if recreate is enabled
 // and a panic occurs (typically due to database corruption), it will rename the file
 // and try again with a fresh database
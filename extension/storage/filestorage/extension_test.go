@@ -190,6 +190,32 @@ func TestTwoClientsWithDifferentNames(t *testing.T) {
 	require.Equal(t, myBytes2, data)
 }
 
+func TestOpenClients(t *testing.T) {
+	ctx := t.Context()
+	se := newTestExtension(t)
+	lfs := se.(*localFileStorage)
+	require.Empty(t, lfs.OpenClients())
+
+	client1, err := se.GetClient(ctx, component.KindReceiver, newTestEntity("my_component"), "foo")
+	require.NoError(t, err)
+	client2, err := se.GetClient(ctx, component.KindExporter, newTestEntity("my_component"), "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, client2.Close(ctx))
+	})
+
+	open := lfs.OpenClients()
+	require.Len(t, open, 2)
+	assert.Same(t, client1, open["receiver_nop_my_component_foo"])
+	assert.Same(t, client2, open["exporter_nop_my_component"])
+
+	// Closed clients are no longer reported
+	require.NoError(t, client1.Close(ctx))
+	open = lfs.OpenClients()
+	require.Len(t, open, 1)
+	assert.Contains(t, open, "exporter_nop_my_component")
+}
+
 func TestSanitize(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -0,0 +1,306 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package transitstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/transitstorage"
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+)
+
+// Encrypted values are stored as:
+//
+//	magic (4) | version (1) | wrapped key length (2) | wrapped key | nonce (12) | ciphertext
+//
+// where the wrapped key is the Transit ciphertext of the AES-256-GCM data key
+// the value was encrypted with.
+const (
+	envelopeVersion = 1
+	nonceSize       = 12
+)
+
+var envelopeMagic = []byte("OTTS")
+
+// client encrypts the values written to the wrapped client and decrypts the
+// values read from it. Keys are stored as they are.
+type client struct {
+	inner            storage.Client
+	keyring          *keyring
+	scope            string
+	allowUnencrypted bool
+}
+
+var _ storage.Client = (*client)(nil)
+
+func (c *client) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.inner.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return c.decrypt(ctx, key, value)
+}
+
+func (c *client) Set(ctx context.Context, key string, value []byte) error {
+	encrypted, err := c.encrypt(ctx, key, value)
+	if err != nil {
+		return err
+	}
+	return c.inner.Set(ctx, key, encrypted)
+}
+
+func (c *client) Delete(ctx context.Context, key string) error {
+	return c.inner.Delete(ctx, key)
+}
+
+func (c *client) Batch(ctx context.Context, ops ...*storage.Operation) error {
+	innerOps, err := c.encryptOps(ctx, ops)
+	if err != nil {
+		return err
+	}
+	if err := c.inner.Batch(ctx, innerOps...); err != nil {
+		return err
+	}
+	return c.decryptResults(ctx, ops, innerOps)
+}
+
+func (c *client) Close(ctx context.Context) error {
+	return c.inner.Close(ctx)
+}
+
+// encryptOps returns the operations to pass to the wrapped client, with the
+// values of Set operations encrypted.
+func (c *client) encryptOps(ctx context.Context, ops []*storage.Operation) ([]*storage.Operation, error) {
+	return c.encryptOpsWith(ops, func(key string, value []byte) ([]byte, error) {
+		return c.encrypt(ctx, key, value)
+	})
+}
+
+// encryptOpsWith returns the operations to pass to the wrapped client, with
+// the values of Set operations encrypted by encrypt.
+func (c *client) encryptOpsWith(ops []*storage.Operation, encrypt func(key string, value []byte) ([]byte, error)) ([]*storage.Operation, error) {
+	innerOps := make([]*storage.Operation, len(ops))
+	for i, op := range ops {
+		innerOp := &storage.Operation{Key: op.Key, Type: op.Type}
+		if op.Type == storage.Set && op.Value != nil {
+			encrypted, err := encrypt(op.Key, op.Value)
+			if err != nil {
+				return nil, err
+			}
+			innerOp.Value = encrypted
+		}
+		innerOps[i] = innerOp
+	}
+	return innerOps, nil
+}
+
+// decryptResults puts the decrypted results of the Get operations passed to
+// the wrapped client into the caller's operations.
+func (c *client) decryptResults(ctx context.Context, ops, innerOps []*storage.Operation) error {
+	for i, op := range ops {
+		if op.Type != storage.Get {
+			continue
+		}
+		value, err := c.decrypt(ctx, op.Key, innerOps[i].Value)
+		if err != nil {
+			return err
+		}
+		op.Value = value
+	}
+	return nil
+}
+
+func (c *client) encrypt(ctx context.Context, key string, value []byte) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	dk, err := c.keyring.currentKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.seal(dk, key, value)
+}
+
+// seal encrypts a value with the data key dk.
+func (c *client) seal(dk *dataKey, key string, value []byte) ([]byte, error) {
+	aead, err := newAEAD(dk.plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(envelopeMagic)+3+len(dk.wrapped)+nonceSize+len(value)+aead.Overhead())
+	out = append(out, envelopeMagic...)
+	out = append(out, envelopeVersion)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(dk.wrapped)))
+	out = append(out, dk.wrapped...)
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, value, c.additionalData(key)), nil
+}
+
+// envelope is a parsed encrypted value.
+type envelope struct {
+	wrapped           string
+	nonce, ciphertext []byte
+}
+
+// parse parses an encrypted value. It reports false for values stored
+// unencrypted, which are only accepted when allowed.
+func (c *client) parse(key string, data []byte) (envelope, bool, error) {
+	if !bytes.HasPrefix(data, envelopeMagic) {
+		if c.allowUnencrypted {
+			return envelope{}, false, nil
+		}
+		return envelope{}, false, fmt.Errorf("value of key %q is not encrypted", key)
+	}
+
+	rest := data[len(envelopeMagic):]
+	if len(rest) < 3 {
+		return envelope{}, false, fmt.Errorf("value of key %q is truncated", key)
+	}
+	if rest[0] != envelopeVersion {
+		return envelope{}, false, fmt.Errorf("value of key %q has unsupported envelope version %d", key, rest[0])
+	}
+	wrappedLen := int(binary.BigEndian.Uint16(rest[1:3]))
+	rest = rest[3:]
+	if len(rest) < wrappedLen+nonceSize {
+		return envelope{}, false, fmt.Errorf("value of key %q is truncated", key)
+	}
+	return envelope{
+		wrapped:    string(rest[:wrappedLen]),
+		nonce:      rest[wrappedLen : wrappedLen+nonceSize],
+		ciphertext: rest[wrappedLen+nonceSize:],
+	}, true, nil
+}
+
+func (c *client) decrypt(ctx context.Context, key string, data []byte) ([]byte, error) {
+	return c.decryptWith(key, data, func(wrapped string) ([]byte, error) {
+		return c.keyring.unwrap(ctx, wrapped)
+	})
+}
+
+// decryptWith decrypts a value, obtaining its data key from unwrap.
+func (c *client) decryptWith(key string, data []byte, unwrap func(wrapped string) ([]byte, error)) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	env, encrypted, err := c.parse(key, data)
+	if err != nil || !encrypted {
+		return data, err
+	}
+	dataKey, err := unwrap(env.wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, env.nonce, env.ciphertext, c.additionalData(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value of key %q: %w", key, err)
+	}
+	return plaintext, nil
+}
+
+// additionalData binds a ciphertext to the client and key it was stored
+// under, so values cannot be moved between keys or components unnoticed.
+func (c *client) additionalData(key string) []byte {
+	return []byte(c.scope + "\x00" + key)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// walkingClient is a client whose wrapped client supports iteration.
+type walkingClient struct {
+	*client
+	walker storage.Walker
+}
+
+var _ storage.Walker = (*walkingClient)(nil)
+
+// Walk iterates the wrapped client, passing decrypted values to fn and
+// encrypting the values of the Set operations it returns. The wrapped client
+// may hold a write transaction during its walk, so Transit is not called
+// from within it: the data keys of the stored values are unwrapped by a first
+// walk, and the values written are encrypted with the data key current when
+// the walk started.
+func (c *walkingClient) Walk(ctx context.Context, fn storage.WalkFunc) error {
+	wrapped := map[string]bool{}
+	err := c.walker.Walk(ctx, func(key string, value []byte) ([]*storage.Operation, error) {
+		if len(value) == 0 {
+			return nil, nil
+		}
+		env, encrypted, err := c.parse(key, value)
+		if encrypted {
+			wrapped[env.wrapped] = true
+		}
+		return nil, err
+	})
+	if err != nil {
+		return err
+	}
+	dataKeys := make(map[string][]byte, len(wrapped)+1)
+	for w := range wrapped {
+		if dataKeys[w], err = c.keyring.unwrap(ctx, w); err != nil {
+			return err
+		}
+	}
+	dk, err := c.keyring.currentKey(ctx)
+	if err != nil {
+		return err
+	}
+	dataKeys[dk.wrapped] = dk.plaintext
+
+	unwrap := func(w string) ([]byte, error) {
+		if dataKey, ok := dataKeys[w]; ok {
+			return dataKey, nil
+		}
+		// Written with a key issued after the first walk.
+		if dataKey, ok := c.keyring.cached(w); ok {
+			return dataKey, nil
+		}
+		return nil, errors.New("value written during the walk with an unknown data key")
+	}
+	seal := func(key string, value []byte) ([]byte, error) {
+		return c.seal(dk, key, value)
+	}
+	var ops, innerOps []*storage.Operation
+	err = c.walker.Walk(ctx, func(key string, value []byte) ([]*storage.Operation, error) {
+		plaintext, err := c.decryptWith(key, value, unwrap)
+		if err != nil {
+			return nil, err
+		}
+		returned, fnErr := fn(key, plaintext)
+		if fnErr != nil && !errors.Is(fnErr, storage.SkipAll) {
+			return nil, fnErr
+		}
+		encrypted, err := c.encryptOpsWith(returned, seal)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, returned...)
+		innerOps = append(innerOps, encrypted...)
+		return encrypted, fnErr
+	})
+	if err != nil {
+		return err
+	}
+	return c.decryptResults(ctx, ops, innerOps)
+}
@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package transitstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/transitstorage"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+// Config defines configuration for the Transit storage extension.
+type Config struct {
+	// Storage is the ID of the storage extension holding the encrypted
+	// values.
+	Storage component.ID `mapstructure:"storage"`
+
+	// ClientConfig configures the HTTP client of the OpenBao Transit
+	// secrets engine; endpoint is the address of the OpenBao server, for
+	// example https://openbao.example.com:8200. Vault's Transit engine
+	// exposes the same API.
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	// Token is the token used to authenticate, sent as X-Vault-Token.
+	Token configopaque.String `mapstructure:"token"`
+
+	// Namespace is the namespace of the mount, sent as X-Vault-Namespace.
+	// Optional.
+	Namespace string `mapstructure:"namespace"`
+
+	// MountPath is the path the Transit engine is mounted at. Default: "transit".
+	MountPath string `mapstructure:"mount_path"`
+
+	// KeyName is the name of the Transit key wrapping the data keys.
+	KeyName string `mapstructure:"key_name"`
+
+	// DataKeyTTL is how long a data key encrypts new values before a new one
+	// is requested from Transit. Default: 1h.
+	DataKeyTTL time.Duration `mapstructure:"data_key_ttl"`
+
+	// AllowUnencrypted returns values that were stored before encryption was
+	// enabled as they are, instead of failing to read them. They are
+	// encrypted when written again. Default: false.
+	AllowUnencrypted bool `mapstructure:"allow_unencrypted"`
+}
+
+// Validate checks the Config for invalid values.
+func (cfg *Config) Validate() error {
+	if cfg.Storage.Type().String() == "" {
+		return errors.New("storage must be specified")
+	}
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must be specified")
+	}
+	if cfg.MountPath == "" {
+		return errors.New("mount_path must be specified")
+	}
+	if cfg.KeyName == "" {
+		return errors.New("key_name must be specified")
+	}
+	if cfg.DataKeyTTL <= 0 {
+		return fmt.Errorf("data_key_ttl must be > 0, got %s", cfg.DataKeyTTL)
+	}
+	return nil
+}
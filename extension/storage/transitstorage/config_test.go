@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package transitstorage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/transitstorage/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr string
+	}{
+		{
+			id: component.NewID(metadata.Type),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.Storage = component.MustNewID("file_storage")
+				cfg.Endpoint = "https://openbao.example.com:8200"
+				cfg.KeyName = "otelcol-storage"
+				return cfg
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "all"),
+			expected: func() *Config {
+				clientConfig := confighttp.NewDefaultClientConfig()
+				clientConfig.Endpoint = "https://openbao.example.com:8200"
+				return &Config{
+					Storage:          component.MustNewID("redis_storage"),
+					ClientConfig:     clientConfig,
+					Token:            "s.token",
+					Namespace:        "audit",
+					MountPath:        "kms",
+					KeyName:          "otelcol-storage",
+					DataKeyTTL:       15 * time.Minute,
+					AllowUnencrypted: true,
+				}
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "nostorage"),
+			expectedErr: "storage must be specified",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "nokey"),
+			expectedErr: "key_name must be specified",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "ttl"),
+			expectedErr: "data_key_ttl must be > 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
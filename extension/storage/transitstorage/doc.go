@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package transitstorage wraps another storage extension and encrypts the
+// stored values with data keys issued by the OpenBao Transit secrets engine.
+package transitstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/transitstorage"
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package transitstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/transitstorage"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/extensioncapabilities"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/transitstorage/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/transit"
+)
+
+type transitStorage struct {
+	cfg       *Config
+	set       extension.Settings
+	telemetry *metadata.TelemetryBuilder
+	keyring   *keyring
+	storage   storage.Extension
+}
+
+var (
+	_ storage.Extension               = (*transitStorage)(nil)
+	_ extensioncapabilities.Dependent = (*transitStorage)(nil)
+)
+
+func newTransitStorage(cfg *Config, set extension.Settings) (*transitStorage, error) {
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+	return &transitStorage{
+		cfg:       cfg,
+		set:       set,
+		telemetry: telemetryBuilder,
+	}, nil
+}
+
+// Dependencies makes the wrapped storage extension start before this one.
+func (e *transitStorage) Dependencies() []component.ID {
+	return []component.ID{e.cfg.Storage}
+}
+
+func (e *transitStorage) Start(ctx context.Context, host component.Host) error {
+	ext, ok := host.GetExtensions()[e.cfg.Storage]
+	if !ok {
+		return fmt.Errorf("storage extension %q not found", e.cfg.Storage)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return fmt.Errorf("extension %q is not a storage extension", e.cfg.Storage)
+	}
+	e.storage = storageExt
+
+	client, err := e.cfg.ToClient(ctx, host.GetExtensions(), e.set.TelemetrySettings)
+	if err != nil {
+		return err
+	}
+	e.keyring = newKeyring(transit.NewClient(client, transit.Settings{
+		Endpoint:  e.cfg.Endpoint,
+		Token:     string(e.cfg.Token),
+		Namespace: e.cfg.Namespace,
+		MountPath: e.cfg.MountPath,
+		KeyName:   e.cfg.KeyName,
+	}), e.cfg, e.set.Logger, e.telemetry)
+	return nil
+}
+
+func (e *transitStorage) Shutdown(context.Context) error {
+	e.telemetry.Shutdown()
+	return nil
+}
+
+// GetClient returns a client of the wrapped storage extension encrypting the
+// values it stores.
+func (e *transitStorage) GetClient(ctx context.Context, kind component.Kind, id component.ID, name string) (storage.Client, error) {
+	inner, err := e.storage.GetClient(ctx, kind, id, name)
+	if err != nil {
+		return nil, err
+	}
+	c := &client{
+		inner:            inner,
+		keyring:          e.keyring,
+		scope:            fmt.Sprintf("%s/%s/%s", kind, id, name),
+		allowUnencrypted: e.cfg.AllowUnencrypted,
+	}
+	if walker, ok := inner.(storage.Walker); ok {
+		return &walkingClient{client: c, walker: walker}, nil
+	}
+	return c, nil
+}
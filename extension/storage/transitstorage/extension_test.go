@@ -0,0 +1,460 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package transitstorage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/transitstorage/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/transitstorage/internal/metadatatest"
+)
+
+// fakeTransit implements the datakey and decrypt endpoints of the Transit
+// secrets engine. Data keys are "wrapped" by base64 encoding them.
+type fakeTransit struct {
+	mu       sync.Mutex
+	version  int
+	datakeys int
+	decrypts int
+	// storage, when set, is checked not to be walked during calls.
+	storage *memClient
+}
+
+func newFakeTransit(t *testing.T) (*fakeTransit, string) {
+	f := &fakeTransit{version: 1}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv.URL
+}
+
+func (f *fakeTransit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("X-Vault-Token") != "s.token" {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		return
+	}
+	if f.storage != nil && f.storage.walking.Load() {
+		http.Error(w, `{"errors":["called during a walk"]}`, http.StatusInternalServerError)
+		return
+	}
+
+	var data map[string]any
+	switch r.URL.Path {
+	case "/v1/transit/datakey/plaintext/otelcol-storage":
+		f.datakeys++
+		key := make([]byte, 32)
+		_, _ = rand.Read(key)
+		data = map[string]any{
+			"plaintext":   base64.StdEncoding.EncodeToString(key),
+			"ciphertext":  fmt.Sprintf("vault:v%d:%s", f.version, base64.StdEncoding.EncodeToString(key)),
+			"key_version": f.version,
+		}
+	case "/v1/transit/decrypt/otelcol-storage":
+		f.decrypts++
+		var in struct {
+			Ciphertext string `json:"ciphertext"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		parts := strings.SplitN(in.Ciphertext, ":", 3)
+		data = map[string]any{"plaintext": parts[2]}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func (f *fakeTransit) calls() (datakeys, decrypts int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.datakeys, f.decrypts
+}
+
+// memStorage is a storage extension handing out a single in-memory client
+// supporting iteration, so tests can inspect what is stored.
+type memStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+	client *memClient
+}
+
+func (s *memStorage) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	return s.client, nil
+}
+
+type memClient struct {
+	data    map[string][]byte
+	walking atomic.Bool
+}
+
+func newMemClient() *memClient {
+	return &memClient{data: map[string][]byte{}}
+}
+
+func (c *memClient) Get(_ context.Context, key string) ([]byte, error) {
+	return c.data[key], nil
+}
+
+func (c *memClient) Set(_ context.Context, key string, value []byte) error {
+	c.data[key] = value
+	return nil
+}
+
+func (c *memClient) Delete(_ context.Context, key string) error {
+	delete(c.data, key)
+	return nil
+}
+
+func (c *memClient) Batch(ctx context.Context, ops ...*storage.Operation) error {
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value, _ = c.Get(ctx, op.Key)
+		case storage.Set:
+			_ = c.Set(ctx, op.Key, op.Value)
+		case storage.Delete:
+			_ = c.Delete(ctx, op.Key)
+		}
+	}
+	return nil
+}
+
+func (*memClient) Close(context.Context) error {
+	return nil
+}
+
+func (c *memClient) Walk(ctx context.Context, fn storage.WalkFunc) error {
+	c.walking.Store(true)
+	defer c.walking.Store(false)
+	keys := make([]string, 0, len(c.data))
+	for k := range c.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var ops []*storage.Operation
+	for _, k := range keys {
+		returned, err := fn(k, c.data[k])
+		ops = append(ops, returned...)
+		if errors.Is(err, storage.SkipAll) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return c.Batch(ctx, ops...)
+}
+
+var memStorageID = component.MustNewID("mem_storage")
+
+func newTestConfig(endpoint string) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = memStorageID
+	cfg.Endpoint = endpoint
+	cfg.Token = "s.token"
+	cfg.KeyName = "otelcol-storage"
+	return cfg
+}
+
+func startExtension(t *testing.T, cfg *Config, inner *memClient, set component.TelemetrySettings) *transitStorage {
+	t.Helper()
+	settings := extensiontest.NewNopSettings(metadata.Type)
+	settings.TelemetrySettings = set
+	ext, err := newTransitStorage(cfg, settings)
+	require.NoError(t, err)
+	host := storagetest.NewStorageHost().WithExtension(memStorageID, &memStorage{client: inner})
+	require.NoError(t, ext.Start(t.Context(), host))
+	t.Cleanup(func() {
+		require.NoError(t, ext.Shutdown(context.Background()))
+	})
+	return ext
+}
+
+func getClient(t *testing.T, ext *transitStorage) storage.Client {
+	t.Helper()
+	client, err := ext.GetClient(t.Context(), component.KindReceiver, component.MustNewID("filelog"), "")
+	require.NoError(t, err)
+	return client
+}
+
+func TestRoundTrip(t *testing.T) {
+	transit, endpoint := newFakeTransit(t)
+	inner := newMemClient()
+	client := getClient(t, startExtension(t, newTestConfig(endpoint), inner, componenttest.NewNopTelemetrySettings()))
+	ctx := t.Context()
+
+	require.NoError(t, client.Set(ctx, "offset", []byte("secret offset")))
+	require.NoError(t, client.Set(ctx, "checkpoint", []byte("secret checkpoint")))
+
+	stored := inner.data["offset"]
+	assert.True(t, strings.HasPrefix(string(stored), "OTTS"))
+	assert.NotContains(t, string(stored), "secret offset")
+	assert.Contains(t, string(stored), "vault:v1:")
+
+	value, err := client.Get(ctx, "offset")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret offset"), value)
+
+	value, err = client.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	require.NoError(t, client.Delete(ctx, "offset"))
+	assert.NotContains(t, inner.data, "offset")
+
+	// Both values share a data key, which is already known for reading.
+	datakeys, decrypts := transit.calls()
+	assert.Equal(t, 1, datakeys)
+	assert.Zero(t, decrypts)
+}
+
+func TestRestart(t *testing.T) {
+	transit, endpoint := newFakeTransit(t)
+	inner := newMemClient()
+	client := getClient(t, startExtension(t, newTestConfig(endpoint), inner, componenttest.NewNopTelemetrySettings()))
+	require.NoError(t, client.Set(t.Context(), "a", []byte("first")))
+	require.NoError(t, client.Set(t.Context(), "b", []byte("second")))
+
+	client = getClient(t, startExtension(t, newTestConfig(endpoint), inner, componenttest.NewNopTelemetrySettings()))
+	for key, expected := range map[string]string{"a": "first", "b": "second"} {
+		value, err := client.Get(t.Context(), key)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(expected), value)
+	}
+
+	// The data key is unwrapped once and cached.
+	_, decrypts := transit.calls()
+	assert.Equal(t, 1, decrypts)
+}
+
+func TestValueBoundToKey(t *testing.T) {
+	_, endpoint := newFakeTransit(t)
+	inner := newMemClient()
+	ext := startExtension(t, newTestConfig(endpoint), inner, componenttest.NewNopTelemetrySettings())
+	client := getClient(t, ext)
+
+	require.NoError(t, client.Set(t.Context(), "a", []byte("value")))
+	inner.data["b"] = inner.data["a"]
+	_, err := client.Get(t.Context(), "b")
+	assert.ErrorContains(t, err, `failed to decrypt value of key "b"`)
+
+	other, err := ext.GetClient(t.Context(), component.KindExporter, component.MustNewID("otlp"), "")
+	require.NoError(t, err)
+	_, err = other.Get(t.Context(), "a")
+	assert.ErrorContains(t, err, `failed to decrypt value of key "a"`)
+}
+
+func TestDataKeyRotation(t *testing.T) {
+	transit, endpoint := newFakeTransit(t)
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	inner := newMemClient()
+	ext := startExtension(t, newTestConfig(endpoint), inner, metadatatest.NewSettings(tel).TelemetrySettings)
+	now := time.Now()
+	ext.keyring.now = func() time.Time { return now }
+	client := getClient(t, ext)
+
+	require.NoError(t, client.Set(t.Context(), "a", []byte("first")))
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, client.Set(t.Context(), "b", []byte("second")))
+	datakeys, _ := transit.calls()
+	assert.Equal(t, 1, datakeys)
+
+	transit.mu.Lock()
+	transit.version = 2
+	transit.mu.Unlock()
+	now = now.Add(time.Hour)
+	require.NoError(t, client.Set(t.Context(), "c", []byte("third")))
+	datakeys, _ = transit.calls()
+	assert.Equal(t, 2, datakeys)
+	assert.Contains(t, string(inner.data["c"]), "vault:v2:")
+
+	for key, expected := range map[string]string{"a": "first", "b": "second", "c": "third"} {
+		value, err := client.Get(t.Context(), key)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(expected), value)
+	}
+
+	metadatatest.AssertEqualExtensionTransitStorageKeyVersion(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 2},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestBatch(t *testing.T) {
+	_, endpoint := newFakeTransit(t)
+	inner := newMemClient()
+	client := getClient(t, startExtension(t, newTestConfig(endpoint), inner, componenttest.NewNopTelemetrySettings()))
+
+	require.NoError(t, client.Batch(t.Context(),
+		storage.SetOperation("a", []byte("first")),
+		storage.SetOperation("b", []byte("second")),
+	))
+	assert.NotContains(t, string(inner.data["a"]), "first")
+
+	getA, getB := storage.GetOperation("a"), storage.GetOperation("b")
+	require.NoError(t, client.Batch(t.Context(), getA, storage.DeleteOperation("b"), getB))
+	assert.Equal(t, []byte("first"), getA.Value)
+	assert.Nil(t, getB.Value)
+}
+
+func TestWalk(t *testing.T) {
+	_, endpoint := newFakeTransit(t)
+	inner := newMemClient()
+	client := getClient(t, startExtension(t, newTestConfig(endpoint), inner, componenttest.NewNopTelemetrySettings()))
+	require.NoError(t, client.Set(t.Context(), "a", []byte("first")))
+	require.NoError(t, client.Set(t.Context(), "b", []byte("second")))
+
+	walker, ok := client.(storage.Walker)
+	require.True(t, ok)
+
+	seen := map[string]string{}
+	getA := storage.GetOperation("a")
+	require.NoError(t, walker.Walk(t.Context(), func(key string, value []byte) ([]*storage.Operation, error) {
+		seen[key] = string(value)
+		if key == "b" {
+			return []*storage.Operation{getA, storage.SetOperation("b", []byte("updated"))}, storage.SkipAll
+		}
+		return nil, nil
+	}))
+	assert.Equal(t, map[string]string{"a": "first", "b": "second"}, seen)
+	assert.Equal(t, []byte("first"), getA.Value)
+
+	assert.NotContains(t, string(inner.data["b"]), "updated")
+	value, err := client.Get(t.Context(), "b")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("updated"), value)
+}
+
+func TestWalkOutsideTransit(t *testing.T) {
+	transit, endpoint := newFakeTransit(t)
+	inner := newMemClient()
+	client := getClient(t, startExtension(t, newTestConfig(endpoint), inner, componenttest.NewNopTelemetrySettings()))
+	require.NoError(t, client.Set(t.Context(), "a", []byte("first")))
+
+	// A new extension has neither unwrapped nor issued data keys: Transit is
+	// called before walking the wrapped client, which may hold a transaction.
+	transit.storage = inner
+	client = getClient(t, startExtension(t, newTestConfig(endpoint), inner, componenttest.NewNopTelemetrySettings()))
+	walker := client.(storage.Walker)
+	require.NoError(t, walker.Walk(t.Context(), func(key string, value []byte) ([]*storage.Operation, error) {
+		assert.Equal(t, "a", key)
+		assert.Equal(t, []byte("first"), value)
+		return []*storage.Operation{storage.SetOperation("b", []byte("second"))}, nil
+	}))
+	transit.storage = nil
+
+	value, err := client.Get(t.Context(), "b")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), value)
+	datakeys, decrypts := transit.calls()
+	assert.Equal(t, 2, datakeys)
+	assert.Equal(t, 1, decrypts)
+}
+
+func TestWalkNotSupported(t *testing.T) {
+	_, endpoint := newFakeTransit(t)
+	cfg := newTestConfig(endpoint)
+	cfg.Storage = storagetest.NewStorageID("test")
+	ext, err := newTransitStorage(cfg, extensiontest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(t.Context(), storagetest.NewStorageHost().WithInMemoryStorageExtension("test")))
+
+	client := getClient(t, ext)
+	_, ok := client.(storage.Walker)
+	assert.False(t, ok)
+	require.NoError(t, client.Set(t.Context(), "a", []byte("value")))
+	value, err := client.Get(t.Context(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+}
+
+func TestUnencryptedValues(t *testing.T) {
+	_, endpoint := newFakeTransit(t)
+	inner := newMemClient()
+	inner.data["legacy"] = []byte("plaintext")
+
+	client := getClient(t, startExtension(t, newTestConfig(endpoint), inner, componenttest.NewNopTelemetrySettings()))
+	_, err := client.Get(t.Context(), "legacy")
+	assert.EqualError(t, err, `value of key "legacy" is not encrypted`)
+
+	cfg := newTestConfig(endpoint)
+	cfg.AllowUnencrypted = true
+	client = getClient(t, startExtension(t, cfg, inner, componenttest.NewNopTelemetrySettings()))
+	value, err := client.Get(t.Context(), "legacy")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plaintext"), value)
+}
+
+func TestTransitError(t *testing.T) {
+	_, endpoint := newFakeTransit(t)
+	cfg := newTestConfig(endpoint)
+	cfg.Token = "wrong"
+	client := getClient(t, startExtension(t, cfg, newMemClient(), componenttest.NewNopTelemetrySettings()))
+
+	err := client.Set(t.Context(), "a", []byte("value"))
+	assert.ErrorContains(t, err, "failed to generate data key: transit returned 403 Forbidden")
+}
+
+func TestStartErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		storage     component.ID
+		expectedErr string
+	}{
+		{
+			name:        "missing",
+			storage:     storagetest.NewStorageID("missing"),
+			expectedErr: `storage extension "test_storage/missing" not found`,
+		},
+		{
+			name:        "not storage",
+			storage:     storagetest.NewNonStorageID("plain"),
+			expectedErr: `extension "non_storage/plain" is not a storage extension`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig("http://localhost")
+			cfg.Storage = tt.storage
+			ext, err := newTransitStorage(cfg, extensiontest.NewNopSettings(metadata.Type))
+			require.NoError(t, err)
+			host := storagetest.NewStorageHost().WithNonStorageExtension("plain")
+			assert.EqualError(t, ext.Start(t.Context(), host), tt.expectedErr)
+		})
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	ext, err := newTransitStorage(newTestConfig("http://localhost"), extensiontest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+	assert.Equal(t, []component.ID{memStorageID}, ext.Dependencies())
+}
@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package transitstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/transitstorage"
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/transitstorage/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/transit"
+)
+
+// maxCachedKeys bounds the number of unwrapped data keys kept in memory.
+const maxCachedKeys = 1024
+
+// dataKey is a data key issued by Transit, along with its wrapped form
+// stored next to every value it encrypted.
+type dataKey struct {
+	plaintext []byte
+	wrapped   string
+	issued    time.Time
+}
+
+// keyring issues data keys with the Transit datakey endpoint and unwraps the
+// data keys of stored values with the decrypt endpoint. The current data key
+// is reused for the configured TTL and unwrapped keys are cached, so Transit
+// is only called once per data key rather than once per value.
+type keyring struct {
+	transit   *transit.Client
+	cfg       *Config
+	logger    *zap.Logger
+	telemetry *metadata.TelemetryBuilder
+	now       func() time.Time
+
+	// issueMu serializes requests for new data keys. mu guards the fields
+	// below it and is never held during a Transit call, so that cached keys
+	// are served while a new key is requested.
+	issueMu   sync.Mutex
+	mu        sync.Mutex
+	current   *dataKey
+	version   int64
+	unwrapped map[string][]byte
+}
+
+func newKeyring(client *transit.Client, cfg *Config, logger *zap.Logger, telemetry *metadata.TelemetryBuilder) *keyring {
+	return &keyring{
+		transit:   client,
+		cfg:       cfg,
+		logger:    logger,
+		telemetry: telemetry,
+		now:       time.Now,
+		unwrapped: map[string][]byte{},
+	}
+}
+
+// currentKey returns the data key new values are encrypted with, requesting
+// a new one from Transit when none was issued yet or the current one expired.
+func (k *keyring) currentKey(ctx context.Context) (*dataKey, error) {
+	if dk := k.validKey(); dk != nil {
+		return dk, nil
+	}
+	k.issueMu.Lock()
+	defer k.issueMu.Unlock()
+	// Another caller may have issued a key while this one waited.
+	if dk := k.validKey(); dk != nil {
+		return dk, nil
+	}
+
+	resp, err := k.transit.GenerateDataKey(ctx, 256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if resp.Version != k.version {
+		if k.version != 0 {
+			k.logger.Info("Transit key version changed",
+				zap.String("key_name", k.cfg.KeyName),
+				zap.Int64("previous_version", k.version),
+				zap.Int64("version", resp.Version))
+		}
+		k.version = resp.Version
+		k.telemetry.ExtensionTransitStorageKeyVersion.Record(ctx, resp.Version)
+	}
+	k.current = &dataKey{plaintext: resp.Plaintext, wrapped: resp.Ciphertext, issued: k.now()}
+	k.cache(resp.Ciphertext, resp.Plaintext)
+	return k.current, nil
+}
+
+// validKey returns the current data key if it has not expired.
+func (k *keyring) validKey() *dataKey {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.current != nil && k.now().Sub(k.current.issued) < k.cfg.DataKeyTTL {
+		return k.current
+	}
+	return nil
+}
+
+// unwrap returns the plaintext of a wrapped data key.
+func (k *keyring) unwrap(ctx context.Context, wrapped string) ([]byte, error) {
+	if plaintext, ok := k.cached(wrapped); ok {
+		return plaintext, nil
+	}
+	plaintext, err := k.transit.Decrypt(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	k.mu.Lock()
+	k.cache(wrapped, plaintext)
+	k.mu.Unlock()
+	return plaintext, nil
+}
+
+// cached returns the plaintext of a wrapped data key if it is cached.
+func (k *keyring) cached(wrapped string) ([]byte, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	plaintext, ok := k.unwrapped[wrapped]
+	return plaintext, ok
+}
+
+// cache remembers an unwrapped data key. The cache is cleared when full;
+// keys still in use are unwrapped again on their next read. Must be called
+// with k.mu held.
+func (k *keyring) cache(wrapped string, plaintext []byte) {
+	if len(k.unwrapped) >= maxCachedKeys {
+		clear(k.unwrapped)
+	}
+	k.unwrapped[wrapped] = plaintext
+}
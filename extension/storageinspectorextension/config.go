@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package storageinspectorextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storageinspectorextension"
+
+import (
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// Config defines configuration for the storage inspector extension.
+type Config struct {
+	confighttp.ServerConfig `mapstructure:",squash"`
+
+	// Path is the URL path the inspector page is served on. Default: "/debug/storagez".
+	Path string `mapstructure:"path"`
+
+	// Storages restricts the inspector to the listed storage extensions. When
+	// empty, every storage extension supporting introspection is shown.
+	Storages []component.ID `mapstructure:"storages"`
+
+	// FirstKeys is the number of keys listed for each client, in key order.
+	// Default: 10.
+	FirstKeys int `mapstructure:"first_keys"`
+}
+
+// Validate checks if the extension configuration is valid.
+func (cfg *Config) Validate() error {
+	if !strings.HasPrefix(cfg.Path, "/") {
+		return errors.New("path must start with /")
+	}
+	if cfg.FirstKeys < 0 {
+		return errors.New("first_keys must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package storageinspectorextension implements an extension serving debug
+// pages that show the live contents of storage extensions, per component.
+package storageinspectorextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storageinspectorextension"
@@ -0,0 +1,181 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package storageinspectorextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storageinspectorextension"
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.uber.org/zap"
+)
+
+//go:embed templates/storagez.html
+var storagezHTML string
+
+var storagezTemplate = template.Must(template.New("storagez").Parse(storagezHTML))
+
+type inspectorExtension struct {
+	config   *Config
+	settings extension.Settings
+	server   *http.Server
+	stopCh   chan struct{}
+
+	// storages holds the inspected extensions, sorted by ID.
+	storages []inspectedStorage
+}
+
+type inspectedStorage struct {
+	id           component.ID
+	introspector Introspector
+}
+
+// storageStats is the content rendered for a single storage extension.
+type storageStats struct {
+	ID      string        `json:"id"`
+	Clients []clientStats `json:"clients"`
+}
+
+func newInspectorExtension(cfg *Config, set extension.Settings) *inspectorExtension {
+	return &inspectorExtension{
+		config:   cfg,
+		settings: set,
+	}
+}
+
+func (e *inspectorExtension) Start(ctx context.Context, host component.Host) error {
+	storages, err := e.resolveStorages(host)
+	if err != nil {
+		return err
+	}
+	e.storages = storages
+
+	ln, err := e.config.ToListener(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", e.config.NetAddr.Endpoint, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(e.config.Path, e.handleStoragez)
+	e.server, err = e.config.ToServer(ctx, host.GetExtensions(), e.settings.TelemetrySettings, mux)
+	if err != nil {
+		_ = ln.Close()
+		return err
+	}
+
+	e.settings.Logger.Info("Starting storage inspector",
+		zap.String("endpoint", e.config.NetAddr.Endpoint), zap.Int("storages", len(storages)))
+
+	e.stopCh = make(chan struct{})
+	go func() {
+		defer close(e.stopCh)
+		if err := e.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(err))
+		}
+	}()
+	return nil
+}
+
+func (e *inspectorExtension) Shutdown(context.Context) error {
+	if e.server == nil {
+		return nil
+	}
+	err := e.server.Close()
+	if e.stopCh != nil {
+		<-e.stopCh
+	}
+	return err
+}
+
+// resolveStorages finds the storage extensions to inspect. Extensions listed
+// explicitly in the configuration must exist and support introspection; when
+// none are listed, every introspectable storage extension is used.
+func (e *inspectorExtension) resolveStorages(host component.Host) ([]inspectedStorage, error) {
+	extensions := host.GetExtensions()
+
+	var storages []inspectedStorage
+	if len(e.config.Storages) > 0 {
+		for _, id := range e.config.Storages {
+			ext, ok := extensions[id]
+			if !ok {
+				return nil, fmt.Errorf("storage extension %q not found", id)
+			}
+			introspector, ok := ext.(Introspector)
+			if !ok {
+				return nil, fmt.Errorf("extension %q does not support storage introspection", id)
+			}
+			storages = append(storages, inspectedStorage{id: id, introspector: introspector})
+		}
+	} else {
+		for id, ext := range extensions {
+			if _, ok := ext.(storage.Extension); !ok {
+				continue
+			}
+			introspector, ok := ext.(Introspector)
+			if !ok {
+				e.settings.Logger.Debug("storage extension does not support introspection", zap.Stringer("id", id))
+				continue
+			}
+			storages = append(storages, inspectedStorage{id: id, introspector: introspector})
+		}
+	}
+
+	slices.SortFunc(storages, func(a, b inspectedStorage) int {
+		return strings.Compare(a.id.String(), b.id.String())
+	})
+	return storages, nil
+}
+
+// collect inspects every open client of every storage extension.
+func (e *inspectorExtension) collect(ctx context.Context) []storageStats {
+	result := make([]storageStats, 0, len(e.storages))
+	for _, s := range e.storages {
+		clients := s.introspector.OpenClients()
+		names := make([]string, 0, len(clients))
+		for name := range clients {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		stats := storageStats{ID: s.id.String(), Clients: make([]clientStats, 0, len(names))}
+		for _, name := range names {
+			stats.Clients = append(stats.Clients, inspectClient(ctx, name, clients[name], e.config.FirstKeys))
+		}
+		result = append(result, stats)
+	}
+	return result
+}
+
+func (e *inspectorExtension) handleStoragez(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats := e.collect(r.Context())
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(stats); err != nil {
+			e.settings.Logger.Warn("failed to write storage statistics", zap.Error(err))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := storagezTemplate.Execute(w, stats); err != nil {
+		e.settings.Logger.Warn("failed to render storage inspector page", zap.Error(err))
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package storageinspectorextension
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storageinspectorextension/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/testutil"
+)
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+// introspectableStorage is a storage extension reporting a fixed set of clients.
+type introspectableStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+	clients map[string]storage.Client
+}
+
+func (*introspectableStorage) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	return storage.NewNopClient(), nil
+}
+
+func (s *introspectableStorage) OpenClients() map[string]storage.Client {
+	return s.clients
+}
+
+// plainStorage is a storage extension that does not support introspection.
+type plainStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func (*plainStorage) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	return storage.NewNopClient(), nil
+}
+
+var (
+	fileStorageID  = component.MustNewID("file_storage")
+	redisStorageID = component.MustNewID("redis_storage")
+)
+
+func newTestHost() *testHost {
+	return &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{
+			fileStorageID: &introspectableStorage{clients: map[string]storage.Client{
+				"exporter_otlp_sending_queue": newIterableClient(map[string][]byte{
+					"1":  []byte("first"),
+					"2":  []byte("second"),
+					"ri": []byte("1"),
+				}),
+				"receiver_filelog": storage.NewNopClient(),
+			}},
+			redisStorageID: &plainStorage{},
+		},
+	}
+}
+
+func startExtension(t *testing.T, cfg *Config, host component.Host) string {
+	t.Helper()
+	cfg.NetAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+
+	ext, err := NewFactory().Create(t.Context(), extensiontest.NewNopSettings(metadata.Type), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(t.Context(), host))
+	t.Cleanup(func() {
+		require.NoError(t, ext.Shutdown(context.Background()))
+	})
+	return "http://" + cfg.NetAddr.Endpoint + cfg.Path
+}
+
+func TestStoragezJSON(t *testing.T) {
+	url := startExtension(t, createDefaultConfig().(*Config), newTestHost())
+
+	resp, err := http.Get(url + "?format=json")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var stats []storageStats
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+	assert.Equal(t, []storageStats{{
+		ID: "file_storage",
+		Clients: []clientStats{
+			{
+				Name:         "exporter_otlp_sending_queue",
+				Keys:         3,
+				KeyBytes:     4,
+				ValueBytes:   12,
+				LargestKey:   "2",
+				LargestValue: 6,
+				FirstKeys:   []string{"1", "2", "ri"},
+			},
+			{
+				Name:       "receiver_filelog",
+				FirstKeys: []string{},
+				Error:      errNotIterable.Error(),
+			},
+		},
+	}}, stats)
+}
+
+func TestStoragezHTML(t *testing.T) {
+	url := startExtension(t, createDefaultConfig().(*Config), newTestHost())
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "<h2>file_storage</h2>")
+	assert.Contains(t, string(body), "exporter_otlp_sending_queue")
+	assert.Contains(t, string(body), "2 (6 bytes)")
+	assert.Contains(t, string(body), errNotIterable.Error())
+	assert.NotContains(t, string(body), "redis_storage")
+}
+
+func TestStoragezMethodNotAllowed(t *testing.T) {
+	url := startExtension(t, createDefaultConfig().(*Config), newTestHost())
+
+	resp, err := http.Post(url, "text/plain", http.NoBody)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestConfiguredStorages(t *testing.T) {
+	tests := []struct {
+		name        string
+		storages    []component.ID
+		expectedErr string
+	}{
+		{
+			name:     "introspectable",
+			storages: []component.ID{fileStorageID},
+		},
+		{
+			name:        "missing",
+			storages:    []component.ID{component.MustNewIDWithName("file_storage", "missing")},
+			expectedErr: `storage extension "file_storage/missing" not found`,
+		},
+		{
+			name:        "not introspectable",
+			storages:    []component.ID{redisStorageID},
+			expectedErr: `extension "redis_storage" does not support storage introspection`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Storages = tt.storages
+			ext := newInspectorExtension(cfg, extensiontest.NewNopSettings(metadata.Type))
+
+			storages, err := ext.resolveStorages(newTestHost())
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, storages, 1)
+			assert.Equal(t, fileStorageID, storages[0].id)
+		})
+	}
+}
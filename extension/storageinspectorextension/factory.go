@@ -0,0 +1,48 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package storageinspectorextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storageinspectorextension"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/confignet"
+	"go.opentelemetry.io/collector/extension"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storageinspectorextension/internal/metadata"
+)
+
+const (
+	defaultEndpoint  = "localhost:55684"
+	defaultPath      = "/debug/storagez"
+	defaultFirstKeys = 10
+)
+
+// NewFactory creates a factory for the storage inspector extension.
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		createExtension,
+		metadata.ExtensionStability,
+	)
+}
+
+func createDefaultConfig() component.Config {
+	serverConfig := confighttp.NewDefaultServerConfig()
+	serverConfig.NetAddr = confignet.AddrConfig{
+		Transport: confignet.TransportTypeTCP,
+		Endpoint:  defaultEndpoint,
+	}
+	return &Config{
+		ServerConfig: serverConfig,
+		Path:         defaultPath,
+		FirstKeys:    defaultFirstKeys,
+	}
+}
+
+func createExtension(_ context.Context, set extension.Settings, cfg component.Config) (extension.Extension, error) {
+	return newInspectorExtension(cfg.(*Config), set), nil
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package storageinspectorextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storageinspectorextension"
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+)
+
+// Introspector is implemented by storage extensions that can enumerate the
+// clients they have handed out to other components. The file_storage
+// extension implements it.
+type Introspector interface {
+	// OpenClients returns the clients that are currently open, keyed by a
+	// name identifying the component owning them.
+	OpenClients() map[string]storage.Client
+}
+
+// Iterator is implemented by storage clients that can enumerate their entries
+// in a read-only transaction. The file_storage extension implements it.
+// storage.Walker is not used: its implementations iterate within a write
+// transaction, which would block the owning component for the whole scan.
+type Iterator interface {
+	// ForEach calls fn for every entry. The value is only valid until fn
+	// returns.
+	ForEach(ctx context.Context, fn func(key string, value []byte) error) error
+}
+
+var errNotIterable = errors.New("storage client does not support read-only iteration")
+
+// clientStats summarizes the contents of a single storage client.
+type clientStats struct {
+	Name         string   `json:"name"`
+	Keys         int      `json:"keys"`
+	KeyBytes     int      `json:"key_bytes"`
+	ValueBytes   int      `json:"value_bytes"`
+	LargestKey   string   `json:"largest_key,omitempty"`
+	LargestValue int      `json:"largest_value_bytes"`
+	FirstKeys    []string `json:"first_keys"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// inspectClient iterates every entry of the client in a read-only transaction
+// and collects its statistics. At most maxFirst keys are kept in FirstKeys, in
+// key order.
+func inspectClient(ctx context.Context, name string, client storage.Client, maxFirst int) clientStats {
+	stats := clientStats{Name: name, FirstKeys: []string{}}
+
+	iterator, ok := client.(Iterator)
+	if !ok {
+		stats.Error = errNotIterable.Error()
+		return stats
+	}
+
+	err := iterator.ForEach(ctx, func(key string, value []byte) error {
+		stats.Keys++
+		stats.KeyBytes += len(key)
+		stats.ValueBytes += len(value)
+		if stats.Keys == 1 || len(value) > stats.LargestValue {
+			stats.LargestKey = key
+			stats.LargestValue = len(value)
+		}
+		stats.FirstKeys = keepFirst(stats.FirstKeys, key, maxFirst)
+		return nil
+	})
+	if err != nil {
+		stats.Error = err.Error()
+	}
+	return stats
+}
+
+// keepFirst inserts key into the sorted slice first, keeping only the first
+// limit keys in key order. Storage does not record when keys were written, so
+// these are only the oldest keys of components using increasing numeric keys.
+func keepFirst(first []string, key string, limit int) []string {
+	if limit == 0 {
+		return first
+	}
+	idx := sort.Search(len(first), func(i int) bool { return keyLess(key, first[i]) })
+	if idx >= limit {
+		return first
+	}
+	first = slices.Insert(first, idx, key)
+	if len(first) > limit {
+		first = first[:limit]
+	}
+	return first
+}
+
+// keyLess orders keys. Numeric keys, as used by the exporter persistent queue,
+// are compared by value and sort before any other key; other keys are
+// compared lexically.
+func keyLess(a, b string) bool {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		return an < bn
+	case aErr == nil:
+		return true
+	case bErr == nil:
+		return false
+	default:
+		return a < b
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package storageinspectorextension
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+)
+
+// iterableClient is an in-memory storage.Client that supports read-only
+// iteration.
+type iterableClient struct {
+	storage.Client
+	data    map[string][]byte
+	iterErr error
+}
+
+func newIterableClient(data map[string][]byte) *iterableClient {
+	return &iterableClient{Client: storage.NewNopClient(), data: data}
+}
+
+func (c *iterableClient) ForEach(_ context.Context, fn func(key string, value []byte) error) error {
+	if c.iterErr != nil {
+		return c.iterErr
+	}
+	keys := make([]string, 0, len(c.data))
+	for k := range c.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := fn(k, c.data[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestInspectClient(t *testing.T) {
+	client := newIterableClient(map[string][]byte{
+		"ri":       []byte("12"),
+		"wi":       []byte("15"),
+		"10":       []byte("tenth item"),
+		"9":        []byte("ninth item, larger than the others"),
+		"11":       []byte("eleventh"),
+		"__meta__": nil,
+	})
+
+	stats := inspectClient(t.Context(), "exporter_otlp", client, 3)
+	assert.Equal(t, clientStats{
+		Name:         "exporter_otlp",
+		Keys:         6,
+		KeyBytes:     17,
+		ValueBytes:   56,
+		LargestKey:   "9",
+		LargestValue: 34,
+		FirstKeys:   []string{"9", "10", "11"},
+	}, stats)
+}
+
+func TestInspectClientFirstKeys(t *testing.T) {
+	client := newIterableClient(map[string][]byte{"b": {1}, "a": {2}, "2": {3}})
+
+	stats := inspectClient(t.Context(), "receiver_filelog", client, 10)
+	assert.Equal(t, []string{"2", "a", "b"}, stats.FirstKeys)
+
+	stats = inspectClient(t.Context(), "receiver_filelog", client, 0)
+	assert.Equal(t, 3, stats.Keys)
+	assert.Empty(t, stats.FirstKeys)
+}
+
+func TestInspectClientErrors(t *testing.T) {
+	stats := inspectClient(t.Context(), "nop", storage.NewNopClient(), 10)
+	assert.Equal(t, errNotIterable.Error(), stats.Error)
+
+	client := newIterableClient(nil)
+	client.iterErr = errors.New("storage is closed")
+	stats = inspectClient(t.Context(), "closed", client, 10)
+	assert.Equal(t, "storage is closed", stats.Error)
+}
+
+func TestKeyLess(t *testing.T) {
+	assert.True(t, keyLess("9", "10"))
+	assert.False(t, keyLess("10", "9"))
+	assert.True(t, keyLess("10", "a"))
+	assert.False(t, keyLess("a", "10"))
+	assert.True(t, keyLess("a", "b"))
+	assert.False(t, keyLess("b", "b"))
+}
@@ -0,0 +1,233 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hashchain implements the hash chained files written by the WORM
+// file exporter. Every line of a file is an entry linking to the hash of the
+// previous one, and a rotated file ends with a seal. The chain continues
+// across rotated files: the first entry of a file links to the seal of the
+// previous file, the first entry of the first file to GenesisHash.
+package hashchain // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/hashchain"
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	EntryTypeLogs = "logs"
+	EntryTypeSeal = "seal"
+)
+
+// GenesisHash is the previous hash of the first entry of a chain.
+var GenesisHash = make([]byte, sha256.Size)
+
+// Entry is a line of a file. Hash covers the previous hash, the sequence
+// number, the type and the exact bytes of Data as written, see Hash.
+type Entry struct {
+	Seq  uint64          `json:"seq"`
+	Type string          `json:"type"`
+	Prev string          `json:"prev"`
+	Data json.RawMessage `json:"data"`
+	Hash string          `json:"hash"`
+}
+
+// Seal is the data of the last entry of a rotated file.
+type Seal struct {
+	// Entries is the number of entries in the file before the seal.
+	Entries  uint64    `json:"entries"`
+	SealedAt time.Time `json:"sealed_at"`
+}
+
+// Hash computes the hash of an entry: SHA-256 over the previous hash, the
+// big-endian sequence number, the type, a zero byte and the data bytes.
+func Hash(prev []byte, seq uint64, typ string, data []byte) []byte {
+	h := sha256.New()
+	h.Write(prev)
+	var seqBytes [8]byte
+	binary.BigEndian.PutUint64(seqBytes[:], seq)
+	h.Write(seqBytes[:])
+	h.Write([]byte(typ))
+	h.Write([]byte{0})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// State is a position in the chain.
+type State struct {
+	// Seq is the sequence number of the last entry, 0 before the first one.
+	Seq uint64
+	// Head is the hash of the last entry.
+	Head []byte
+	// Entries is the number of entries in the current file.
+	Entries uint64
+	// Sealed reports whether the current file ends with a seal.
+	Sealed bool
+}
+
+// Genesis returns the state before the first entry of a chain.
+func Genesis() State {
+	return State{Head: GenesisHash}
+}
+
+// Next returns the state at the start of the file following s: the chain
+// continues, the entry count starts over.
+func (s State) Next() State {
+	return State{Seq: s.Seq, Head: s.Head}
+}
+
+// Append formats the next entry of the chain and advances the state. The
+// line is built by hand rather than with encoding/json, which would re-encode
+// data and change the bytes covered by the hash.
+func (s *State) Append(typ string, data []byte) []byte {
+	prev := s.Head
+	s.Seq++
+	s.Head = Hash(prev, s.Seq, typ, data)
+	s.Entries++
+	s.Sealed = typ == EntryTypeSeal
+
+	line := make([]byte, 0, len(data)+220)
+	line = append(line, `{"seq":`...)
+	line = strconv.AppendUint(line, s.Seq, 10)
+	line = append(line, `,"type":"`...)
+	line = append(line, typ...)
+	line = append(line, `","prev":"`...)
+	line = hex.AppendEncode(line, prev)
+	line = append(line, `","data":`...)
+	line = append(line, data...)
+	line = append(line, `,"hash":"`...)
+	line = hex.AppendEncode(line, s.Head)
+	line = append(line, "\"}\n"...)
+	return line
+}
+
+// Verify reads a file and checks that every entry hash is correct, links to
+// the previous entry and follows its sequence number, and that a seal is only
+// found last and counts the entries before it. The first entry must continue
+// the chain at start: Genesis for the first file, the state at the seal of the
+// previous file otherwise. fn, when not nil, is called with every verified
+// entry. The returned state is the position reached, with the entries of the
+// file only.
+func Verify(r io.Reader, start State, fn func(Entry) error) (State, error) {
+	state := start.Next()
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		raw, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(raw) > 0 {
+				return state, fmt.Errorf("line %d: incomplete entry", line)
+			}
+			return state, nil
+		}
+		if err != nil {
+			return state, err
+		}
+		if state.Sealed {
+			return state, fmt.Errorf("line %d: entry after seal", line)
+		}
+
+		var e Entry
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&e); err != nil {
+			return state, fmt.Errorf("line %d: %w", line, err)
+		}
+		prev, err := hex.DecodeString(e.Prev)
+		if err != nil {
+			return state, fmt.Errorf("line %d: invalid prev: %w", line, err)
+		}
+		if !bytes.Equal(prev, state.Head) {
+			if line == 1 {
+				return state, errors.New("line 1: prev does not match the hash of the previous file's seal")
+			}
+			return state, fmt.Errorf("line %d: prev does not match the hash of the previous entry", line)
+		}
+		if e.Seq != state.Seq+1 {
+			return state, fmt.Errorf("line %d: sequence number %d does not follow %d", line, e.Seq, state.Seq)
+		}
+		hash := Hash(prev, e.Seq, e.Type, e.Data)
+		if hex.EncodeToString(hash) != e.Hash {
+			return state, fmt.Errorf("line %d: hash mismatch", line)
+		}
+
+		switch e.Type {
+		case EntryTypeLogs:
+		case EntryTypeSeal:
+			var sl Seal
+			if err := json.Unmarshal(e.Data, &sl); err != nil {
+				return state, fmt.Errorf("line %d: invalid seal: %w", line, err)
+			}
+			if sl.Entries != state.Entries {
+				return state, fmt.Errorf("line %d: seal counts %d entries, file has %d", line, sl.Entries, state.Entries)
+			}
+			state.Sealed = true
+		default:
+			return state, fmt.Errorf("line %d: unknown entry type %q", line, e.Type)
+		}
+		state.Seq = e.Seq
+		state.Head = hash
+		state.Entries++
+		if fn != nil {
+			if err := fn(e); err != nil {
+				return state, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+	}
+}
+
+// maxSealLength bounds the length of a seal entry, which holds no batch.
+const maxSealLength = 1024
+
+// ReadSeal returns the chain state at the seal ending a rotated file. Only
+// the seal is read and checked, the rest of the file is expected to have been
+// verified before it was sealed.
+func ReadSeal(f io.ReadSeeker) (State, error) {
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return State{}, err
+	}
+	offset := max(size-maxSealLength, 0)
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return State{}, err
+	}
+	tail, err := io.ReadAll(f)
+	if err != nil {
+		return State{}, err
+	}
+	raw, ok := bytes.CutSuffix(tail, []byte("\n"))
+	if !ok {
+		return State{}, errors.New("file does not end with a complete entry")
+	}
+	i := bytes.LastIndexByte(raw, '\n')
+	if i < 0 && offset > 0 {
+		return State{}, errors.New("last entry is not a seal")
+	}
+	raw = raw[i+1:]
+
+	var e Entry
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&e); err != nil {
+		return State{}, fmt.Errorf("invalid last entry: %w", err)
+	}
+	if e.Type != EntryTypeSeal {
+		return State{}, errors.New("last entry is not a seal")
+	}
+	prev, err := hex.DecodeString(e.Prev)
+	if err != nil {
+		return State{}, fmt.Errorf("invalid prev: %w", err)
+	}
+	hash := Hash(prev, e.Seq, e.Type, e.Data)
+	if hex.EncodeToString(hash) != e.Hash {
+		return State{}, errors.New("seal hash mismatch")
+	}
+	return State{Seq: e.Seq, Head: hash}, nil
+}
@@ -0,0 +1,158 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package hashchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildChain(t *testing.T, entries ...string) (string, State) {
+	t.Helper()
+	state := Genesis()
+	var buf bytes.Buffer
+	for _, e := range entries {
+		typ, data, ok := strings.Cut(e, " ")
+		require.True(t, ok)
+		buf.Write(state.Append(typ, []byte(data)))
+	}
+	return buf.String(), state
+}
+
+func TestAppend(t *testing.T) {
+	state := Genesis()
+	line := state.Append(EntryTypeLogs, []byte(`{"resourceLogs":[]}`))
+
+	hash := hex.EncodeToString(Hash(GenesisHash, 1, EntryTypeLogs, []byte(`{"resourceLogs":[]}`)))
+	assert.JSONEq(t, `{
+		"seq": 1,
+		"type": "logs",
+		"prev": "`+hex.EncodeToString(GenesisHash)+`",
+		"data": {"resourceLogs": []},
+		"hash": "`+hash+`"
+	}`, string(line))
+	assert.True(t, bytes.HasSuffix(line, []byte("\n")))
+	assert.Equal(t, uint64(1), state.Seq)
+	assert.Equal(t, uint64(1), state.Entries)
+	assert.Equal(t, hash, hex.EncodeToString(state.Head))
+}
+
+func TestVerify(t *testing.T) {
+	file, want := buildChain(t, `logs {"a":1}`, `logs {"b":2}`, `seal {"entries":2}`)
+
+	var types []string
+	state, err := Verify(strings.NewReader(file), Genesis(), func(e Entry) error {
+		types = append(types, e.Type)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want, state)
+	assert.True(t, state.Sealed)
+	assert.Equal(t, []string{EntryTypeLogs, EntryTypeLogs, EntryTypeSeal}, types)
+
+	_, err = Verify(strings.NewReader(file), Genesis(), func(Entry) error { return errors.New("rejected") })
+	assert.EqualError(t, err, "line 1: rejected")
+
+	// The next file continues from the seal.
+	next := want.Next()
+	var buf bytes.Buffer
+	buf.Write(next.Append(EntryTypeLogs, []byte(`{"c":3}`)))
+	state, err = Verify(&buf, want, nil)
+	require.NoError(t, err)
+	assert.Equal(t, want.Seq+1, state.Seq)
+	assert.Equal(t, uint64(1), state.Entries)
+
+	state, err = Verify(strings.NewReader(""), Genesis(), nil)
+	require.NoError(t, err)
+	assert.Zero(t, state.Entries)
+	assert.Equal(t, Genesis(), state)
+}
+
+func TestVerifyTampered(t *testing.T) {
+	file, _ := buildChain(t, `logs {"a":1}`, `logs {"b":2}`, `logs {"c":3}`)
+	lines := strings.SplitAfter(file, "\n")
+	sealed, _ := buildChain(t, `logs {"a":1}`, `seal {"entries":1}`)
+
+	tests := []struct {
+		name        string
+		file        string
+		expectedErr string
+	}{
+		{
+			name:        "modified data",
+			file:        strings.Replace(file, `{"b":2}`, `{"b":3}`, 1),
+			expectedErr: "line 2: hash mismatch",
+		},
+		{
+			name:        "removed first lines",
+			file:        lines[2],
+			expectedErr: "line 1: prev does not match the hash of the previous file's seal",
+		},
+		{
+			name:        "wrong seal count",
+			file:        strings.Replace(sealed, `{"entries":1}`, `{"entries":2}`, 1),
+			expectedErr: "line 2: hash mismatch",
+		},
+		{
+			name:        "removed line",
+			file:        lines[0] + lines[2],
+			expectedErr: "line 2: prev does not match the hash of the previous entry",
+		},
+		{
+			name:        "reordered lines",
+			file:        lines[0] + lines[2] + lines[1],
+			expectedErr: "line 2: prev does not match the hash of the previous entry",
+		},
+		{
+			name:        "truncated line",
+			file:        file[:len(file)-10],
+			expectedErr: "line 3: incomplete entry",
+		},
+		{
+			name:        "entry after seal",
+			file:        sealed + lines[1],
+			expectedErr: "line 3: entry after seal",
+		},
+		{
+			name:        "not an entry",
+			file:        "{\"message\":\"hello\"}\n",
+			expectedErr: `line 1: json: unknown field "message"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Verify(strings.NewReader(tt.file), Genesis(), nil)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+
+	// A seal counting the wrong number of entries is rejected even when its
+	// hash is consistent.
+	miscounted, _ := buildChain(t, `logs {"a":1}`, `seal {"entries":2}`)
+	_, err := Verify(strings.NewReader(miscounted), Genesis(), nil)
+	assert.EqualError(t, err, "line 2: seal counts 2 entries, file has 1")
+}
+
+func TestReadSeal(t *testing.T) {
+	file, want := buildChain(t, `logs {"a":1}`, `logs {"`+strings.Repeat("b", 2*maxSealLength)+`":2}`, `seal {"entries":2}`)
+	state, err := ReadSeal(strings.NewReader(file))
+	require.NoError(t, err)
+	assert.Equal(t, want.Next(), state)
+
+	unsealed, _ := buildChain(t, `logs {"a":1}`)
+	_, err = ReadSeal(strings.NewReader(unsealed))
+	assert.EqualError(t, err, "last entry is not a seal")
+
+	_, err = ReadSeal(strings.NewReader(strings.Replace(file, `"entries":2`, `"entries":3`, 1)))
+	assert.EqualError(t, err, "seal hash mismatch")
+
+	_, err = ReadSeal(strings.NewReader(file[:len(file)-1]))
+	assert.EqualError(t, err, "file does not end with a complete entry")
+}
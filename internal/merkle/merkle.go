@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package merkle implements the Merkle trees of RFC 6962 section 2.1, so that
+// roots and inclusion proofs can be verified with any Certificate
+// Transparency style tooling: leaves and interior nodes are hashed with
+// distinct prefixes, and a tree is split at the largest power of two smaller
+// than its number of leaves.
+package merkle // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/merkle"
+
+import (
+	"crypto/sha256"
+	"math/bits"
+)
+
+const (
+	leafHashPrefix = 0x00
+	nodeHashPrefix = 0x01
+)
+
+// HashLeaf returns the hash of a leaf, MTH({d(0)}) in RFC 6962.
+func HashLeaf(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafHashPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func hashNode(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodeHashPrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Root returns the root of the tree over leaves, MTH(D[n]) in RFC 6962.
+func Root(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		d := sha256.Sum256(nil)
+		return d[:]
+	case 1:
+		return HashLeaf(leaves[0])
+	}
+	k := splitPoint(len(leaves))
+	return hashNode(Root(leaves[:k]), Root(leaves[k:]))
+}
+
+// AuditPath returns the inclusion proof of the leaf at index m, from the leaf
+// up, PATH(m, D[n]) in RFC 6962 section 2.1.1.
+func AuditPath(m int, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := splitPoint(len(leaves))
+	if m < k {
+		return append(AuditPath(m, leaves[:k]), Root(leaves[k:]))
+	}
+	return append(AuditPath(m-k, leaves[k:]), Root(leaves[:k]))
+}
+
+// RootFromAuditPath computes the root of a tree of n leaves from the leaf at
+// index m and its audit path, walking PATH(m, D[n]) back up. It reports false
+// when the path does not have the length of an audit path of m.
+func RootFromAuditPath(leaf []byte, m, n int, path [][]byte) ([]byte, bool) {
+	if m < 0 || m >= n {
+		return nil, false
+	}
+	fn, sn := m, n-1
+	r := HashLeaf(leaf)
+	for _, p := range path {
+		if sn == 0 {
+			return nil, false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = hashNode(p, r)
+			if fn&1 == 0 {
+				for fn&1 == 0 && fn != 0 {
+					fn >>= 1
+					sn >>= 1
+				}
+			}
+		} else {
+			r = hashNode(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return r, sn == 0
+}
+
+// splitPoint returns the largest power of two smaller than n, n > 1.
+func splitPoint(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
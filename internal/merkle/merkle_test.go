@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRoot uses the RFC 6962 test vectors of the Certificate Transparency
+// implementations.
+func TestRoot(t *testing.T) {
+	leaves := [][]byte{}
+	for _, l := range []string{"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f"} {
+		b, err := hex.DecodeString(l)
+		require.NoError(t, err)
+		leaves = append(leaves, b)
+	}
+	roots := []string{
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+	for n, root := range roots {
+		assert.Equal(t, root, hex.EncodeToString(Root(leaves[:n])), "tree of %d leaves", n)
+	}
+
+	// RFC 6962 audit path of leaf 0 in the tree of 8 leaves.
+	path := AuditPath(0, leaves)
+	require.Len(t, path, 3)
+	assert.Equal(t, "96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7", hex.EncodeToString(path[0]))
+}
+
+func testLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		sum := sha256.Sum256(fmt.Appendf(nil, "record %d", i))
+		leaves[i] = sum[:]
+	}
+	return leaves
+}
+
+func TestAuditPath(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		leaves := testLeaves(n)
+		root := Root(leaves)
+		for m := range n {
+			path := AuditPath(m, leaves)
+			computed, ok := RootFromAuditPath(leaves[m], m, n, path)
+			require.True(t, ok, "n=%d m=%d", n, m)
+			require.Equal(t, hex.EncodeToString(root), hex.EncodeToString(computed), "n=%d m=%d", n, m)
+		}
+	}
+}
+
+func TestRootFromAuditPathRejects(t *testing.T) {
+	leaves := testLeaves(7)
+	root := Root(leaves)
+	path := AuditPath(3, leaves)
+
+	computed, ok := RootFromAuditPath(leaves[2], 3, 7, path)
+	assert.True(t, ok)
+	assert.NotEqual(t, root, computed)
+
+	computed, ok = RootFromAuditPath(leaves[3], 2, 7, path)
+	assert.True(t, ok)
+	assert.NotEqual(t, root, computed)
+
+	_, ok = RootFromAuditPath(leaves[3], 3, 7, path[:len(path)-1])
+	assert.False(t, ok)
+	_, ok = RootFromAuditPath(leaves[3], 3, 7, append(path, root))
+	assert.False(t, ok)
+	_, ok = RootFromAuditPath(leaves[3], 7, 7, path)
+	assert.False(t, ok)
+	_, ok = RootFromAuditPath(leaves[0], 1, 1, nil)
+	assert.False(t, ok)
+}
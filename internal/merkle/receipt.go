@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package merkle // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/merkle"
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Receipt records where the ledger anchor exporter anchored a Merkle root,
+// together with the leaves of the tree so that inclusion proofs can be built
+// for any of them. Leaves are hex encoded.
+type Receipt struct {
+	Root          string    `json:"root"`
+	Backend       string    `json:"backend"`
+	TransactionID string    `json:"transaction_id"`
+	AnchoredAt    time.Time `json:"anchored_at"`
+	Leaves        []string  `json:"leaves"`
+}
+
+// RecordLeaf returns the Merkle leaf the ledger anchor exporter computes for a
+// record: the value of hashAttribute when set and present on the record, the
+// SHA-256 hash of the protobuf encoding of a batch holding only the record,
+// its resource and its scope otherwise.
+func RecordLeaf(rl plog.ResourceLogs, sl plog.ScopeLogs, lr plog.LogRecord, hashAttribute string) ([]byte, error) {
+	if hashAttribute != "" {
+		if v, ok := lr.Attributes().Get(hashAttribute); ok {
+			switch v.Type() {
+			case pcommon.ValueTypeBytes:
+				return v.Bytes().AsRaw(), nil
+			case pcommon.ValueTypeStr:
+				if b, err := hex.DecodeString(v.Str()); err == nil {
+					return b, nil
+				}
+				return []byte(v.Str()), nil
+			}
+		}
+	}
+
+	single := plog.NewLogs()
+	srl := single.ResourceLogs().AppendEmpty()
+	rl.Resource().CopyTo(srl.Resource())
+	srl.SetSchemaUrl(rl.SchemaUrl())
+	ssl := srl.ScopeLogs().AppendEmpty()
+	sl.Scope().CopyTo(ssl.Scope())
+	ssl.SetSchemaUrl(sl.SchemaUrl())
+	lr.CopyTo(ssl.LogRecords().AppendEmpty())
+
+	data, err := (&plog.ProtoMarshaler{}).MarshalLogs(single)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode log record: %w", err)
+	}
+	d := sha256.Sum256(data)
+	return d[:], nil
+}
@@ -0,0 +1,45 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package merkle
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestRecordLeaf(t *testing.T) {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "audit")
+	sl := rl.ScopeLogs().AppendEmpty()
+	first := sl.LogRecords().AppendEmpty()
+	first.Body().SetStr("login")
+	first.Attributes().PutStr("hash", "0a0b")
+	second := sl.LogRecords().AppendEmpty()
+	second.Body().SetStr("logout")
+	second.Attributes().PutStr("hash", "not hex")
+
+	leaf, err := RecordLeaf(rl, sl, first, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x0a, 0x0b}, leaf)
+	leaf, err = RecordLeaf(rl, sl, second, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("not hex"), leaf)
+
+	// Without the attribute, the record is hashed in a batch of its own.
+	single := plog.NewLogs()
+	srl := single.ResourceLogs().AppendEmpty()
+	srl.Resource().Attributes().PutStr("service.name", "audit")
+	second.CopyTo(srl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty())
+	data, err := (&plog.ProtoMarshaler{}).MarshalLogs(single)
+	require.NoError(t, err)
+	want := sha256.Sum256(data)
+	leaf, err = RecordLeaf(rl, sl, second, "")
+	require.NoError(t, err)
+	assert.Equal(t, want[:], leaf)
+}
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package transit implements a client of the OpenBao Transit secrets engine.
+// Vault's Transit engine exposes the same API.
+package transit // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/transit"
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Settings defines how to reach a Transit key.
+type Settings struct {
+	// Endpoint is the address of the OpenBao server, for example
+	// https://openbao.example.com:8200.
+	Endpoint string
+	// Token is the token used to authenticate, sent as X-Vault-Token.
+	Token string
+	// Namespace is the namespace of the mount, sent as X-Vault-Namespace.
+	// Optional.
+	Namespace string
+	// MountPath is the path the Transit engine is mounted at.
+	MountPath string
+	// KeyName is the name of the Transit key.
+	KeyName string
+}
+
+// Client calls the Transit endpoints of a single key. It is safe for
+// concurrent use.
+type Client struct {
+	client   *http.Client
+	settings Settings
+}
+
+// NewClient returns a Client sending its requests with client.
+func NewClient(client *http.Client, settings Settings) *Client {
+	return &Client{client: client, settings: settings}
+}
+
+// DataKey is a data key issued by Transit.
+type DataKey struct {
+	// Plaintext is the data key.
+	Plaintext []byte
+	// Ciphertext is the data key wrapped by the Transit key, which
+	// Decrypt unwraps.
+	Ciphertext string
+	// Version is the version of the Transit key that wrapped the data key.
+	Version int64
+}
+
+// Encrypt encrypts plaintext with the Transit key and returns the
+// "vault:v<version>:" prefixed ciphertext.
+func (c *Client) Encrypt(ctx context.Context, plaintext []byte) (string, error) {
+	var out struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, "encrypt", map[string]any{"plaintext": base64.StdEncoding.EncodeToString(plaintext)}, &out); err != nil {
+		return "", err
+	}
+	if out.Data.Ciphertext == "" {
+		return "", errors.New("transit returned no ciphertext")
+	}
+	return out.Data.Ciphertext, nil
+}
+
+// Decrypt decrypts a ciphertext returned by Encrypt, or unwraps the
+// ciphertext of a data key.
+func (c *Client) Decrypt(ctx context.Context, ciphertext string) ([]byte, error) {
+	var out struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, "decrypt", map[string]any{"ciphertext": ciphertext}, &out); err != nil {
+		return nil, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(out.Data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plaintext: %w", err)
+	}
+	return plaintext, nil
+}
+
+// GenerateDataKey requests a new data key of the given size in bits,
+// returned both in plaintext and wrapped by the Transit key.
+func (c *Client) GenerateDataKey(ctx context.Context, bits int) (DataKey, error) {
+	var out struct {
+		Data struct {
+			Plaintext  string `json:"plaintext"`
+			Ciphertext string `json:"ciphertext"`
+			KeyVersion int64  `json:"key_version"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, "datakey/plaintext", map[string]any{"bits": bits}, &out); err != nil {
+		return DataKey{}, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(out.Data.Plaintext)
+	if err != nil {
+		return DataKey{}, fmt.Errorf("invalid data key: %w", err)
+	}
+	if len(plaintext) != bits/8 {
+		return DataKey{}, fmt.Errorf("invalid data key length %d", len(plaintext))
+	}
+	if out.Data.Ciphertext == "" {
+		return DataKey{}, errors.New("transit returned no wrapped data key")
+	}
+	version := out.Data.KeyVersion
+	if version == 0 {
+		// Older servers only report the version in the ciphertext prefix.
+		var ok bool
+		if version, ok = KeyVersion(out.Data.Ciphertext); !ok {
+			return DataKey{}, errors.New("invalid wrapped data key version")
+		}
+	}
+	return DataKey{Plaintext: plaintext, Ciphertext: out.Data.Ciphertext, Version: version}, nil
+}
+
+// KeyVersion parses the key version from a "vault:v<version>:" prefixed
+// ciphertext. It reports false when the ciphertext has no such prefix.
+func KeyVersion(ciphertext string) (int64, bool) {
+	parts := strings.SplitN(ciphertext, ":", 3)
+	if len(parts) != 3 || !strings.HasPrefix(parts[1], "v") {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(parts[1][1:], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+func (c *Client) do(ctx context.Context, operation string, in, out any) error {
+	url := fmt.Sprintf("%s/v1/%s/%s/%s",
+		strings.TrimSuffix(c.settings.Endpoint, "/"), strings.Trim(c.settings.MountPath, "/"), operation, c.settings.KeyName)
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.settings.Token != "" {
+		req.Header.Set("X-Vault-Token", c.settings.Token)
+	}
+	if c.settings.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.settings.Namespace)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("transit returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode transit response: %w", err)
+	}
+	return nil
+}
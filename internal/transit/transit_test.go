@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package transit
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServer starts a fake Transit engine mounted at "transit" with a key
+// named "audit". Its ciphertexts are the base64 plaintext behind a
+// "vault:v1:" prefix.
+func newServer(t *testing.T, dataKey map[string]any) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "s.token", r.Header.Get("X-Vault-Token"))
+		assert.Equal(t, "ns1", r.Header.Get("X-Vault-Namespace"))
+		var req map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var data map[string]any
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/audit":
+			data = map[string]any{"ciphertext": "vault:v1:" + req["plaintext"].(string)}
+		case "/v1/transit/decrypt/audit":
+			ciphertext := req["ciphertext"].(string)
+			if !strings.HasPrefix(ciphertext, "vault:v1:") {
+				http.Error(w, `{"errors":["invalid ciphertext"]}`, http.StatusBadRequest)
+				return
+			}
+			data = map[string]any{"plaintext": strings.TrimPrefix(ciphertext, "vault:v1:")}
+		case "/v1/transit/datakey/plaintext/audit":
+			assert.Equal(t, float64(256), req["bits"])
+			data = dataKey
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"data": data}))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(srv *httptest.Server) *Client {
+	return NewClient(srv.Client(), Settings{
+		Endpoint:  srv.URL + "/",
+		Token:     "s.token",
+		Namespace: "ns1",
+		MountPath: "/transit/",
+		KeyName:   "audit",
+	})
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	c := newTestClient(newServer(t, nil))
+
+	ciphertext, err := c.Encrypt(t.Context(), []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "vault:v1:"+base64.StdEncoding.EncodeToString([]byte("secret")), ciphertext)
+
+	plaintext, err := c.Decrypt(t.Context(), ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), plaintext)
+
+	_, err = c.Decrypt(t.Context(), "forged")
+	assert.EqualError(t, err, `transit returned 400 Bad Request: {"errors":["invalid ciphertext"]}`)
+}
+
+func TestGenerateDataKey(t *testing.T) {
+	key := make([]byte, 32)
+	encoded := base64.StdEncoding.EncodeToString(key)
+
+	tests := []struct {
+		name        string
+		response    map[string]any
+		expected    DataKey
+		expectedErr string
+	}{
+		{
+			name:     "version reported",
+			response: map[string]any{"plaintext": encoded, "ciphertext": "vault:v3:wrapped", "key_version": 3},
+			expected: DataKey{Plaintext: key, Ciphertext: "vault:v3:wrapped", Version: 3},
+		},
+		{
+			name:     "version from ciphertext",
+			response: map[string]any{"plaintext": encoded, "ciphertext": "vault:v2:wrapped"},
+			expected: DataKey{Plaintext: key, Ciphertext: "vault:v2:wrapped", Version: 2},
+		},
+		{
+			name:        "no version",
+			response:    map[string]any{"plaintext": encoded, "ciphertext": "wrapped"},
+			expectedErr: "invalid wrapped data key version",
+		},
+		{
+			name:        "short key",
+			response:    map[string]any{"plaintext": base64.StdEncoding.EncodeToString(key[:16]), "ciphertext": "vault:v1:wrapped"},
+			expectedErr: "invalid data key length 16",
+		},
+		{
+			name:        "not wrapped",
+			response:    map[string]any{"plaintext": encoded},
+			expectedErr: "transit returned no wrapped data key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(newServer(t, tt.response))
+			dk, err := c.GenerateDataKey(t.Context(), 256)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, dk)
+		})
+	}
+}
+
+func TestKeyVersion(t *testing.T) {
+	version, ok := KeyVersion("vault:v12:abc:def")
+	assert.True(t, ok)
+	assert.Equal(t, int64(12), version)
+
+	for _, ciphertext := range []string{"abc", "vault:vx:abc", "vault:v0:abc", "vault:1:abc"} {
+		_, ok = KeyVersion(ciphertext)
+		assert.False(t, ok, ciphertext)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package antireplayprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/antireplayprocessor"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+const (
+	// ActionDrop removes replayed records from the pipeline.
+	ActionDrop = "drop"
+	// ActionFlag keeps replayed records and marks them with FlagAttribute.
+	ActionFlag = "flag"
+)
+
+// Config defines configuration for the anti-replay processor.
+type Config struct {
+	// Attributes are the log record attribute keys whose values identify a
+	// record, typically its signature or nonce. Records missing any of them are
+	// passed through unchecked.
+	Attributes []string `mapstructure:"attributes"`
+
+	// Window is how long a record identity is remembered. A record carrying an
+	// identity seen within the window is a duplicate. Default: 24h.
+	Window time.Duration `mapstructure:"window"`
+
+	// RejectStale treats records whose timestamp is older than Window as
+	// replays, since their identity may already have been forgotten. Records
+	// without a timestamp are never stale. Default: false.
+	RejectStale bool `mapstructure:"reject_stale"`
+
+	// Action is what happens to replayed records: "drop" removes them, "flag"
+	// sets FlagAttribute to the reason ("duplicate" or "stale"). Default: drop.
+	Action string `mapstructure:"action"`
+
+	// FlagAttribute is the attribute set on replayed records when Action is
+	// "flag". Default: "log.record.replay".
+	FlagAttribute string `mapstructure:"flag_attribute"`
+
+	// MaxEntries bounds the number of remembered identities. When the limit is
+	// reached the oldest identity is forgotten. 0 means unlimited. Default: 100000.
+	MaxEntries int `mapstructure:"max_entries"`
+
+	// Storage is the ID of a storage extension used to persist remembered
+	// identities across restarts. The identities remembered from every batch
+	// are written to storage before the batch is passed on.
+	// Optional — when unset, replays are only detected within a single run.
+	Storage *component.ID `mapstructure:"storage"`
+
+	// SaveInterval is the interval at which the remembered identities are
+	// saved as a single snapshot, replacing the identities written per batch
+	// since the previous snapshot. 0 only saves a snapshot on shutdown.
+	// Default: 1m.
+	SaveInterval time.Duration `mapstructure:"save_interval"`
+}
+
+// Validate checks the Config for invalid values.
+func (cfg *Config) Validate() error {
+	if len(cfg.Attributes) == 0 {
+		return errors.New("attributes must not be empty")
+	}
+	for _, attr := range cfg.Attributes {
+		if attr == "" {
+			return errors.New("attributes must not contain empty keys")
+		}
+	}
+	if cfg.Window <= 0 {
+		return fmt.Errorf("window must be > 0, got %s", cfg.Window)
+	}
+	switch cfg.Action {
+	case ActionDrop:
+	case ActionFlag:
+		if cfg.FlagAttribute == "" {
+			return errors.New("flag_attribute must be set when action is flag")
+		}
+	default:
+		return fmt.Errorf("action must be %q or %q, got %q", ActionDrop, ActionFlag, cfg.Action)
+	}
+	if cfg.MaxEntries < 0 {
+		return fmt.Errorf("max_entries must be >= 0, got %d", cfg.MaxEntries)
+	}
+	if cfg.SaveInterval < 0 {
+		return fmt.Errorf("save_interval must be >= 0, got %s", cfg.SaveInterval)
+	}
+	return nil
+}
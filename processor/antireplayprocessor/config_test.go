@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package antireplayprocessor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/antireplayprocessor/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	storageID := component.MustNewID("file_storage")
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr string
+	}{
+		{
+			id: component.NewID(metadata.Type),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.Attributes = []string{"signature"}
+				return cfg
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "custom"),
+			expected: func() *Config {
+				return &Config{
+					Attributes:    []string{"signature", "nonce"},
+					Window:        time.Hour,
+					RejectStale:   true,
+					Action:        ActionFlag,
+					FlagAttribute: "replay",
+					MaxEntries:    1000,
+					Storage:       &storageID,
+					SaveInterval:  30 * time.Second,
+				}
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "noattributes"),
+			expectedErr: "attributes must not be empty",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalidaction"),
+			expectedErr: `action must be "drop" or "flag", got "reject"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	validCfg := func() *Config {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Attributes = []string{"signature"}
+		return cfg
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			mutate: func(*Config) {},
+		},
+		{
+			name:    "empty attribute key",
+			mutate:  func(c *Config) { c.Attributes = []string{"signature", ""} },
+			wantErr: "attributes must not contain empty keys",
+		},
+		{
+			name:    "zero window",
+			mutate:  func(c *Config) { c.Window = 0 },
+			wantErr: "window must be > 0",
+		},
+		{
+			name: "flag without attribute",
+			mutate: func(c *Config) {
+				c.Action = ActionFlag
+				c.FlagAttribute = ""
+			},
+			wantErr: "flag_attribute must be set when action is flag",
+		},
+		{
+			name:    "negative max_entries",
+			mutate:  func(c *Config) { c.MaxEntries = -1 },
+			wantErr: "max_entries must be >= 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validCfg()
+			tt.mutate(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package antireplayprocessor implements a processor that remembers the
+// signatures or nonces of recently seen log records and drops or flags records
+// that are replayed within a configurable window.
+package antireplayprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/antireplayprocessor"
@@ -0,0 +1,231 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package antireplayprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/antireplayprocessor"
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/antireplayprocessor/internal/metadata"
+)
+
+const (
+	reasonDuplicate = "duplicate"
+	reasonStale     = "stale"
+)
+
+var (
+	attrReasonDuplicate = metric.WithAttributes(attribute.String("reason", reasonDuplicate))
+	attrReasonStale     = metric.WithAttributes(attribute.String("reason", reasonStale))
+)
+
+type antiReplayProcessor struct {
+	config      *Config
+	componentID component.ID
+	logger      *zap.Logger
+	telemetry   *metadata.TelemetryBuilder
+	now         func() time.Time
+
+	// mu guards the remembered identities and their storage, so that the
+	// identities of a batch are persisted before the next batch is checked.
+	mu   sync.Mutex
+	seen *seenSet
+	// journalStart is the sequence of the first journal entry not included
+	// in the stored snapshot, journalNext the sequence of the next one.
+	journalStart uint64
+	journalNext  uint64
+
+	storageClient storage.Client
+	stopSave      context.CancelFunc // cancels periodic save goroutine
+	saveWG        sync.WaitGroup
+}
+
+func newAntiReplayProcessor(set processor.Settings, cfg *Config) (*antiReplayProcessor, error) {
+	tel, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &antiReplayProcessor{
+		config:      cfg,
+		componentID: set.ID,
+		logger:      set.Logger,
+		telemetry:   tel,
+		now:         time.Now,
+		seen:        newSeenSet(cfg.MaxEntries),
+	}, nil
+}
+
+// Start loads remembered identities from storage and starts the periodic save
+// goroutine.
+func (p *antiReplayProcessor) Start(ctx context.Context, host component.Host) error {
+	if p.config.Storage == nil {
+		return nil
+	}
+
+	var err error
+	p.storageClient, err = getStorageClient(ctx, host, p.config.Storage, p.componentID)
+	if err != nil {
+		return fmt.Errorf("failed to get storage client: %w", err)
+	}
+	if err = p.loadSnapshot(ctx); err != nil {
+		return err
+	}
+
+	if p.config.SaveInterval > 0 {
+		p.startPeriodicSave()
+	}
+	return nil
+}
+
+// Shutdown stops the periodic save goroutine, performs a final snapshot save,
+// and closes the storage client.
+func (p *antiReplayProcessor) Shutdown(ctx context.Context) error {
+	if p.stopSave != nil {
+		p.stopSave()
+	}
+	p.saveWG.Wait()
+
+	var errs []error
+	if p.storageClient != nil {
+		if err := p.saveSnapshot(ctx); err != nil {
+			p.logger.Warn("final snapshot save failed", zap.Error(err))
+			errs = append(errs, err)
+		}
+		if err := p.storageClient.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// processLogs is the ConsumeLogs handler passed to processorhelper.NewLogs.
+// With storage, the identities remembered from the batch are persisted before
+// it is passed on; when that fails they are forgotten again and the batch is
+// rejected.
+func (p *antiReplayProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	now := p.now()
+	staleBefore := now.Add(-p.config.Window).UnixNano()
+
+	p.mu.Lock()
+	var reasons []string
+	var remembered []seenEntry
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		sls := ld.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				reason, entry := p.check(lrs.At(k), now, staleBefore)
+				reasons = append(reasons, reason)
+				if entry != nil {
+					remembered = append(remembered, *entry)
+				}
+			}
+		}
+	}
+	if p.storageClient != nil && len(remembered) > 0 {
+		if err := p.writeJournal(ctx, remembered); err != nil {
+			for _, e := range remembered {
+				p.seen.forget(e)
+			}
+			p.mu.Unlock()
+			return ld, err
+		}
+	}
+	entries := p.seen.len()
+	p.mu.Unlock()
+
+	p.telemetry.ProcessorAntiReplayEntries.Record(ctx, int64(entries))
+
+	next := 0
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				reason := reasons[next]
+				next++
+				if reason == "" {
+					return false
+				}
+				p.recordReplay(ctx, reason)
+				if p.config.Action == ActionDrop {
+					return true
+				}
+				lr.Attributes().PutStr(p.config.FlagAttribute, reason)
+				return false
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+
+	if ld.ResourceLogs().Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+// check returns why lr is a replay, or an empty string when it is not. Records
+// that are not replays are remembered and the new entry is returned. Must be
+// called with p.mu held.
+func (p *antiReplayProcessor) check(lr plog.LogRecord, now time.Time, staleBefore int64) (string, *seenEntry) {
+	digest, ok := p.identity(lr)
+	if !ok {
+		return "", nil
+	}
+	if ts := int64(lr.Timestamp()); p.config.RejectStale && ts != 0 && ts < staleBefore {
+		return reasonStale, nil
+	}
+	expires := now.Add(p.config.Window).UnixNano()
+	if p.seen.observe(digest, now.UnixNano(), expires) {
+		return reasonDuplicate, nil
+	}
+	return "", &seenEntry{digest: digest, expires: expires}
+}
+
+// identity hashes the values of the configured attributes. It returns false
+// when any of them is missing.
+func (p *antiReplayProcessor) identity(lr plog.LogRecord) ([sha256.Size]byte, bool) {
+	h := sha256.New()
+	var length [8]byte
+	for _, key := range p.config.Attributes {
+		v, ok := lr.Attributes().Get(key)
+		if !ok {
+			return [sha256.Size]byte{}, false
+		}
+		// Prefix every value with its type and length so that distinct value
+		// tuples never produce the same byte stream: the int 1 and the string
+		// "1" have the same string representation.
+		s := v.AsString()
+		binary.BigEndian.PutUint64(length[:], uint64(len(s)))
+		h.Write([]byte{byte(v.Type())})
+		h.Write(length[:])
+		h.Write([]byte(s))
+	}
+	var digest [sha256.Size]byte
+	h.Sum(digest[:0])
+	return digest, true
+}
+
+func (p *antiReplayProcessor) recordReplay(ctx context.Context, reason string) {
+	attrs := attrReasonDuplicate
+	if reason == reasonStale {
+		attrs = attrReasonStale
+	}
+	p.telemetry.ProcessorAntiReplayLogRecordsReplayed.Add(ctx, 1, attrs)
+	p.logger.Debug("replayed log record detected", zap.String("reason", reason))
+}
@@ -0,0 +1,200 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package antireplayprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/antireplayprocessor/internal/metadatatest"
+)
+
+var testNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Attributes = []string{"signature", "nonce"}
+	cfg.Window = time.Hour
+	return cfg
+}
+
+func newTestProcessor(t *testing.T, cfg *Config) *antiReplayProcessor {
+	t.Helper()
+	p, err := newAntiReplayProcessor(metadatatest.NewSettings(componenttest.NewTelemetry()), cfg)
+	require.NoError(t, err)
+	p.now = func() time.Time { return testNow }
+	return p
+}
+
+type testRecord struct {
+	signature string
+	nonce     string
+	timestamp time.Time
+}
+
+func makeLogs(records ...testRecord) plog.Logs {
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, r := range records {
+		lr := lrs.AppendEmpty()
+		lr.Body().SetStr("user logged in")
+		if r.signature != "" {
+			lr.Attributes().PutStr("signature", r.signature)
+		}
+		if r.nonce != "" {
+			lr.Attributes().PutStr("nonce", r.nonce)
+		}
+		if !r.timestamp.IsZero() {
+			lr.SetTimestamp(pcommon.NewTimestampFromTime(r.timestamp))
+		}
+	}
+	return ld
+}
+
+func signatures(ld plog.Logs) []string {
+	var out []string
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < lrs.Len(); i++ {
+		v, _ := lrs.At(i).Attributes().Get("signature")
+		out = append(out, v.Str())
+	}
+	return out
+}
+
+func TestDropsDuplicates(t *testing.T) {
+	p := newTestProcessor(t, testConfig())
+
+	out, err := p.processLogs(t.Context(), makeLogs(
+		testRecord{signature: "sig-1", nonce: "n1"},
+		testRecord{signature: "sig-2", nonce: "n1"},
+		testRecord{signature: "sig-1", nonce: "n1"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sig-1", "sig-2"}, signatures(out))
+
+	out, err = p.processLogs(t.Context(), makeLogs(
+		testRecord{signature: "sig-2", nonce: "n1"},
+		testRecord{signature: "sig-2", nonce: "n2"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sig-2"}, signatures(out))
+}
+
+func TestIdentityIncludesValueType(t *testing.T) {
+	p := newTestProcessor(t, testConfig())
+
+	ld := makeLogs(
+		testRecord{signature: "sig-1"},
+		testRecord{signature: "sig-1"},
+		testRecord{signature: "sig-1"},
+	)
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	lrs.At(0).Attributes().PutStr("nonce", "1")
+	lrs.At(1).Attributes().PutInt("nonce", 1)
+	lrs.At(2).Attributes().PutInt("nonce", 1)
+
+	out, err := p.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, 2, out.LogRecordCount())
+}
+
+func TestDropsWholeBatchOfReplays(t *testing.T) {
+	p := newTestProcessor(t, testConfig())
+	ld := makeLogs(testRecord{signature: "sig-1", nonce: "n1"})
+
+	_, err := p.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+
+	_, err = p.processLogs(t.Context(), makeLogs(testRecord{signature: "sig-1", nonce: "n1"}))
+	assert.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+}
+
+func TestPassesRecordsWithoutIdentity(t *testing.T) {
+	p := newTestProcessor(t, testConfig())
+	ld := makeLogs(
+		testRecord{signature: "sig-1"},
+		testRecord{signature: "sig-1"},
+	)
+
+	out, err := p.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, 2, out.LogRecordCount())
+	assert.Equal(t, 0, p.seen.len())
+}
+
+func TestForgetsAfterWindow(t *testing.T) {
+	p := newTestProcessor(t, testConfig())
+
+	_, err := p.processLogs(t.Context(), makeLogs(testRecord{signature: "sig-1", nonce: "n1"}))
+	require.NoError(t, err)
+
+	p.now = func() time.Time { return testNow.Add(time.Hour) }
+	out, err := p.processLogs(t.Context(), makeLogs(testRecord{signature: "sig-1", nonce: "n1"}))
+	require.NoError(t, err)
+	assert.Equal(t, 1, out.LogRecordCount())
+}
+
+func TestRejectStale(t *testing.T) {
+	cfg := testConfig()
+	cfg.RejectStale = true
+	cfg.Action = ActionFlag
+	p := newTestProcessor(t, cfg)
+
+	out, err := p.processLogs(t.Context(), makeLogs(
+		testRecord{signature: "fresh", nonce: "n1", timestamp: testNow.Add(-time.Minute)},
+		testRecord{signature: "stale", nonce: "n1", timestamp: testNow.Add(-2 * time.Hour)},
+		testRecord{signature: "untimed", nonce: "n1"},
+		testRecord{signature: "fresh", nonce: "n1", timestamp: testNow.Add(-time.Minute)},
+	))
+	require.NoError(t, err)
+
+	lrs := out.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 4, lrs.Len())
+	var flags []string
+	for i := 0; i < lrs.Len(); i++ {
+		v, _ := lrs.At(i).Attributes().Get("log.record.replay")
+		flags = append(flags, v.Str())
+	}
+	assert.Equal(t, []string{"", reasonStale, "", reasonDuplicate}, flags)
+	// Stale records are not remembered.
+	assert.Equal(t, 2, p.seen.len())
+}
+
+func TestTelemetry(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	cfg := testConfig()
+	cfg.RejectStale = true
+	p, err := newAntiReplayProcessor(metadatatest.NewSettings(tel), cfg)
+	require.NoError(t, err)
+	p.now = func() time.Time { return testNow }
+
+	_, err = p.processLogs(t.Context(), makeLogs(
+		testRecord{signature: "sig-1", nonce: "n1"},
+		testRecord{signature: "sig-1", nonce: "n1"},
+		testRecord{signature: "sig-1", nonce: "n1"},
+		testRecord{signature: "sig-2", nonce: "n1", timestamp: testNow.Add(-2 * time.Hour)},
+	))
+	require.NoError(t, err)
+
+	metadatatest.AssertEqualProcessorAntiReplayLogRecordsReplayed(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 2, Attributes: attribute.NewSet(attribute.String("reason", reasonDuplicate))},
+		{Value: 1, Attributes: attribute.NewSet(attribute.String("reason", reasonStale))},
+	}, metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualProcessorAntiReplayEntries(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 1},
+	}, metricdatatest.IgnoreTimestamp())
+}
@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package antireplayprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/antireplayprocessor"
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	snapshotVersion   = 1
+	snapshotEntrySize = sha256.Size + 8
+)
+
+type seenEntry struct {
+	digest  [sha256.Size]byte
+	expires int64 // unix nanoseconds
+}
+
+// seenSet remembers record identities until they expire. Identities are kept
+// in insertion order, which is also expiry order since every identity is
+// remembered for the same window, so expiry and eviction only ever pop from
+// the front of the queue. seenSet is not safe for concurrent use.
+type seenSet struct {
+	maxEntries int
+	expires    map[[sha256.Size]byte]int64
+	queue      []seenEntry
+	head       int
+}
+
+func newSeenSet(maxEntries int) *seenSet {
+	return &seenSet{
+		maxEntries: maxEntries,
+		expires:    map[[sha256.Size]byte]int64{},
+	}
+}
+
+// len returns the number of remembered identities.
+func (s *seenSet) len() int {
+	return len(s.expires)
+}
+
+// observe records digest as seen until expires and reports whether it was
+// already remembered at now.
+func (s *seenSet) observe(digest [sha256.Size]byte, now, expires int64) bool {
+	s.expire(now)
+	if _, ok := s.expires[digest]; ok {
+		return true
+	}
+	s.add(seenEntry{digest: digest, expires: expires})
+	return false
+}
+
+// add remembers e, evicting the oldest identities when the set is full.
+func (s *seenSet) add(e seenEntry) {
+	for s.maxEntries > 0 && s.len() >= s.maxEntries {
+		s.pop()
+	}
+	s.expires[e.digest] = e.expires
+	s.queue = append(s.queue, e)
+}
+
+// forget undoes the observation that remembered e. Its queue entry is left
+// in place and ignored when popped.
+func (s *seenSet) forget(e seenEntry) {
+	if s.expires[e.digest] == e.expires {
+		delete(s.expires, e.digest)
+	}
+}
+
+// expire forgets every identity that expired at or before now.
+func (s *seenSet) expire(now int64) {
+	for s.head < len(s.queue) && s.queue[s.head].expires <= now {
+		s.pop()
+	}
+}
+
+func (s *seenSet) pop() {
+	if e := s.queue[s.head]; s.expires[e.digest] == e.expires {
+		delete(s.expires, e.digest)
+	}
+	s.queue[s.head] = seenEntry{}
+	s.head++
+	// Reclaim the consumed prefix once it makes up half of the queue.
+	if s.head > len(s.queue)/2 {
+		s.queue = append(s.queue[:0], s.queue[s.head:]...)
+		s.head = 0
+	}
+}
+
+// marshal encodes the remembered identities, oldest first.
+func (s *seenSet) marshal() []byte {
+	entries := make([]seenEntry, 0, s.len())
+	for _, e := range s.queue[s.head:] {
+		if s.expires[e.digest] == e.expires {
+			entries = append(entries, e)
+		}
+	}
+	return marshalEntries(entries)
+}
+
+// marshalEntries encodes entries in the snapshot format.
+func marshalEntries(entries []seenEntry) []byte {
+	data := make([]byte, 1, 1+len(entries)*snapshotEntrySize)
+	data[0] = snapshotVersion
+	for _, e := range entries {
+		data = append(data, e.digest[:]...)
+		data = binary.BigEndian.AppendUint64(data, uint64(e.expires))
+	}
+	return data
+}
+
+// unmarshal replaces the remembered identities with the ones encoded in data,
+// skipping those that expired at or before now.
+func (s *seenSet) unmarshal(data []byte, now int64) error {
+	s.expires = map[[sha256.Size]byte]int64{}
+	s.queue = s.queue[:0]
+	s.head = 0
+	return s.merge(data, now)
+}
+
+// merge adds the identities encoded in data to the remembered ones, skipping
+// those that expired at or before now.
+func (s *seenSet) merge(data []byte, now int64) error {
+	if len(data) == 0 {
+		return errors.New("empty snapshot")
+	}
+	if data[0] != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", data[0])
+	}
+	data = data[1:]
+	if len(data)%snapshotEntrySize != 0 {
+		return fmt.Errorf("snapshot size %d is not a multiple of %d", len(data), snapshotEntrySize)
+	}
+
+	for ; len(data) > 0; data = data[snapshotEntrySize:] {
+		var e seenEntry
+		copy(e.digest[:], data)
+		e.expires = int64(binary.BigEndian.Uint64(data[sha256.Size:]))
+		if e.expires <= now {
+			continue
+		}
+		if _, ok := s.expires[e.digest]; ok {
+			continue
+		}
+		s.add(e)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package antireplayprocessor
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func digestOf(s string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(s))
+}
+
+func TestSeenSetObserve(t *testing.T) {
+	s := newSeenSet(0)
+
+	assert.False(t, s.observe(digestOf("a"), 0, 10))
+	assert.False(t, s.observe(digestOf("b"), 5, 15))
+	assert.True(t, s.observe(digestOf("a"), 9, 19))
+	assert.Equal(t, 2, s.len())
+
+	// "a" expires at 10 and is forgotten, "b" is still remembered.
+	assert.False(t, s.observe(digestOf("a"), 10, 20))
+	assert.True(t, s.observe(digestOf("b"), 10, 20))
+	assert.Equal(t, 2, s.len())
+}
+
+func TestSeenSetMaxEntries(t *testing.T) {
+	s := newSeenSet(2)
+
+	assert.False(t, s.observe(digestOf("a"), 0, 100))
+	assert.False(t, s.observe(digestOf("b"), 1, 101))
+	assert.False(t, s.observe(digestOf("c"), 2, 102))
+	assert.Equal(t, 2, s.len())
+
+	// The oldest identity was evicted to make room for "c".
+	assert.False(t, s.observe(digestOf("a"), 3, 103))
+	assert.True(t, s.observe(digestOf("a"), 4, 104))
+}
+
+func TestSeenSetMarshalRoundTrip(t *testing.T) {
+	s := newSeenSet(0)
+	s.observe(digestOf("a"), 0, 10)
+	s.observe(digestOf("b"), 5, 15)
+	s.observe(digestOf("c"), 6, 16)
+
+	restored := newSeenSet(0)
+	require.NoError(t, restored.unmarshal(s.marshal(), 12))
+
+	// "a" had already expired when the snapshot was loaded.
+	assert.Equal(t, 2, restored.len())
+	assert.False(t, restored.observe(digestOf("a"), 12, 22))
+	assert.True(t, restored.observe(digestOf("b"), 12, 22))
+	assert.True(t, restored.observe(digestOf("c"), 12, 22))
+}
+
+func TestSeenSetUnmarshalErrors(t *testing.T) {
+	s := newSeenSet(0)
+	assert.EqualError(t, s.unmarshal([]byte{}, 0), "empty snapshot")
+	assert.EqualError(t, s.unmarshal([]byte{2}, 0), "unsupported snapshot version 2")
+	assert.EqualError(t, s.unmarshal([]byte{snapshotVersion, 1, 2, 3}, 0), "snapshot size 3 is not a multiple of 40")
+}
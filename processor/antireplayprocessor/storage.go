@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package antireplayprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/antireplayprocessor"
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	storageKey       = "seen_records"
+	journalStartKey  = "seen_records_journal_start"
+	journalKeyPrefix = "seen_records_journal/"
+)
+
+// getStorageClient resolves a storage.Client for the processor.
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", storageID)
+	}
+
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", storageID)
+	}
+
+	return storageExt.GetClient(ctx, component.KindProcessor, componentID, "")
+}
+
+// loadSnapshot restores remembered identities from the snapshot and the
+// journal written since. A missing or unreadable snapshot is logged and the
+// processor starts with the identities of the journal only.
+func (p *antiReplayProcessor) loadSnapshot(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now().UnixNano()
+
+	start, err := p.storageClient.Get(ctx, journalStartKey)
+	if err != nil {
+		return fmt.Errorf("failed to read journal position from storage: %w", err)
+	}
+	if len(start) == 8 {
+		p.journalStart = binary.BigEndian.Uint64(start)
+	}
+
+	data, err := p.storageClient.Get(ctx, storageKey)
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to read snapshot from storage: %w", err)
+	case len(data) > 0:
+		if err := p.seen.unmarshal(data, now); err != nil {
+			p.logger.Warn("failed to load snapshot, starting from the journal", zap.Error(err))
+			p.seen = newSeenSet(p.config.MaxEntries)
+		}
+	}
+
+	for p.journalNext = p.journalStart; ; p.journalNext++ {
+		data, err := p.storageClient.Get(ctx, journalKey(p.journalNext))
+		if err != nil {
+			return fmt.Errorf("failed to read journal from storage: %w", err)
+		}
+		if data == nil {
+			break
+		}
+		if err := p.seen.merge(data, now); err != nil {
+			p.logger.Warn("skipping unreadable journal entry", zap.Uint64("sequence", p.journalNext), zap.Error(err))
+		}
+	}
+	if p.seen.len() > 0 {
+		p.logger.Info("loaded seen records from storage", zap.Int("entries", p.seen.len()))
+	}
+	return nil
+}
+
+// writeJournal persists the identities remembered from a batch. Must be called
+// with p.mu held.
+func (p *antiReplayProcessor) writeJournal(ctx context.Context, entries []seenEntry) error {
+	if err := p.storageClient.Set(ctx, journalKey(p.journalNext), marshalEntries(entries)); err != nil {
+		return fmt.Errorf("failed to write seen records to storage: %w", err)
+	}
+	p.journalNext++
+	return nil
+}
+
+// startPeriodicSave launches a background goroutine that saves a snapshot of
+// the remembered identities at the configured interval.
+func (p *antiReplayProcessor) startPeriodicSave() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.stopSave = cancel
+
+	p.saveWG.Go(func() {
+		ticker := time.NewTicker(p.config.SaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := p.saveSnapshot(ctx); err != nil {
+					p.logger.Warn("periodic snapshot save failed", zap.Error(err))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// saveSnapshot writes the remembered identities to storage and removes the
+// journal they replace, in a single batch. The write is skipped when the
+// journal is empty.
+func (p *antiReplayProcessor) saveSnapshot(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.journalNext == p.journalStart {
+		return nil
+	}
+
+	data := p.seen.marshal()
+	ops := []*storage.Operation{
+		storage.SetOperation(storageKey, data),
+		storage.SetOperation(journalStartKey, binary.BigEndian.AppendUint64(nil, p.journalNext)),
+	}
+	for seq := p.journalStart; seq < p.journalNext; seq++ {
+		ops = append(ops, storage.DeleteOperation(journalKey(seq)))
+	}
+	if err := p.storageClient.Batch(ctx, ops...); err != nil {
+		return fmt.Errorf("failed to write snapshot to storage: %w", err)
+	}
+	p.journalStart = p.journalNext
+	p.logger.Debug("saved seen records to storage", zap.Int("bytes", len(data)))
+	return nil
+}
+
+func journalKey(seq uint64) string {
+	return journalKeyPrefix + strconv.FormatUint(seq, 10)
+}
@@ -0,0 +1,187 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package antireplayprocessor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+func storageID() *component.ID {
+	id := storagetest.NewStorageID("test")
+	return &id
+}
+
+// TestSnapshotPersistAndReload verifies that identities remembered by one
+// instance are still rejected after a restart on the same storage.
+func TestSnapshotPersistAndReload(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := testConfig()
+	cfg.Storage = storageID()
+
+	p1 := newTestProcessor(t, cfg)
+	require.NoError(t, p1.Start(t.Context(), host))
+	_, err := p1.processLogs(t.Context(), makeLogs(
+		testRecord{signature: "sig-1", nonce: "n1"},
+		testRecord{signature: "sig-2", nonce: "n1"},
+	))
+	require.NoError(t, err)
+	require.NoError(t, p1.Shutdown(t.Context()))
+
+	p2 := newTestProcessor(t, cfg)
+	require.NoError(t, p2.Start(t.Context(), host))
+	t.Cleanup(func() { require.NoError(t, p2.Shutdown(t.Context())) })
+	assert.Equal(t, 2, p2.seen.len())
+
+	out, err := p2.processLogs(t.Context(), makeLogs(
+		testRecord{signature: "sig-1", nonce: "n1"},
+		testRecord{signature: "sig-3", nonce: "n1"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sig-3"}, signatures(out))
+}
+
+// TestSnapshotSkipsExpired verifies that identities expiring while the
+// collector was down are not restored.
+func TestSnapshotSkipsExpired(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := testConfig()
+	cfg.Storage = storageID()
+
+	p1 := newTestProcessor(t, cfg)
+	require.NoError(t, p1.Start(t.Context(), host))
+	_, err := p1.processLogs(t.Context(), makeLogs(testRecord{signature: "sig-1", nonce: "n1"}))
+	require.NoError(t, err)
+	require.NoError(t, p1.Shutdown(t.Context()))
+
+	p2 := newTestProcessor(t, cfg)
+	p2.now = func() time.Time { return testNow.Add(2 * time.Hour) }
+	require.NoError(t, p2.Start(t.Context(), host))
+	t.Cleanup(func() { require.NoError(t, p2.Shutdown(t.Context())) })
+	assert.Equal(t, 0, p2.seen.len())
+}
+
+// TestJournalSurvivesCrash verifies that identities are persisted before a
+// batch is passed on, so they are still rejected when the collector stops
+// without saving a snapshot.
+func TestJournalSurvivesCrash(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := testConfig()
+	cfg.Storage = storageID()
+
+	p1 := newTestProcessor(t, cfg)
+	require.NoError(t, p1.Start(t.Context(), host))
+	_, err := p1.processLogs(t.Context(), makeLogs(testRecord{signature: "sig-1", nonce: "n1"}))
+	require.NoError(t, err)
+	_, err = p1.processLogs(t.Context(), makeLogs(testRecord{signature: "sig-2", nonce: "n1"}))
+	require.NoError(t, err)
+
+	// Simulate a crash: release the storage without the final snapshot.
+	p1.stopSave()
+	p1.saveWG.Wait()
+	require.NoError(t, p1.storageClient.Close(t.Context()))
+
+	p2 := newTestProcessor(t, cfg)
+	require.NoError(t, p2.Start(t.Context(), host))
+	t.Cleanup(func() { require.NoError(t, p2.Shutdown(t.Context())) })
+	assert.Equal(t, 2, p2.seen.len())
+
+	out, err := p2.processLogs(t.Context(), makeLogs(
+		testRecord{signature: "sig-1", nonce: "n1"},
+		testRecord{signature: "sig-2", nonce: "n1"},
+		testRecord{signature: "sig-3", nonce: "n1"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sig-3"}, signatures(out))
+}
+
+// TestSnapshotReplacesJournal verifies that a snapshot removes the journal
+// entries it includes.
+func TestSnapshotReplacesJournal(t *testing.T) {
+	host := storagetest.NewStorageHost().WithInMemoryStorageExtension("test")
+	cfg := testConfig()
+	cfg.Storage = storageID()
+
+	p := newTestProcessor(t, cfg)
+	require.NoError(t, p.Start(t.Context(), host))
+	t.Cleanup(func() { require.NoError(t, p.Shutdown(t.Context())) })
+
+	_, err := p.processLogs(t.Context(), makeLogs(testRecord{signature: "sig-1", nonce: "n1"}))
+	require.NoError(t, err)
+	data, err := p.storageClient.Get(t.Context(), journalKey(0))
+	require.NoError(t, err)
+	assert.Len(t, data, 1+snapshotEntrySize)
+
+	require.NoError(t, p.saveSnapshot(t.Context()))
+	data, err = p.storageClient.Get(t.Context(), journalKey(0))
+	require.NoError(t, err)
+	assert.Nil(t, data)
+	data, err = p.storageClient.Get(t.Context(), storageKey)
+	require.NoError(t, err)
+	assert.Len(t, data, 1+snapshotEntrySize)
+}
+
+type failingClient struct {
+	storage.Client
+}
+
+func (failingClient) Set(context.Context, string, []byte) error {
+	return errors.New("disk full")
+}
+
+// TestJournalWriteFailure verifies that a batch whose identities cannot be
+// persisted is rejected and its identities are not remembered.
+func TestJournalWriteFailure(t *testing.T) {
+	p := newTestProcessor(t, testConfig())
+	p.storageClient = failingClient{Client: storage.NewNopClient()}
+
+	ld := makeLogs(testRecord{signature: "sig-1", nonce: "n1"})
+	_, err := p.processLogs(t.Context(), ld)
+	require.EqualError(t, err, "failed to write seen records to storage: disk full")
+	assert.Equal(t, 0, p.seen.len())
+	assert.Equal(t, []string{"sig-1"}, signatures(ld))
+
+	p.storageClient = storage.NewNopClient()
+	out, err := p.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sig-1"}, signatures(out))
+}
+
+func TestPeriodicSave(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := testConfig()
+	cfg.Storage = storageID()
+	cfg.SaveInterval = 10 * time.Millisecond
+
+	p := newTestProcessor(t, cfg)
+	require.NoError(t, p.Start(t.Context(), host))
+	t.Cleanup(func() { require.NoError(t, p.Shutdown(t.Context())) })
+
+	_, err := p.processLogs(t.Context(), makeLogs(testRecord{signature: "sig-1", nonce: "n1"}))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		data, err := p.storageClient.Get(t.Context(), storageKey)
+		return err == nil && len(data) == 1+snapshotEntrySize
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStartWithMissingStorage(t *testing.T) {
+	cfg := testConfig()
+	cfg.Storage = storageID()
+
+	p := newTestProcessor(t, cfg)
+	err := p.Start(t.Context(), componenttest.NewNopHost())
+	assert.ErrorContains(t, err, `storage extension "test_storage/test" not found`)
+}
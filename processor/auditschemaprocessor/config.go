@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditschemaprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/auditschemaprocessor"
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	// ActionDrop removes non-conforming records from the pipeline.
+	ActionDrop = "drop"
+	// ActionFlag keeps non-conforming records and lists their violations in
+	// FlagAttribute.
+	ActionFlag = "flag"
+)
+
+// severityLevels maps severity level names to the lowest severity number of
+// the level. Every level spans four severity numbers.
+var severityLevels = map[string]plog.SeverityNumber{
+	"TRACE": plog.SeverityNumberTrace,
+	"DEBUG": plog.SeverityNumberDebug,
+	"INFO":  plog.SeverityNumberInfo,
+	"WARN":  plog.SeverityNumberWarn,
+	"ERROR": plog.SeverityNumberError,
+	"FATAL": plog.SeverityNumberFatal,
+}
+
+var valueTypes = []string{"string", "int", "double", "bool", "map", "slice", "bytes"}
+
+// Config defines configuration for the audit schema processor.
+type Config struct {
+	// Schemas are the audit event schemas. A record is validated against the
+	// first schema whose match conditions it meets.
+	Schemas []SchemaConfig `mapstructure:"schemas"`
+
+	// RequireSchema treats records matching no schema as non-conforming.
+	// Default: false, such records are passed through unchecked.
+	RequireSchema bool `mapstructure:"require_schema"`
+
+	// Action is what happens to non-conforming records: "drop" removes them,
+	// "flag" sets FlagAttribute to the list of violations. Default: flag.
+	Action string `mapstructure:"action"`
+
+	// FlagAttribute is the attribute set on non-conforming records when
+	// Action is "flag". Default: "audit.schema.violations".
+	FlagAttribute string `mapstructure:"flag_attribute"`
+}
+
+// SchemaConfig defines an audit event schema.
+type SchemaConfig struct {
+	// Name identifies the schema in violations.
+	Name string `mapstructure:"name"`
+
+	// Match selects the records the schema applies to: record attribute keys
+	// and the regular expressions their values must match. A schema without
+	// match conditions applies to every record.
+	Match map[string]string `mapstructure:"match"`
+
+	// RequiredAttributes are the record attributes that must be present.
+	RequiredAttributes []string `mapstructure:"required_attributes"`
+
+	// Attributes constrains the values of record attributes, when present.
+	Attributes map[string]AttributeConfig `mapstructure:"attributes"`
+
+	// Severity constrains the severity number of records. Optional.
+	Severity SeverityConfig `mapstructure:"severity"`
+}
+
+// AttributeConfig constrains the value of an attribute.
+type AttributeConfig struct {
+	// Type is the required value type: string, int, double, bool, map, slice
+	// or bytes. Optional.
+	Type string `mapstructure:"type"`
+
+	// Enum lists the allowed values, compared to the string representation
+	// of the value. Optional.
+	Enum []string `mapstructure:"enum"`
+}
+
+// SeverityConfig bounds the severity of records by level: TRACE, DEBUG,
+// INFO, WARN, ERROR or FATAL. Both bounds are inclusive of every severity
+// number of their level. Records without a severity number do not conform
+// when a bound is set.
+type SeverityConfig struct {
+	Min string `mapstructure:"min"`
+	Max string `mapstructure:"max"`
+}
+
+// Validate checks the Config for invalid values.
+func (cfg *Config) Validate() error {
+	if len(cfg.Schemas) == 0 {
+		return errors.New("schemas must not be empty")
+	}
+	names := make(map[string]bool, len(cfg.Schemas))
+	for i, s := range cfg.Schemas {
+		if err := s.validate(); err != nil {
+			return fmt.Errorf("schemas[%d]: %w", i, err)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("schemas[%d]: duplicate schema %q", i, s.Name)
+		}
+		names[s.Name] = true
+	}
+	switch cfg.Action {
+	case ActionDrop:
+	case ActionFlag:
+		if cfg.FlagAttribute == "" {
+			return errors.New("flag_attribute must be set when action is flag")
+		}
+	default:
+		return fmt.Errorf("action must be %q or %q, got %q", ActionDrop, ActionFlag, cfg.Action)
+	}
+	return nil
+}
+
+func (s *SchemaConfig) validate() error {
+	if s.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	for key, expr := range s.Match {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("match %q: %w", key, err)
+		}
+	}
+	for _, attr := range s.RequiredAttributes {
+		if attr == "" {
+			return errors.New("required_attributes must not contain empty keys")
+		}
+	}
+	for key, attr := range s.Attributes {
+		if attr.Type != "" && !slices.Contains(valueTypes, attr.Type) {
+			return fmt.Errorf("attributes %q: type must be one of %s, got %q", key, strings.Join(valueTypes, ", "), attr.Type)
+		}
+	}
+	minSev, maxSev := s.Severity.Min, s.Severity.Max
+	if _, ok := severityLevels[minSev]; minSev != "" && !ok {
+		return fmt.Errorf("severity: unknown level %q", minSev)
+	}
+	if _, ok := severityLevels[maxSev]; maxSev != "" && !ok {
+		return fmt.Errorf("severity: unknown level %q", maxSev)
+	}
+	if minSev != "" && maxSev != "" && severityLevels[minSev] > severityLevels[maxSev] {
+		return fmt.Errorf("severity: min %s is above max %s", minSev, maxSev)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditschemaprocessor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/auditschemaprocessor/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr string
+	}{
+		{
+			id:          component.NewID(metadata.Type),
+			expectedErr: "schemas must not be empty",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "full"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.Schemas = []SchemaConfig{
+					{
+						Name:               "login",
+						Match:              map[string]string{"event.name": "^login$"},
+						RequiredAttributes: []string{"user.id", "event.outcome"},
+						Attributes: map[string]AttributeConfig{
+							"event.outcome": {Type: "string", Enum: []string{"success", "failure"}},
+						},
+						Severity: SeverityConfig{Min: "INFO", Max: "WARN"},
+					},
+					{
+						Name:               "any",
+						RequiredAttributes: []string{"event.name"},
+					},
+				}
+				cfg.RequireSchema = true
+				cfg.Action = ActionDrop
+				return cfg
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_action"),
+			expectedErr: `action must be "drop" or "flag", got "route"`,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_type"),
+			expectedErr: `schemas[0]: attributes "user.id": type must be one of string, int, double, bool, map, slice, bytes, got "number"`,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_severity"),
+			expectedErr: "schemas[0]: severity: min ERROR is above max INFO",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "duplicate_name"),
+			expectedErr: `schemas[1]: duplicate schema "any"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
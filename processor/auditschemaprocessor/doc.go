@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package auditschemaprocessor validates log records against audit event
+// schemas.
+package auditschemaprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/auditschemaprocessor"
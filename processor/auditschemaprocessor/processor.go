@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditschemaprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/auditschemaprocessor"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/auditschemaprocessor/internal/metadata"
+)
+
+const violationNoSchema = "no matching schema"
+
+type auditSchemaProcessor struct {
+	config    *Config
+	logger    *zap.Logger
+	telemetry *metadata.TelemetryBuilder
+	schemas   []*schema
+}
+
+func newAuditSchemaProcessor(set processor.Settings, cfg *Config) (*auditSchemaProcessor, error) {
+	tel, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make([]*schema, 0, len(cfg.Schemas))
+	for _, s := range cfg.Schemas {
+		schemas = append(schemas, newSchema(s))
+	}
+	return &auditSchemaProcessor{
+		config:    cfg,
+		logger:    set.Logger,
+		telemetry: tel,
+		schemas:   schemas,
+	}, nil
+}
+
+// processLogs is the ConsumeLogs handler passed to processorhelper.NewLogs.
+func (p *auditSchemaProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	var invalid int64
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				// Only the processor lists violations: a conforming record
+				// never carries the flag attribute.
+				if p.config.FlagAttribute != "" {
+					lr.Attributes().Remove(p.config.FlagAttribute)
+				}
+				violations := p.check(lr)
+				if len(violations) == 0 {
+					return false
+				}
+				invalid++
+				p.logger.Debug("non-conforming log record", zap.Strings("violations", violations))
+				if p.config.Action == ActionDrop {
+					return true
+				}
+				s := lr.Attributes().PutEmptySlice(p.config.FlagAttribute)
+				for _, v := range violations {
+					s.AppendEmpty().SetStr(v)
+				}
+				return false
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+
+	if invalid > 0 {
+		p.telemetry.ProcessorAuditSchemaLogRecordsInvalid.Add(ctx, invalid)
+	}
+	if ld.ResourceLogs().Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+// check validates lr against the first schema it matches and returns the
+// violations found.
+func (p *auditSchemaProcessor) check(lr plog.LogRecord) []string {
+	for _, s := range p.schemas {
+		if s.matches(lr) {
+			return s.validate(lr)
+		}
+	}
+	if p.config.RequireSchema {
+		return []string{violationNoSchema}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditschemaprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/auditschemaprocessor/internal/metadatatest"
+)
+
+func testConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Schemas = []SchemaConfig{
+		{
+			Name:               "login",
+			Match:              map[string]string{"event.name": "^login$"},
+			RequiredAttributes: []string{"user.id", "event.outcome"},
+			Attributes: map[string]AttributeConfig{
+				"event.outcome": {Enum: []string{"success", "failure"}},
+				"user.id":       {Type: "string"},
+			},
+			Severity: SeverityConfig{Min: "INFO", Max: "WARN"},
+		},
+	}
+	return cfg
+}
+
+func newTestProcessor(t *testing.T, cfg *Config, tel *componenttest.Telemetry) *auditSchemaProcessor {
+	t.Helper()
+	require.NoError(t, cfg.Validate())
+	p, err := newAuditSchemaProcessor(metadatatest.NewSettings(tel), cfg)
+	require.NoError(t, err)
+	return p
+}
+
+func makeLogs(records ...map[string]any) plog.Logs {
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, attrs := range records {
+		lr := lrs.AppendEmpty()
+		lr.SetSeverityNumber(plog.SeverityNumberInfo)
+		_ = lr.Attributes().FromRaw(attrs)
+	}
+	return ld
+}
+
+// violations returns the flagged violations of every record, nil for
+// conforming records.
+func violations(ld plog.Logs) []any {
+	var out []any
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < lrs.Len(); i++ {
+		out = append(out, lrs.At(i).Attributes().AsRaw()["audit.schema.violations"])
+	}
+	return out
+}
+
+func TestFlagsViolations(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	p := newTestProcessor(t, testConfig(), tel)
+
+	out, err := p.processLogs(t.Context(), makeLogs(
+		map[string]any{"event.name": "login", "user.id": "alice", "event.outcome": "success"},
+		map[string]any{"event.name": "login", "user.id": 42, "event.outcome": "maybe"},
+		map[string]any{"event.name": "login"},
+		map[string]any{"event.name": "logout"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		nil,
+		[]any{
+			`login: attribute "event.outcome" has value "maybe" not in [success failure]`,
+			`login: attribute "user.id" must be of type string, got Int`,
+		},
+		[]any{
+			`login: missing attribute "user.id"`,
+			`login: missing attribute "event.outcome"`,
+		},
+		nil,
+	}, violations(out))
+
+	metadatatest.AssertEqualProcessorAuditSchemaLogRecordsInvalid(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 2},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestRemovesSenderViolations(t *testing.T) {
+	p := newTestProcessor(t, testConfig(), componenttest.NewTelemetry())
+
+	out, err := p.processLogs(t.Context(), makeLogs(
+		map[string]any{"event.name": "login", "user.id": "alice", "event.outcome": "success", "audit.schema.violations": []any{"forged"}},
+		map[string]any{"event.name": "login", "user.id": "alice", "audit.schema.violations": []any{}},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		nil,
+		[]any{`login: missing attribute "event.outcome"`},
+	}, violations(out))
+}
+
+func TestSeverityRange(t *testing.T) {
+	p := newTestProcessor(t, testConfig(), componenttest.NewTelemetry())
+
+	ld := makeLogs(
+		map[string]any{"event.name": "login", "user.id": "alice", "event.outcome": "success"},
+		map[string]any{"event.name": "login", "user.id": "alice", "event.outcome": "success"},
+		map[string]any{"event.name": "login", "user.id": "alice", "event.outcome": "success"},
+		map[string]any{"event.name": "login", "user.id": "alice", "event.outcome": "success"},
+	)
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	lrs.At(0).SetSeverityNumber(plog.SeverityNumberWarn4)
+	lrs.At(1).SetSeverityNumber(plog.SeverityNumberDebug4)
+	lrs.At(2).SetSeverityNumber(plog.SeverityNumberError)
+	lrs.At(3).SetSeverityNumber(plog.SeverityNumberUnspecified)
+
+	out, err := p.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		nil,
+		[]any{"login: severity Debug4 is below Info"},
+		[]any{"login: severity Error is above Warn4"},
+		[]any{"login: severity is not set"},
+	}, violations(out))
+}
+
+func TestRequireSchema(t *testing.T) {
+	cfg := testConfig()
+	cfg.RequireSchema = true
+	p := newTestProcessor(t, cfg, componenttest.NewTelemetry())
+
+	out, err := p.processLogs(t.Context(), makeLogs(map[string]any{"event.name": "logout"}))
+	require.NoError(t, err)
+	assert.Equal(t, []any{[]any{"no matching schema"}}, violations(out))
+}
+
+func TestDropsViolations(t *testing.T) {
+	cfg := testConfig()
+	cfg.Action = ActionDrop
+	p := newTestProcessor(t, cfg, componenttest.NewTelemetry())
+
+	out, err := p.processLogs(t.Context(), makeLogs(
+		map[string]any{"event.name": "login", "user.id": "alice", "event.outcome": "success"},
+		map[string]any{"event.name": "login"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, 1, out.LogRecordCount())
+
+	_, err = p.processLogs(t.Context(), makeLogs(map[string]any{"event.name": "login"}))
+	assert.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+}
@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package auditschemaprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/auditschemaprocessor"
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+var valueTypeNames = map[pcommon.ValueType]string{
+	pcommon.ValueTypeStr:    "string",
+	pcommon.ValueTypeInt:    "int",
+	pcommon.ValueTypeDouble: "double",
+	pcommon.ValueTypeBool:   "bool",
+	pcommon.ValueTypeMap:    "map",
+	pcommon.ValueTypeSlice:  "slice",
+	pcommon.ValueTypeBytes:  "bytes",
+}
+
+type attributeRule struct {
+	key      string
+	typeName string
+	enum     []string
+}
+
+// schema is a compiled SchemaConfig.
+type schema struct {
+	name       string
+	match      map[string]*regexp.Regexp
+	required   []string
+	attributes []attributeRule
+	minSev     plog.SeverityNumber
+	maxSev     plog.SeverityNumber
+}
+
+func newSchema(cfg SchemaConfig) *schema {
+	s := &schema{
+		name:     cfg.Name,
+		match:    make(map[string]*regexp.Regexp, len(cfg.Match)),
+		required: cfg.RequiredAttributes,
+	}
+	for key, expr := range cfg.Match {
+		// Error checked in Config.Validate()
+		s.match[key] = regexp.MustCompile(expr)
+	}
+	for key, attr := range cfg.Attributes {
+		s.attributes = append(s.attributes, attributeRule{key: key, typeName: attr.Type, enum: attr.Enum})
+	}
+	// Report violations in a stable order.
+	sort.Slice(s.attributes, func(i, j int) bool { return s.attributes[i].key < s.attributes[j].key })
+	if cfg.Severity.Min != "" {
+		s.minSev = severityLevels[cfg.Severity.Min]
+	}
+	if cfg.Severity.Max != "" {
+		s.maxSev = severityLevels[cfg.Severity.Max] + 3
+	}
+	return s
+}
+
+// matches reports whether the schema applies to lr.
+func (s *schema) matches(lr plog.LogRecord) bool {
+	for key, re := range s.match {
+		v, ok := lr.Attributes().Get(key)
+		if !ok || !re.MatchString(v.AsString()) {
+			return false
+		}
+	}
+	return true
+}
+
+// validate returns the violations of lr, prefixed with the schema name.
+func (s *schema) validate(lr plog.LogRecord) []string {
+	var violations []string
+	report := func(format string, args ...any) {
+		violations = append(violations, s.name+": "+fmt.Sprintf(format, args...))
+	}
+
+	for _, key := range s.required {
+		if _, ok := lr.Attributes().Get(key); !ok {
+			report("missing attribute %q", key)
+		}
+	}
+	for _, rule := range s.attributes {
+		v, ok := lr.Attributes().Get(rule.key)
+		if !ok {
+			continue
+		}
+		if rule.typeName != "" && valueTypeNames[v.Type()] != rule.typeName {
+			report("attribute %q must be of type %s, got %s", rule.key, rule.typeName, v.Type())
+			continue
+		}
+		if len(rule.enum) > 0 && !slices.Contains(rule.enum, v.AsString()) {
+			report("attribute %q has value %q not in %v", rule.key, v.AsString(), rule.enum)
+		}
+	}
+
+	sev := lr.SeverityNumber()
+	switch {
+	case (s.minSev != 0 || s.maxSev != 0) && sev == plog.SeverityNumberUnspecified:
+		report("severity is not set")
+	case s.minSev != 0 && sev < s.minSev:
+		report("severity %s is below %s", sev, s.minSev)
+	case s.maxSev != 0 && sev > s.maxSev:
+		report("severity %s is above %s", sev, s.maxSev)
+	}
+	return violations
+}
@@ -0,0 +1,76 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fieldencryptionprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/fieldencryptionprocessor"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+// Config defines configuration for the field encryption processor.
+type Config struct {
+	// Attributes are the log record attribute keys whose values are
+	// encrypted. Records missing an attribute are left unchanged for it.
+	Attributes []string `mapstructure:"attributes"`
+
+	// Body encrypts the log record body. Default: false.
+	Body bool `mapstructure:"body"`
+
+	// DataKeyTTL is how long a data key is used before a new one is requested
+	// from Transit. Default: 1h.
+	DataKeyTTL time.Duration `mapstructure:"data_key_ttl"`
+
+	// Transit configures the OpenBao Transit secrets engine issuing the data
+	// keys. Vault's Transit engine exposes the same API.
+	Transit TransitConfig `mapstructure:"transit"`
+}
+
+// TransitConfig defines how to reach the Transit secrets engine.
+type TransitConfig struct {
+	// ClientConfig configures the HTTP client; endpoint is the address of the
+	// OpenBao server, for example https://openbao.example.com:8200.
+	confighttp.ClientConfig `mapstructure:",squash"`
+
+	// Token is the token used to authenticate, sent as X-Vault-Token.
+	Token configopaque.String `mapstructure:"token"`
+
+	// Namespace is the namespace of the mount, sent as X-Vault-Namespace.
+	// Optional.
+	Namespace string `mapstructure:"namespace"`
+
+	// MountPath is the path the Transit engine is mounted at. Default: "transit".
+	MountPath string `mapstructure:"mount_path"`
+
+	// KeyName is the name of the Transit key wrapping the data keys.
+	KeyName string `mapstructure:"key_name"`
+}
+
+// Validate checks the Config for invalid values.
+func (cfg *Config) Validate() error {
+	if len(cfg.Attributes) == 0 && !cfg.Body {
+		return errors.New("at least one of attributes or body must be set")
+	}
+	for _, attr := range cfg.Attributes {
+		if attr == "" {
+			return errors.New("attributes must not contain empty keys")
+		}
+	}
+	if cfg.DataKeyTTL <= 0 {
+		return fmt.Errorf("data_key_ttl must be > 0, got %s", cfg.DataKeyTTL)
+	}
+	if cfg.Transit.Endpoint == "" {
+		return errors.New("transit: endpoint must be set")
+	}
+	if cfg.Transit.MountPath == "" {
+		return errors.New("transit: mount_path must be set")
+	}
+	if cfg.Transit.KeyName == "" {
+		return errors.New("transit: key_name must be set")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fieldencryptionprocessor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/fieldencryptionprocessor/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr string
+	}{
+		{
+			id:          component.NewID(metadata.Type),
+			expectedErr: "at least one of attributes or body must be set",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "full"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.Attributes = []string{"user.email", "client.address"}
+				cfg.Body = true
+				cfg.DataKeyTTL = 10 * time.Minute
+				cfg.Transit.Endpoint = "https://openbao.example.com:8200"
+				cfg.Transit.Token = "s.token"
+				cfg.Transit.Namespace = "audit"
+				cfg.Transit.MountPath = "audit-transit"
+				cfg.Transit.KeyName = "records"
+				return cfg
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "no_fields"),
+			expectedErr: "at least one of attributes or body must be set",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "no_key"),
+			expectedErr: "transit: key_name must be set",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_ttl"),
+			expectedErr: "data_key_ttl must be > 0, got 0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package fieldencryptionprocessor encrypts selected log record fields with
+// data keys issued by the OpenBao Transit secrets engine.
+package fieldencryptionprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/fieldencryptionprocessor"
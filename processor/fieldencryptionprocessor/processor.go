@@ -0,0 +1,329 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fieldencryptionprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/fieldencryptionprocessor"
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/transit"
+)
+
+const (
+	// attrKeyVersion holds the version of the Transit key that wrapped the
+	// data key.
+	attrKeyVersion = "encryption.key_version"
+	// attrWrappedKey holds the data key encrypted by Transit.
+	attrWrappedKey = "encryption.wrapped_key"
+	// attrFields lists the encrypted fields: "body" and "attributes.<key>".
+	attrFields = "encryption.fields"
+	// attrRecordID holds a random ID of the record, bound to its ciphertexts.
+	attrRecordID = "encryption.record_id"
+
+	fieldBody             = "body"
+	fieldAttributesPrefix = "attributes."
+
+	// nonceSize and tagSize are the sizes of the AES-GCM nonce and
+	// authentication tag around every ciphertext.
+	nonceSize = 12
+	tagSize   = 16
+	// recordIDSize is the size of the record ID, before hex encoding.
+	recordIDSize = 16
+)
+
+// Every plaintext starts with a byte giving the type of the encrypted value,
+// so that it can be restored on decryption.
+const (
+	typeStr    byte = 1
+	typeInt    byte = 2
+	typeDouble byte = 3
+	typeBool   byte = 4
+	typeMap    byte = 5
+	typeSlice  byte = 6
+	typeBytes  byte = 7
+)
+
+// dataKey is a data key issued by Transit, used to encrypt records locally.
+type dataKey struct {
+	aead    cipher.AEAD
+	wrapped string
+	version int64
+	expires time.Time
+}
+
+type fieldEncryptionProcessor struct {
+	config *Config
+	set    processor.Settings
+	logger *zap.Logger
+	now    func() time.Time
+
+	transit *transit.Client
+
+	mu  sync.Mutex
+	key *dataKey
+}
+
+func newFieldEncryptionProcessor(set processor.Settings, cfg *Config) *fieldEncryptionProcessor {
+	return &fieldEncryptionProcessor{
+		config: cfg,
+		set:    set,
+		logger: set.Logger,
+		now:    time.Now,
+	}
+}
+
+// Start creates the Transit client. Data keys are requested on first use.
+func (p *fieldEncryptionProcessor) Start(ctx context.Context, host component.Host) error {
+	client, err := p.config.Transit.ToClient(ctx, host.GetExtensions(), p.set.TelemetrySettings)
+	if err != nil {
+		return err
+	}
+	p.transit = transit.NewClient(client, transit.Settings{
+		Endpoint:  p.config.Transit.Endpoint,
+		Token:     string(p.config.Transit.Token),
+		Namespace: p.config.Transit.Namespace,
+		MountPath: p.config.Transit.MountPath,
+		KeyName:   p.config.Transit.KeyName,
+	})
+	return nil
+}
+
+func (p *fieldEncryptionProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				if err := p.encryptRecord(ctx, lrs.At(k)); err != nil {
+					// Never forward the batch with fields left in plaintext.
+					return ld, err
+				}
+			}
+		}
+	}
+	return ld, nil
+}
+
+func (p *fieldEncryptionProcessor) encryptRecord(ctx context.Context, lr plog.LogRecord) error {
+	if _, ok := lr.Attributes().Get(attrWrappedKey); ok {
+		if p.encrypted(lr) {
+			// Already encrypted, for example by an upstream collector.
+			return nil
+		}
+		// The encryption attributes don't describe the record, they may have
+		// been set by the sender to keep its fields in plaintext.
+		p.logger.Debug("Encrypting record with invalid encryption attributes")
+		lr.Attributes().Remove(attrKeyVersion)
+		lr.Attributes().Remove(attrWrappedKey)
+		lr.Attributes().Remove(attrFields)
+		lr.Attributes().Remove(attrRecordID)
+	}
+
+	var key *dataKey
+	var recordID string
+	var fields []string
+	encrypt := func(field string, v pcommon.Value) error {
+		if key == nil {
+			var err error
+			if key, err = p.currentKey(ctx); err != nil {
+				return err
+			}
+			if recordID, err = newRecordID(); err != nil {
+				return err
+			}
+		}
+		plaintext, err := encodeValue(v)
+		if err != nil {
+			return err
+		}
+		ciphertext, err := seal(key.aead, recordID, field, plaintext)
+		if err != nil {
+			return err
+		}
+		v.SetEmptyBytes().FromRaw(ciphertext)
+		fields = append(fields, field)
+		return nil
+	}
+
+	if p.config.Body && lr.Body().Type() != pcommon.ValueTypeEmpty {
+		if err := encrypt(fieldBody, lr.Body()); err != nil {
+			return err
+		}
+	}
+	for _, attr := range p.config.Attributes {
+		v, ok := lr.Attributes().Get(attr)
+		if !ok || v.Type() == pcommon.ValueTypeEmpty {
+			continue
+		}
+		if err := encrypt(fieldAttributesPrefix+attr, v); err != nil {
+			return err
+		}
+	}
+	if key == nil {
+		return nil
+	}
+
+	lr.Attributes().PutInt(attrKeyVersion, key.version)
+	lr.Attributes().PutStr(attrWrappedKey, key.wrapped)
+	lr.Attributes().PutStr(attrRecordID, recordID)
+	s := lr.Attributes().PutEmptySlice(attrFields)
+	for _, f := range fields {
+		s.AppendEmpty().SetStr(f)
+	}
+	return nil
+}
+
+// encrypted reports whether a record carrying a wrapped data key is encrypted:
+// the encryption attributes are well-formed, and every configured field of the
+// record is listed as encrypted and holds a ciphertext.
+func (p *fieldEncryptionProcessor) encrypted(lr plog.LogRecord) bool {
+	attrs := lr.Attributes()
+	wrapped, _ := attrs.Get(attrWrappedKey)
+	if wrapped.Type() != pcommon.ValueTypeStr {
+		return false
+	}
+	if version, ok := attrs.Get(attrKeyVersion); !ok || version.Type() != pcommon.ValueTypeInt {
+		return false
+	}
+	if id, ok := attrs.Get(attrRecordID); !ok || id.Type() != pcommon.ValueTypeStr || id.Str() == "" {
+		return false
+	}
+	list, ok := attrs.Get(attrFields)
+	if !ok || list.Type() != pcommon.ValueTypeSlice {
+		return false
+	}
+
+	listed := map[string]bool{}
+	for i := 0; i < list.Slice().Len(); i++ {
+		field := list.Slice().At(i)
+		if field.Type() != pcommon.ValueTypeStr {
+			return false
+		}
+		var v pcommon.Value
+		switch name := field.Str(); {
+		case name == fieldBody:
+			v = lr.Body()
+		case strings.HasPrefix(name, fieldAttributesPrefix):
+			if v, ok = attrs.Get(strings.TrimPrefix(name, fieldAttributesPrefix)); !ok {
+				return false
+			}
+		default:
+			return false
+		}
+		// A ciphertext holds at least the nonce, the type and the tag.
+		if v.Type() != pcommon.ValueTypeBytes || v.Bytes().Len() < nonceSize+1+tagSize {
+			return false
+		}
+		listed[field.Str()] = true
+	}
+
+	if p.config.Body && lr.Body().Type() != pcommon.ValueTypeEmpty && !listed[fieldBody] {
+		return false
+	}
+	for _, attr := range p.config.Attributes {
+		if v, ok := attrs.Get(attr); ok && v.Type() != pcommon.ValueTypeEmpty && !listed[fieldAttributesPrefix+attr] {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeValue returns the plaintext of a value: its type followed by its
+// string representation, or its bytes. Maps and slices are encoded as JSON.
+func encodeValue(v pcommon.Value) ([]byte, error) {
+	switch v.Type() {
+	case pcommon.ValueTypeStr:
+		return append([]byte{typeStr}, v.Str()...), nil
+	case pcommon.ValueTypeInt:
+		return append([]byte{typeInt}, v.AsString()...), nil
+	case pcommon.ValueTypeDouble:
+		return append([]byte{typeDouble}, v.AsString()...), nil
+	case pcommon.ValueTypeBool:
+		return append([]byte{typeBool}, v.AsString()...), nil
+	case pcommon.ValueTypeMap:
+		data, err := json.Marshal(v.Map().AsRaw())
+		return append([]byte{typeMap}, data...), err
+	case pcommon.ValueTypeSlice:
+		data, err := json.Marshal(v.Slice().AsRaw())
+		return append([]byte{typeSlice}, data...), err
+	case pcommon.ValueTypeBytes:
+		return append([]byte{typeBytes}, v.Bytes().AsRaw()...), nil
+	}
+	return nil, fmt.Errorf("cannot encrypt a value of type %s", v.Type())
+}
+
+// currentKey returns the current data key, requesting a new one from Transit
+// when there is none or it expired. The lock is not held while Transit is
+// called, so that a slow Transit does not block the records that can still be
+// encrypted with the current key.
+func (p *fieldEncryptionProcessor) currentKey(ctx context.Context) (*dataKey, error) {
+	p.mu.Lock()
+	key := p.key
+	p.mu.Unlock()
+	now := p.now()
+	if key != nil && now.Before(key.expires) {
+		return key, nil
+	}
+
+	dk, err := p.transit.GenerateDataKey(ctx, 256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a data key from transit: %w", err)
+	}
+	block, err := aes.NewCipher(dk.Plaintext)
+	clear(dk.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	key = &dataKey{
+		aead:    aead,
+		wrapped: dk.Ciphertext,
+		version: dk.Version,
+		expires: now.Add(p.config.DataKeyTTL),
+	}
+
+	p.mu.Lock()
+	p.key = key
+	p.mu.Unlock()
+	p.logger.Debug("Using new data key", zap.Int64("key_version", dk.Version))
+	return key, nil
+}
+
+// newRecordID returns a random, hex encoded record ID.
+func newRecordID() (string, error) {
+	id := make([]byte, recordIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// seal encrypts plaintext with AES-GCM. The record ID followed by the field
+// name is used as additional data, so that ciphertexts cannot be moved between
+// fields or records. The result is the nonce followed by the ciphertext.
+func seal(aead cipher.AEAD, recordID, field string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, []byte(recordID+field)), nil
+}
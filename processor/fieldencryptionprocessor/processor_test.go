@@ -0,0 +1,321 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fieldencryptionprocessor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/fieldencryptionprocessor/internal/metadata"
+)
+
+// fakeTransit issues data keys like the Transit datakey endpoint. Every key
+// is made of a single repeated byte, the version, so tests can decrypt.
+type fakeTransit struct {
+	*httptest.Server
+	requests atomic.Int64
+	fail     atomic.Bool
+	// legacy omits key_version from the response.
+	legacy bool
+}
+
+func newFakeTransit(t *testing.T) *fakeTransit {
+	ft := &fakeTransit{}
+	ft.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/transit/datakey/plaintext/audit", r.URL.Path)
+		assert.Equal(t, "s.token", r.Header.Get("X-Vault-Token"))
+		if ft.fail.Load() {
+			http.Error(w, `{"errors":["sealed"]}`, http.StatusServiceUnavailable)
+			return
+		}
+		version := ft.requests.Add(1)
+		key := make([]byte, 32)
+		for i := range key {
+			key[i] = byte(version)
+		}
+		data := map[string]any{
+			"plaintext":  base64.StdEncoding.EncodeToString(key),
+			"ciphertext": fmt.Sprintf("vault:v%d:wrapped", version),
+		}
+		if !ft.legacy {
+			data["key_version"] = version
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"data": data}))
+	}))
+	t.Cleanup(ft.Close)
+	return ft
+}
+
+// open opens a field of lr encrypted with the data key of version.
+func open(t *testing.T, version int64, lr plog.LogRecord, field string, ciphertext []byte) ([]byte, error) {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(version)
+	}
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	recordID, ok := lr.Attributes().Get(attrRecordID)
+	require.True(t, ok)
+	return aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], []byte(recordID.Str()+field))
+}
+
+// decrypt opens a field of lr encrypted with the data key of version, and
+// returns the type and the encoding of its value.
+func decrypt(t *testing.T, version int64, lr plog.LogRecord, field string, ciphertext []byte) (byte, string) {
+	plaintext, err := open(t, version, lr, field, ciphertext)
+	require.NoError(t, err)
+	require.NotEmpty(t, plaintext)
+	return plaintext[0], string(plaintext[1:])
+}
+
+func newTestProcessor(t *testing.T, endpoint string) *fieldEncryptionProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Attributes = []string{"user.email", "user.id"}
+	cfg.Body = true
+	cfg.Transit.Endpoint = endpoint
+	cfg.Transit.Token = "s.token"
+	cfg.Transit.KeyName = "audit"
+	require.NoError(t, cfg.Validate())
+
+	proc := newFieldEncryptionProcessor(processortest.NewNopSettings(metadata.Type), cfg)
+	require.NoError(t, proc.Start(t.Context(), componenttest.NewNopHost()))
+	return proc
+}
+
+func testLogs() plog.Logs {
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lr := lrs.AppendEmpty()
+	lr.Body().SetStr("user alice@example.com logged in")
+	lr.Attributes().PutStr("user.email", "alice@example.com")
+	lr.Attributes().PutInt("user.id", 42)
+	lr.Attributes().PutStr("event.action", "login")
+	lrs.AppendEmpty().Attributes().PutStr("event.action", "heartbeat")
+	return ld
+}
+
+func TestEncryptFields(t *testing.T) {
+	transit := newFakeTransit(t)
+	proc := newTestProcessor(t, transit.URL)
+
+	ld, err := proc.processLogs(t.Context(), testLogs())
+	require.NoError(t, err)
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+
+	lr := lrs.At(0)
+	assert.Equal(t, "login", lr.Attributes().AsRaw()["event.action"])
+	assert.Equal(t, int64(1), lr.Attributes().AsRaw()[attrKeyVersion])
+	assert.Equal(t, "vault:v1:wrapped", lr.Attributes().AsRaw()[attrWrappedKey])
+	assert.Equal(t, []any{"body", "attributes.user.email", "attributes.user.id"}, lr.Attributes().AsRaw()[attrFields])
+
+	typ, body := decrypt(t, 1, lr, "body", lr.Body().Bytes().AsRaw())
+	assert.Equal(t, typeStr, typ)
+	assert.Equal(t, "user alice@example.com logged in", body)
+	email, _ := lr.Attributes().Get("user.email")
+	typ, value := decrypt(t, 1, lr, "attributes.user.email", email.Bytes().AsRaw())
+	assert.Equal(t, typeStr, typ)
+	assert.Equal(t, "alice@example.com", value)
+	id, _ := lr.Attributes().Get("user.id")
+	typ, value = decrypt(t, 1, lr, "attributes.user.id", id.Bytes().AsRaw())
+	assert.Equal(t, typeInt, typ)
+	assert.Equal(t, "42", value)
+
+	// Records without fields to encrypt are left unchanged.
+	assert.Equal(t, map[string]any{"event.action": "heartbeat"}, lrs.At(1).Attributes().AsRaw())
+
+	// Already encrypted records are not encrypted twice.
+	_, err = proc.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+	_, body = decrypt(t, 1, lr, "body", lr.Body().Bytes().AsRaw())
+	assert.Equal(t, "user alice@example.com logged in", body)
+	assert.Equal(t, int64(1), transit.requests.Load())
+}
+
+func TestEncryptValueTypes(t *testing.T) {
+	transit := newFakeTransit(t)
+	proc := newTestProcessor(t, transit.URL)
+
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, set := range []func(v pcommon.Value){
+		func(v pcommon.Value) { v.SetDouble(1.5) },
+		func(v pcommon.Value) { v.SetBool(true) },
+		func(v pcommon.Value) { v.SetEmptyBytes().FromRaw([]byte{0xca, 0xfe}) },
+		func(v pcommon.Value) { _ = v.SetEmptyMap().FromRaw(map[string]any{"a": "b"}) },
+		func(v pcommon.Value) { _ = v.SetEmptySlice().FromRaw([]any{"a", int64(1)}) },
+	} {
+		set(lrs.AppendEmpty().Body())
+	}
+
+	_, err := proc.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+	expected := []struct {
+		typ   byte
+		value string
+	}{
+		{typeDouble, "1.5"},
+		{typeBool, "true"},
+		{typeBytes, "\xca\xfe"},
+		{typeMap, `{"a":"b"}`},
+		{typeSlice, `["a",1]`},
+	}
+	for i, e := range expected {
+		typ, value := decrypt(t, 1, lrs.At(i), "body", lrs.At(i).Body().Bytes().AsRaw())
+		assert.Equal(t, e.typ, typ)
+		assert.Equal(t, e.value, value)
+	}
+}
+
+func TestCiphertextBoundToRecord(t *testing.T) {
+	transit := newFakeTransit(t)
+	proc := newTestProcessor(t, transit.URL)
+
+	ld := testLogs()
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	lrs.At(1).Attributes().PutStr("user.email", "bob@example.com")
+	_, err := proc.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+
+	alice, _ := lrs.At(0).Attributes().Get("user.email")
+	bob, _ := lrs.At(1).Attributes().Get("user.email")
+	assert.NotEqual(t, lrs.At(0).Attributes().AsRaw()[attrRecordID], lrs.At(1).Attributes().AsRaw()[attrRecordID])
+
+	// A ciphertext moved to another record, or to another field of the
+	// record, does not decrypt.
+	_, err = open(t, 1, lrs.At(1), "attributes.user.email", alice.Bytes().AsRaw())
+	require.Error(t, err)
+	_, err = open(t, 1, lrs.At(0), "attributes.user.id", alice.Bytes().AsRaw())
+	require.Error(t, err)
+	_, value := decrypt(t, 1, lrs.At(1), "attributes.user.email", bob.Bytes().AsRaw())
+	assert.Equal(t, "bob@example.com", value)
+}
+
+func TestEncryptForgedEncryptionAttributes(t *testing.T) {
+	transit := newFakeTransit(t)
+	proc := newTestProcessor(t, transit.URL)
+
+	tests := []struct {
+		name  string
+		forge func(lr plog.LogRecord)
+	}{
+		{
+			name: "no fields",
+			forge: func(lr plog.LogRecord) {
+				lr.Attributes().PutInt(attrKeyVersion, 1)
+				lr.Attributes().PutStr(attrWrappedKey, "vault:v1:forged")
+			},
+		},
+		{
+			name: "plaintext fields",
+			forge: func(lr plog.LogRecord) {
+				lr.Attributes().PutInt(attrKeyVersion, 1)
+				lr.Attributes().PutStr(attrWrappedKey, "vault:v1:forged")
+				_ = lr.Attributes().PutEmptySlice(attrFields).FromRaw([]any{"body", "attributes.user.email", "attributes.user.id"})
+			},
+		},
+		{
+			name: "unlisted field",
+			forge: func(lr plog.LogRecord) {
+				lr.Body().SetEmptyBytes().FromRaw(make([]byte, 64))
+				lr.Attributes().PutInt(attrKeyVersion, 1)
+				lr.Attributes().PutStr(attrWrappedKey, "vault:v1:forged")
+				_ = lr.Attributes().PutEmptySlice(attrFields).FromRaw([]any{"body"})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ld := testLogs()
+			lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			tt.forge(lr)
+
+			_, err := proc.processLogs(t.Context(), ld)
+			require.NoError(t, err)
+			assert.Equal(t, "vault:v1:wrapped", lr.Attributes().AsRaw()[attrWrappedKey])
+			assert.Equal(t, []any{"body", "attributes.user.email", "attributes.user.id"}, lr.Attributes().AsRaw()[attrFields])
+			email, _ := lr.Attributes().Get("user.email")
+			_, value := decrypt(t, 1, lr, "attributes.user.email", email.Bytes().AsRaw())
+			assert.Equal(t, "alice@example.com", value)
+		})
+	}
+}
+
+func TestDataKeyRotation(t *testing.T) {
+	transit := newFakeTransit(t)
+	transit.legacy = true
+	proc := newTestProcessor(t, transit.URL)
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	proc.now = func() time.Time { return now }
+
+	versionOf := func(ld plog.Logs) any {
+		return ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw()[attrKeyVersion]
+	}
+
+	ld, err := proc.processLogs(t.Context(), testLogs())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), versionOf(ld))
+
+	now = now.Add(30 * time.Minute)
+	ld, err = proc.processLogs(t.Context(), testLogs())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), versionOf(ld))
+
+	now = now.Add(30 * time.Minute)
+	ld, err = proc.processLogs(t.Context(), testLogs())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), versionOf(ld))
+	assert.Equal(t, int64(2), transit.requests.Load())
+}
+
+func TestTransitUnavailable(t *testing.T) {
+	transit := newFakeTransit(t)
+	transit.fail.Store(true)
+	proc := newTestProcessor(t, transit.URL)
+
+	_, err := proc.processLogs(t.Context(), testLogs())
+	assert.ErrorContains(t, err, "failed to get a data key from transit: transit returned 503 Service Unavailable")
+}
+
+func TestProcessorForwardsEncryptedLogs(t *testing.T) {
+	transit := newFakeTransit(t)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Attributes = []string{"user.email"}
+	cfg.Transit.Endpoint = transit.URL
+	cfg.Transit.Token = "s.token"
+	cfg.Transit.KeyName = "audit"
+
+	sink := &consumertest.LogsSink{}
+	proc, err := NewFactory().CreateLogs(t.Context(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, proc.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		require.NoError(t, proc.Shutdown(t.Context()))
+	})
+
+	require.NoError(t, proc.ConsumeLogs(t.Context(), testLogs()))
+	require.Len(t, sink.AllLogs(), 1)
+	lr := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "user alice@example.com logged in", lr.Body().Str())
+	email, _ := lr.Attributes().Get("user.email")
+	_, value := decrypt(t, 1, lr, "attributes.user.email", email.Bytes().AsRaw())
+	assert.Equal(t, "alice@example.com", value)
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opapolicyprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/opapolicyprocessor"
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// ActionDrop removes denied records from the pipeline.
+	ActionDrop = "drop"
+	// ActionFlag keeps denied records and marks them with FlagAttribute.
+	ActionFlag = "flag"
+)
+
+// Config defines configuration for the OPA policy processor.
+type Config struct {
+	// Policy is an inline Rego module. Exactly one of Policy and PolicyFile
+	// must be set.
+	Policy string `mapstructure:"policy"`
+
+	// PolicyFile is the path of a file holding the Rego module, read when the
+	// processor is created.
+	PolicyFile string `mapstructure:"policy_file"`
+
+	// Query is the Rego query evaluated for every record. Its value is the
+	// decision applied to the record. Default: "data.otelcol.audit.decision".
+	Query string `mapstructure:"query"`
+
+	// Action is what happens to denied records: "drop" removes them, "flag"
+	// sets FlagAttribute to the reason of the decision. Default: drop.
+	Action string `mapstructure:"action"`
+
+	// FlagAttribute is the attribute set on denied records when Action is
+	// "flag". Default: "audit.policy.denied".
+	FlagAttribute string `mapstructure:"flag_attribute"`
+
+	// ClassificationAttribute is the attribute the classification of a
+	// decision is written to. Default: "audit.classification".
+	ClassificationAttribute string `mapstructure:"classification_attribute"`
+
+	// RouteAttribute is the attribute the route of a decision is written to,
+	// for a routing connector to act on. Default: "audit.route".
+	RouteAttribute string `mapstructure:"route_attribute"`
+}
+
+// Validate checks the Config for invalid values.
+func (cfg *Config) Validate() error {
+	if (cfg.Policy == "") == (cfg.PolicyFile == "") {
+		return errors.New("exactly one of policy and policy_file must be set")
+	}
+	if cfg.Query == "" {
+		return errors.New("query must not be empty")
+	}
+	switch cfg.Action {
+	case ActionDrop:
+	case ActionFlag:
+		if cfg.FlagAttribute == "" {
+			return errors.New("flag_attribute must be set when action is flag")
+		}
+	default:
+		return fmt.Errorf("action must be %q or %q, got %q", ActionDrop, ActionFlag, cfg.Action)
+	}
+	if cfg.ClassificationAttribute == "" {
+		return errors.New("classification_attribute must not be empty")
+	}
+	if cfg.RouteAttribute == "" {
+		return errors.New("route_attribute must not be empty")
+	}
+	return nil
+}
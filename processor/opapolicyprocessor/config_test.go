@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opapolicyprocessor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/opapolicyprocessor/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id          component.ID
+		expected    *Config
+		expectedErr string
+	}{
+		{
+			id: component.NewID(metadata.Type),
+			expected: &Config{
+				Policy:                  "package otelcol.audit\ndecision := {\"allow\": true}\n",
+				Query:                   "data.otelcol.audit.decision",
+				Action:                  ActionDrop,
+				FlagAttribute:           "audit.policy.denied",
+				ClassificationAttribute: "audit.classification",
+				RouteAttribute:          "audit.route",
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "file"),
+			expected: &Config{
+				PolicyFile:              "testdata/policy.rego",
+				Query:                   "data.audit.handling",
+				Action:                  ActionFlag,
+				FlagAttribute:           "policy.denied",
+				ClassificationAttribute: "data.class",
+				RouteAttribute:          "destination",
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "nopolicy"),
+			expectedErr: "exactly one of policy and policy_file must be set",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "both"),
+			expectedErr: "exactly one of policy and policy_file must be set",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_action"),
+			expectedErr: `action must be "drop" or "flag", got "quarantine"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package opapolicyprocessor evaluates Rego policies against log records and
+// applies the resulting decisions.
+package opapolicyprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/opapolicyprocessor"
@@ -0,0 +1,238 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opapolicyprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/opapolicyprocessor"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/open-policy-agent/opa/v1/rego"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/opapolicyprocessor/internal/metadata"
+)
+
+const (
+	defaultDenyReason = "denied"
+	// policyErrorReason denies the records the policy fails to evaluate for.
+	policyErrorReason = "policy_error"
+)
+
+type policyProcessor struct {
+	config    *Config
+	logger    *zap.Logger
+	telemetry *metadata.TelemetryBuilder
+	query     rego.PreparedEvalQuery
+}
+
+func newPolicyProcessor(ctx context.Context, set processor.Settings, cfg *Config) (*policyProcessor, error) {
+	module, filename := cfg.Policy, "policy.rego"
+	if cfg.PolicyFile != "" {
+		data, err := os.ReadFile(cfg.PolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy: %w", err)
+		}
+		module, filename = string(data), cfg.PolicyFile
+	}
+	query, err := rego.New(
+		rego.Query(cfg.Query),
+		rego.Module(filename, module),
+		rego.StrictBuiltinErrors(true),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile policy: %w", err)
+	}
+
+	tel, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+	return &policyProcessor{
+		config:    cfg,
+		logger:    set.Logger,
+		telemetry: tel,
+		query:     query,
+	}, nil
+}
+
+func (p *policyProcessor) Shutdown(context.Context) error {
+	p.telemetry.Shutdown()
+	return nil
+}
+
+// decision is the result of the policy for a record. All fields are optional.
+type decision struct {
+	// Allow false denies the record.
+	Allow *bool `json:"allow"`
+	// Deny true denies the record.
+	Deny bool `json:"deny"`
+	// Reason explains a denial; it is the value of the flag attribute.
+	Reason string `json:"reason"`
+	// Classification is written to the classification attribute.
+	Classification string `json:"classification"`
+	// Route is written to the route attribute.
+	Route string `json:"route"`
+	// Attributes are set on the record.
+	Attributes map[string]any `json:"attributes"`
+}
+
+func (d *decision) denied() bool {
+	return d.Deny || (d.Allow != nil && !*d.Allow)
+}
+
+// processLogs is the ConsumeLogs handler passed to processorhelper.NewLogs.
+// The decisions of all records are made before any is applied, so that a
+// canceled evaluation refuses the batch unchanged.
+func (p *policyProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	var decisions []*decision
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resource := rl.Resource().Attributes().AsRaw()
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			sl := sls.At(j)
+			scope := map[string]any{"name": sl.Scope().Name(), "version": sl.Scope().Version()}
+			lrs := sl.LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				d, err := p.evaluate(ctx, lrs.At(k), resource, scope)
+				if err != nil {
+					if ctx.Err() != nil {
+						return ld, err
+					}
+					// A record the policy fails on is denied rather than
+					// blocking the pipeline with the rest of its batch.
+					p.logger.Warn("Denying log record", zap.Error(err))
+					d = &decision{Deny: true, Reason: policyErrorReason}
+				}
+				decisions = append(decisions, d)
+			}
+		}
+	}
+
+	next := 0
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				d := decisions[next]
+				next++
+				p.clear(lr)
+				if d == nil {
+					return false
+				}
+				if d.denied() {
+					p.telemetry.ProcessorOpaPolicyLogRecordsDenied.Add(ctx, 1)
+					if p.config.Action == ActionDrop {
+						return true
+					}
+				}
+				p.apply(lr, d)
+				return false
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+
+	if ld.ResourceLogs().Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+// evaluate runs the policy against a record. It returns nil when the policy
+// makes no decision for it.
+func (p *policyProcessor) evaluate(ctx context.Context, lr plog.LogRecord, resource, scope map[string]any) (*decision, error) {
+	input := map[string]any{
+		"body":            lr.Body().AsRaw(),
+		"attributes":      lr.Attributes().AsRaw(),
+		"resource":        resource,
+		"scope":           scope,
+		"event_name":      lr.EventName(),
+		"severity_text":   lr.SeverityText(),
+		"severity_number": int64(lr.SeverityNumber()),
+		"time_unix_nano":  int64(lr.Timestamp()),
+	}
+	rs, err := p.query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate policy: %w", err)
+	}
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return nil, nil
+	}
+
+	// Round trip through JSON to decode the decision into its fields.
+	data, err := json.Marshal(rs[0].Expressions[0].Value)
+	if err != nil {
+		return nil, err
+	}
+	var d decision
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&d); err != nil {
+		return nil, fmt.Errorf("invalid policy decision %s: %w", data, err)
+	}
+	return &d, nil
+}
+
+// clear removes the attributes written from decisions, so that only the
+// policy sets them: a record without a decision carries none of them.
+func (p *policyProcessor) clear(lr plog.LogRecord) {
+	attrs := lr.Attributes()
+	attrs.Remove(p.config.ClassificationAttribute)
+	attrs.Remove(p.config.RouteAttribute)
+	if p.config.FlagAttribute != "" {
+		attrs.Remove(p.config.FlagAttribute)
+	}
+}
+
+// apply writes a decision to a record.
+func (p *policyProcessor) apply(lr plog.LogRecord, d *decision) {
+	attrs := lr.Attributes()
+	for key, value := range d.Attributes {
+		// The values were decoded from JSON and are all supported by FromRaw.
+		_ = attrs.PutEmpty(key).FromRaw(fromJSON(value))
+	}
+	if d.Classification != "" {
+		attrs.PutStr(p.config.ClassificationAttribute, d.Classification)
+	}
+	if d.Route != "" {
+		attrs.PutStr(p.config.RouteAttribute, d.Route)
+	}
+	if d.denied() {
+		reason := d.Reason
+		if reason == "" {
+			reason = defaultDenyReason
+		}
+		attrs.PutStr(p.config.FlagAttribute, reason)
+	}
+}
+
+// fromJSON converts the numbers of a decoded JSON value to int64 when they are
+// integers and to float64 otherwise.
+func fromJSON(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, elem := range v {
+			v[key] = fromJSON(elem)
+		}
+	case []any:
+		for i, elem := range v {
+			v[i] = fromJSON(elem)
+		}
+	}
+	return v
+}
@@ -0,0 +1,185 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opapolicyprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/opapolicyprocessor/internal/metadatatest"
+)
+
+func newTestProcessor(t *testing.T, cfg *Config) (*policyProcessor, *componenttest.Telemetry) {
+	t.Helper()
+	require.NoError(t, cfg.Validate())
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	p, err := newPolicyProcessor(t.Context(), metadatatest.NewSettings(tel), cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, p.Shutdown(context.Background())) })
+	return p, tel
+}
+
+func fileConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.PolicyFile = "testdata/policy.rego"
+	return cfg
+}
+
+type testRecord struct {
+	action   string
+	severity plog.SeverityNumber
+}
+
+// makeLogs creates one resource for service, holding the records.
+func makeLogs(service string, records ...testRecord) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", service)
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, r := range records {
+		lr := lrs.AppendEmpty()
+		lr.Attributes().PutStr("event.action", r.action)
+		lr.SetSeverityNumber(r.severity)
+	}
+	return ld
+}
+
+func records(ld plog.Logs) []map[string]any {
+	var out []map[string]any
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				out = append(out, lrs.At(k).Attributes().AsRaw())
+			}
+		}
+	}
+	return out
+}
+
+func TestDecisions(t *testing.T) {
+	p, tel := newTestProcessor(t, fileConfig())
+
+	ld := makeLogs("payments",
+		testRecord{action: "auth.login", severity: plog.SeverityNumberInfo},
+		testRecord{action: "payment.capture", severity: plog.SeverityNumberWarn},
+		testRecord{action: "payment.debug", severity: plog.SeverityNumberDebug},
+	)
+	makeLogs("web", testRecord{action: "page.view", severity: plog.SeverityNumberInfo}).ResourceLogs().MoveAndAppendTo(ld.ResourceLogs())
+
+	out, err := p.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"event.action": "auth.login", "audit.classification": "pci", "audit.route": "security", "audit.policy.version": int64(3)},
+		{"event.action": "payment.capture", "audit.classification": "pci", "audit.route": "default", "audit.policy.version": int64(3)},
+		{"event.action": "page.view", "audit.classification": "internal", "audit.route": "default", "audit.policy.version": int64(3)},
+	}, records(out))
+
+	metadatatest.AssertEqualProcessorOpaPolicyLogRecordsDenied(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 1},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestFlagDenied(t *testing.T) {
+	cfg := fileConfig()
+	cfg.Action = ActionFlag
+	p, _ := newTestProcessor(t, cfg)
+
+	out, err := p.processLogs(t.Context(), makeLogs("web", testRecord{action: "page.debug", severity: plog.SeverityNumberDebug}))
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"event.action": "page.debug", "audit.classification": "internal", "audit.route": "default", "audit.policy.version": int64(3), "audit.policy.denied": "below info"},
+	}, records(out))
+}
+
+func TestAllDropped(t *testing.T) {
+	p, _ := newTestProcessor(t, fileConfig())
+
+	_, err := p.processLogs(t.Context(), makeLogs("web", testRecord{action: "page.debug", severity: plog.SeverityNumberDebug}))
+	assert.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+}
+
+func TestUndefinedDecision(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Policy = `package otelcol.audit
+
+decision := {"deny": true} if input.attributes["event.action"] == "secret.read"
+`
+	p, _ := newTestProcessor(t, cfg)
+
+	out, err := p.processLogs(t.Context(), makeLogs("web",
+		testRecord{action: "secret.read"},
+		testRecord{action: "page.view"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"event.action": "page.view"}}, records(out))
+}
+
+func TestEvaluationErrorDeniesRecord(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Action = ActionFlag
+	cfg.Policy = `package otelcol.audit
+
+decision := {"deny": to_number(input.attributes["event.action"]) > 0}
+`
+	p, tel := newTestProcessor(t, cfg)
+
+	out, err := p.processLogs(t.Context(), makeLogs("web", testRecord{action: "1"}, testRecord{action: "page.view"}))
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"event.action": "1", "audit.policy.denied": "denied"},
+		{"event.action": "page.view", "audit.policy.denied": "policy_error"},
+	}, records(out))
+	metadatatest.AssertEqualProcessorOpaPolicyLogRecordsDenied(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 2},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestSenderAttributesRemoved(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Action = ActionFlag
+	cfg.Policy = `package otelcol.audit
+
+decision := {"route": "security"} if input.attributes["event.action"] == "auth.login"
+`
+	p, _ := newTestProcessor(t, cfg)
+
+	ld := makeLogs("web", testRecord{action: "auth.login"}, testRecord{action: "page.view"})
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < lrs.Len(); i++ {
+		lrs.At(i).Attributes().PutStr("audit.classification", "public")
+		lrs.At(i).Attributes().PutStr("audit.route", "archive")
+		lrs.At(i).Attributes().PutStr("audit.policy.denied", "forged")
+	}
+
+	out, err := p.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"event.action": "auth.login", "audit.route": "security"},
+		{"event.action": "page.view"},
+	}, records(out))
+}
+
+func TestInvalidPolicy(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Policy = "package otelcol.audit\n\ndecision := {"
+	_, err := newPolicyProcessor(t.Context(), metadatatest.NewSettings(componenttest.NewTelemetry()), cfg)
+	assert.ErrorContains(t, err, "failed to compile policy")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.PolicyFile = "testdata/missing.rego"
+	_, err = newPolicyProcessor(t.Context(), metadatatest.NewSettings(componenttest.NewTelemetry()), cfg)
+	assert.ErrorContains(t, err, "failed to read policy")
+}
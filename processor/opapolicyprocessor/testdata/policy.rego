@@ -0,0 +1,23 @@
+package otelcol.audit
+
+# Records of the payments service hold cardholder data.
+default classification := "internal"
+
+classification := "pci" if input.resource["service.name"] == "payments"
+
+default route := "default"
+
+route := "security" if startswith(input.attributes["event.action"], "auth.")
+
+# Records below INFO must not reach the audit store.
+default allow := true
+
+allow := false if input.severity_number < 9
+
+decision := {
+	"allow": allow,
+	"reason": "below info",
+	"classification": classification,
+	"route": route,
+	"attributes": {"audit.policy.version": 3},
+}
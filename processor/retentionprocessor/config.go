@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package retentionprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/retentionprocessor"
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Config defines configuration for the retention processor.
+type Config struct {
+	// Classes are the retention classes rules can assign.
+	Classes []ClassConfig `mapstructure:"classes"`
+
+	// DefaultClass is the class of records no rule assigns a class to.
+	// Optional — when unset, such records are not stamped.
+	DefaultClass string `mapstructure:"default_class"`
+
+	// Rules are evaluated in order for every record. The class is taken from
+	// the first matching rule that sets one; a record is under legal hold when
+	// any matching rule sets legal_hold.
+	Rules []RuleConfig `mapstructure:"rules"`
+
+	// TenantAttribute is the attribute holding the tenant of a record, looked
+	// up on the record and then on its resource. Default: "tenant.id".
+	TenantAttribute string `mapstructure:"tenant_attribute"`
+
+	// ClassAttribute is the attribute set to the retention class.
+	// Default: "retention.class".
+	ClassAttribute string `mapstructure:"class_attribute"`
+
+	// LegalHoldAttribute is the attribute set to true on records under legal
+	// hold. Default: "retention.legal_hold".
+	LegalHoldAttribute string `mapstructure:"legal_hold_attribute"`
+
+	// DropExpired drops records whose timestamp is older than the period of
+	// their class. Records under legal hold, without a class or without a
+	// timestamp are never dropped. Default: false.
+	DropExpired bool `mapstructure:"drop_expired"`
+}
+
+// ClassConfig defines a retention class.
+type ClassConfig struct {
+	// Name is the value the class attribute is set to.
+	Name string `mapstructure:"name"`
+
+	// Period is how long records of the class are retained.
+	Period time.Duration `mapstructure:"period"`
+}
+
+// RuleConfig assigns a class or a legal hold to the records it matches. A
+// rule matches a record when all of its conditions hold.
+type RuleConfig struct {
+	// Tenants matches records of any of the listed tenants. Optional.
+	Tenants []string `mapstructure:"tenants"`
+
+	// Attributes matches records whose attributes, looked up on the record and
+	// then on its resource, match all of the given regular expressions.
+	// Optional.
+	Attributes map[string]string `mapstructure:"attributes"`
+
+	// Class is the retention class assigned to matched records. Optional.
+	Class string `mapstructure:"class"`
+
+	// LegalHold places matched records under legal hold. Default: false.
+	LegalHold bool `mapstructure:"legal_hold"`
+}
+
+// Validate checks the Config for invalid values.
+func (cfg *Config) Validate() error {
+	classes := make(map[string]bool, len(cfg.Classes))
+	for i, c := range cfg.Classes {
+		if c.Name == "" {
+			return fmt.Errorf("classes[%d]: name must not be empty", i)
+		}
+		if classes[c.Name] {
+			return fmt.Errorf("classes[%d]: duplicate class %q", i, c.Name)
+		}
+		if c.Period <= 0 {
+			return fmt.Errorf("classes[%d]: period must be > 0, got %s", i, c.Period)
+		}
+		classes[c.Name] = true
+	}
+	if cfg.DefaultClass != "" && !classes[cfg.DefaultClass] {
+		return fmt.Errorf("default_class: unknown class %q", cfg.DefaultClass)
+	}
+	for i, r := range cfg.Rules {
+		if r.Class == "" && !r.LegalHold {
+			return fmt.Errorf("rules[%d]: one of class or legal_hold must be set", i)
+		}
+		if r.Class != "" && !classes[r.Class] {
+			return fmt.Errorf("rules[%d]: unknown class %q", i, r.Class)
+		}
+		for key, expr := range r.Attributes {
+			if _, err := regexp.Compile(expr); err != nil {
+				return fmt.Errorf("rules[%d]: attribute %q: %w", i, key, err)
+			}
+		}
+	}
+	if cfg.DefaultClass == "" && len(cfg.Rules) == 0 {
+		return errors.New("at least one of default_class or rules must be set")
+	}
+	if cfg.ClassAttribute == "" {
+		return errors.New("class_attribute must not be empty")
+	}
+	if cfg.LegalHoldAttribute == "" {
+		return errors.New("legal_hold_attribute must not be empty")
+	}
+	return nil
+}
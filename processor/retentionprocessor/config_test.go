@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package retentionprocessor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/retentionprocessor/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr string
+	}{
+		{
+			id:          component.NewID(metadata.Type),
+			expectedErr: "at least one of default_class or rules must be set",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "full"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.Classes = []ClassConfig{
+					{Name: "standard", Period: 720 * time.Hour},
+					{Name: "security", Period: 8760 * time.Hour},
+				}
+				cfg.DefaultClass = "standard"
+				cfg.Rules = []RuleConfig{
+					{Attributes: map[string]string{"event.category": "^authentication$"}, Class: "security"},
+					{Tenants: []string{"acme"}, LegalHold: true},
+				}
+				cfg.TenantAttribute = "k8s.namespace.name"
+				cfg.DropExpired = true
+				return cfg
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "unknown_class"),
+			expectedErr: `rules[0]: unknown class "archive"`,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "empty_rule"),
+			expectedErr: "rules[0]: one of class or legal_hold must be set",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_period"),
+			expectedErr: "classes[0]: period must be > 0, got 0s",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_regexp"),
+			expectedErr: `rules[0]: attribute "event.category": error parsing regexp`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
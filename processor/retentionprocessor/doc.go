@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package retentionprocessor stamps retention classes and legal holds on log
+// records and drops records past their retention period.
+package retentionprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/retentionprocessor"
@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package retentionprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/retentionprocessor"
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/retentionprocessor/internal/metadata"
+)
+
+type rule struct {
+	tenants    []string
+	attributes map[string]*regexp.Regexp
+	class      string
+	legalHold  bool
+}
+
+type retentionProcessor struct {
+	config    *Config
+	logger    *zap.Logger
+	telemetry *metadata.TelemetryBuilder
+	now       func() time.Time
+
+	periods map[string]time.Duration
+	rules   []rule
+}
+
+func newRetentionProcessor(set processor.Settings, cfg *Config) (*retentionProcessor, error) {
+	tel, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := make(map[string]time.Duration, len(cfg.Classes))
+	for _, c := range cfg.Classes {
+		periods[c.Name] = c.Period
+	}
+	rules := make([]rule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		attrs := make(map[string]*regexp.Regexp, len(r.Attributes))
+		for key, expr := range r.Attributes {
+			// Error checked in Config.Validate()
+			attrs[key] = regexp.MustCompile(expr)
+		}
+		rules = append(rules, rule{
+			tenants:    r.Tenants,
+			attributes: attrs,
+			class:      r.Class,
+			legalHold:  r.LegalHold,
+		})
+	}
+
+	return &retentionProcessor{
+		config:    cfg,
+		logger:    set.Logger,
+		telemetry: tel,
+		now:       time.Now,
+		periods:   periods,
+		rules:     rules,
+	}, nil
+}
+
+// processLogs is the ConsumeLogs handler passed to processorhelper.NewLogs.
+func (p *retentionProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	now := p.now()
+	var expired int64
+
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		resourceAttrs := rl.Resource().Attributes()
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				if p.apply(lr, resourceAttrs, now) {
+					expired++
+					return true
+				}
+				return false
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+
+	if expired > 0 {
+		p.telemetry.ProcessorRetentionLogRecordsExpired.Add(ctx, expired)
+		p.logger.Debug("dropped expired log records", zap.Int64("count", expired))
+	}
+	if ld.ResourceLogs().Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+// apply stamps the class and legal hold of lr and reports whether it expired
+// and must be dropped. The class and legal hold attributes set by the sender
+// are removed first, so that only the rules set them: otherwise any sender
+// could exempt its records from drop_expired.
+func (p *retentionProcessor) apply(lr plog.LogRecord, resourceAttrs pcommon.Map, now time.Time) bool {
+	lr.Attributes().Remove(p.config.ClassAttribute)
+	lr.Attributes().Remove(p.config.LegalHoldAttribute)
+
+	class := ""
+	legalHold := false
+	for _, r := range p.rules {
+		if !p.matches(r, lr.Attributes(), resourceAttrs) {
+			continue
+		}
+		if class == "" {
+			class = r.class
+		}
+		legalHold = legalHold || r.legalHold
+	}
+	if class == "" {
+		class = p.config.DefaultClass
+	}
+
+	if class != "" {
+		lr.Attributes().PutStr(p.config.ClassAttribute, class)
+	}
+	if legalHold {
+		lr.Attributes().PutBool(p.config.LegalHoldAttribute, true)
+	}
+
+	if !p.config.DropExpired || legalHold || class == "" {
+		return false
+	}
+	ts := lr.Timestamp()
+	if ts == 0 {
+		return false
+	}
+	return ts.AsTime().Add(p.periods[class]).Before(now)
+}
+
+func (p *retentionProcessor) matches(r rule, recordAttrs, resourceAttrs pcommon.Map) bool {
+	if len(r.tenants) > 0 {
+		tenant, ok := lookup(p.config.TenantAttribute, recordAttrs, resourceAttrs)
+		if !ok || !slices.Contains(r.tenants, tenant) {
+			return false
+		}
+	}
+	for key, re := range r.attributes {
+		v, ok := lookup(key, recordAttrs, resourceAttrs)
+		if !ok || !re.MatchString(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func lookup(key string, maps ...pcommon.Map) (string, bool) {
+	for _, m := range maps {
+		if v, ok := m.Get(key); ok {
+			return v.AsString(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package retentionprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/retentionprocessor/internal/metadatatest"
+)
+
+var testNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Classes = []ClassConfig{
+		{Name: "standard", Period: 24 * time.Hour},
+		{Name: "security", Period: 30 * 24 * time.Hour},
+	}
+	cfg.DefaultClass = "standard"
+	cfg.Rules = []RuleConfig{
+		{Attributes: map[string]string{"event.category": "^authentication$"}, Class: "security"},
+		{Tenants: []string{"acme"}, LegalHold: true},
+		{Attributes: map[string]string{"event.category": "."}, Class: "standard"},
+	}
+	return cfg
+}
+
+func newTestProcessor(t *testing.T, cfg *Config, tel *componenttest.Telemetry) *retentionProcessor {
+	t.Helper()
+	require.NoError(t, cfg.Validate())
+	p, err := newRetentionProcessor(metadatatest.NewSettings(tel), cfg)
+	require.NoError(t, err)
+	p.now = func() time.Time { return testNow }
+	return p
+}
+
+type testRecord struct {
+	name      string
+	category  string
+	tenant    string
+	timestamp time.Time
+}
+
+func makeLogs(resourceTenant string, records ...testRecord) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	if resourceTenant != "" {
+		rl.Resource().Attributes().PutStr("tenant.id", resourceTenant)
+	}
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, r := range records {
+		lr := lrs.AppendEmpty()
+		lr.Attributes().PutStr("name", r.name)
+		if r.category != "" {
+			lr.Attributes().PutStr("event.category", r.category)
+		}
+		if r.tenant != "" {
+			lr.Attributes().PutStr("tenant.id", r.tenant)
+		}
+		if !r.timestamp.IsZero() {
+			lr.SetTimestamp(pcommon.NewTimestampFromTime(r.timestamp))
+		}
+	}
+	return ld
+}
+
+// stamps returns the class and legal hold of every record by name.
+func stamps(ld plog.Logs) map[string][2]any {
+	out := map[string][2]any{}
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		lrs := rls.At(i).ScopeLogs().At(0).LogRecords()
+		for j := 0; j < lrs.Len(); j++ {
+			attrs := lrs.At(j).Attributes().AsRaw()
+			out[attrs["name"].(string)] = [2]any{attrs["retention.class"], attrs["retention.legal_hold"]}
+		}
+	}
+	return out
+}
+
+func TestStampsClassesAndLegalHolds(t *testing.T) {
+	p := newTestProcessor(t, testConfig(), componenttest.NewTelemetry())
+
+	out, err := p.processLogs(t.Context(), makeLogs("",
+		testRecord{name: "login", category: "authentication"},
+		testRecord{name: "read", category: "file"},
+		testRecord{name: "other"},
+		testRecord{name: "acme-login", category: "authentication", tenant: "acme"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][2]any{
+		"login":      {"security", nil},
+		"read":       {"standard", nil},
+		"other":      {"standard", nil},
+		"acme-login": {"security", true},
+	}, stamps(out))
+
+	// The tenant is also looked up on the resource.
+	out, err = p.processLogs(t.Context(), makeLogs("acme", testRecord{name: "read", category: "file"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][2]any{"read": {"standard", true}}, stamps(out))
+}
+
+func TestNoDefaultClass(t *testing.T) {
+	cfg := testConfig()
+	cfg.DefaultClass = ""
+	p := newTestProcessor(t, cfg, componenttest.NewTelemetry())
+
+	out, err := p.processLogs(t.Context(), makeLogs("", testRecord{name: "other"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][2]any{"other": {nil, nil}}, stamps(out))
+}
+
+func TestDropExpired(t *testing.T) {
+	cfg := testConfig()
+	cfg.DropExpired = true
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	p := newTestProcessor(t, cfg, tel)
+
+	twoDaysAgo := testNow.Add(-48 * time.Hour)
+	out, err := p.processLogs(t.Context(), makeLogs("",
+		testRecord{name: "fresh", timestamp: testNow.Add(-time.Hour)},
+		testRecord{name: "expired", timestamp: twoDaysAgo},
+		testRecord{name: "security", category: "authentication", timestamp: twoDaysAgo},
+		testRecord{name: "held", tenant: "acme", timestamp: twoDaysAgo},
+		testRecord{name: "untimed"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][2]any{
+		"fresh":    {"standard", nil},
+		"security": {"security", nil},
+		"held":     {"standard", true},
+		"untimed":  {"standard", nil},
+	}, stamps(out))
+
+	metadatatest.AssertEqualProcessorRetentionLogRecordsExpired(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 1},
+	}, metricdatatest.IgnoreTimestamp())
+
+	_, err = p.processLogs(t.Context(), makeLogs("", testRecord{name: "expired", timestamp: twoDaysAgo}))
+	assert.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+}
+
+func TestIgnoresSenderStamps(t *testing.T) {
+	cfg := testConfig()
+	cfg.DefaultClass = ""
+	cfg.DropExpired = true
+	p := newTestProcessor(t, cfg, componenttest.NewTelemetry())
+
+	ld := makeLogs("",
+		testRecord{name: "forged", category: "file", timestamp: testNow.Add(-48 * time.Hour)},
+		testRecord{name: "unclassified"},
+	)
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < lrs.Len(); i++ {
+		lrs.At(i).Attributes().PutStr("retention.class", "security")
+		lrs.At(i).Attributes().PutBool("retention.legal_hold", true)
+	}
+
+	// The sender cannot place a hold: the expired record is dropped.
+	out, err := p.processLogs(t.Context(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][2]any{"unclassified": {nil, nil}}, stamps(out))
+}
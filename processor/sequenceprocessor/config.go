@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sequenceprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sequenceprocessor"
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines configuration for the sequence processor.
+type Config struct {
+	// StreamAttributes are the attribute keys whose values identify the
+	// stream of a record, looked up on the record and then on its resource.
+	// Each stream has its own counter. A missing attribute counts as an empty
+	// value. Default: ["tenant.id", "service.name"].
+	StreamAttributes []string `mapstructure:"stream_attributes"`
+
+	// SequenceAttribute is the attribute set to the sequence number of a
+	// record. Default: "log.record.sequence".
+	SequenceAttribute string `mapstructure:"sequence_attribute"`
+
+	// MaxStreams bounds the number of stream counters held in memory. When
+	// the limit is reached the least recently used counter is evicted: it is
+	// read again from storage when its stream comes back, or restarts at 1
+	// without storage. 0 means unlimited. Default: 100000.
+	MaxStreams int `mapstructure:"max_streams"`
+
+	// Storage is the ID of a storage extension persisting the counters. They
+	// are written before a batch is passed on, so sequence numbers are never
+	// reused after a restart. Optional — when unset, counters restart at 1.
+	Storage *component.ID `mapstructure:"storage"`
+}
+
+// Validate checks the Config for invalid values.
+func (cfg *Config) Validate() error {
+	if slices.Contains(cfg.StreamAttributes, "") {
+		return errors.New("stream_attributes must not contain empty keys")
+	}
+	if cfg.SequenceAttribute == "" {
+		return errors.New("sequence_attribute must not be empty")
+	}
+	if slices.Contains(cfg.StreamAttributes, cfg.SequenceAttribute) {
+		return errors.New("sequence_attribute must not be a stream attribute")
+	}
+	if cfg.MaxStreams < 0 {
+		return fmt.Errorf("max_streams must be >= 0, got %d", cfg.MaxStreams)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package sequenceprocessor assigns strictly monotonic sequence numbers to
+// log records, per stream.
+package sequenceprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sequenceprocessor"
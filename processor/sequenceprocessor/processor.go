@@ -0,0 +1,208 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sequenceprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/sequenceprocessor"
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/sequenceprocessor/internal/metadata"
+)
+
+const storageKeyPrefix = "stream/"
+
+// streamCounter is the last number assigned in a stream.
+type streamCounter struct {
+	key string
+	seq uint64
+}
+
+type sequenceProcessor struct {
+	config      *Config
+	componentID component.ID
+	logger      *zap.Logger
+	telemetry   *metadata.TelemetryBuilder
+
+	// mu serializes batches, so that sequence numbers are persisted in the
+	// order they are assigned.
+	mu sync.Mutex
+	// counters holds the counters of the streams by key, in recently used
+	// order, the least recently used at the back.
+	counters map[string]*list.Element
+	recent   *list.List
+
+	storageClient storage.Client
+}
+
+func newSequenceProcessor(set processor.Settings, cfg *Config) (*sequenceProcessor, error) {
+	tel, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sequenceProcessor{
+		config:      cfg,
+		componentID: set.ID,
+		logger:      set.Logger,
+		telemetry:   tel,
+		counters:    map[string]*list.Element{},
+		recent:      list.New(),
+	}, nil
+}
+
+// Start opens the storage client when configured. Counters are loaded from
+// storage when their stream is first seen.
+func (p *sequenceProcessor) Start(ctx context.Context, host component.Host) error {
+	if p.config.Storage == nil {
+		p.logger.Warn("No storage configured, sequence numbers restart at 1 when the collector restarts")
+		return nil
+	}
+
+	var err error
+	p.storageClient, err = getStorageClient(ctx, host, p.config.Storage, p.componentID)
+	if err != nil {
+		return fmt.Errorf("failed to get storage client: %w", err)
+	}
+	return nil
+}
+
+// Shutdown closes the storage client.
+func (p *sequenceProcessor) Shutdown(ctx context.Context) error {
+	if p.storageClient == nil {
+		return nil
+	}
+	return p.storageClient.Close(ctx)
+}
+
+// processLogs is the ConsumeLogs handler passed to processorhelper.NewLogs.
+func (p *sequenceProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Assign numbers from a copy of the touched counters, committed only once
+	// persisted: a refused batch does not consume sequence numbers.
+	next := map[string]uint64{}
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lr := lrs.At(k)
+				key := p.streamKey(lr.Attributes(), rl.Resource().Attributes())
+				seq, ok := next[key]
+				if !ok {
+					var err error
+					if seq, err = p.counter(ctx, key); err != nil {
+						return ld, err
+					}
+				}
+				seq++
+				next[key] = seq
+				lr.Attributes().PutInt(p.config.SequenceAttribute, int64(seq))
+			}
+		}
+	}
+
+	if err := p.persist(ctx, next); err != nil {
+		return ld, err
+	}
+	evicted := 0
+	for key, seq := range next {
+		evicted += p.setCounter(key, seq)
+	}
+	if evicted > 0 {
+		p.telemetry.ProcessorSequenceStreamsEvicted.Add(ctx, int64(evicted))
+	}
+	p.telemetry.ProcessorSequenceStreams.Record(ctx, int64(len(p.counters)))
+	return ld, nil
+}
+
+// setCounter updates the counter of a stream and marks it as the most
+// recently used, evicting the least recently used counters beyond
+// max_streams. It returns the number of evicted counters. Must be called with
+// p.mu held.
+func (p *sequenceProcessor) setCounter(key string, seq uint64) int {
+	if elem, ok := p.counters[key]; ok {
+		elem.Value.(*streamCounter).seq = seq
+		p.recent.MoveToFront(elem)
+		return 0
+	}
+	p.counters[key] = p.recent.PushFront(&streamCounter{key: key, seq: seq})
+
+	evicted := 0
+	for p.config.MaxStreams > 0 && len(p.counters) > p.config.MaxStreams {
+		oldest := p.recent.Remove(p.recent.Back()).(*streamCounter)
+		delete(p.counters, oldest.key)
+		evicted++
+	}
+	return evicted
+}
+
+// streamKey encodes the stream attribute values of a record.
+func (p *sequenceProcessor) streamKey(recordAttrs, resourceAttrs pcommon.Map) string {
+	values := make([]string, len(p.config.StreamAttributes))
+	for i, key := range p.config.StreamAttributes {
+		if v, ok := recordAttrs.Get(key); ok {
+			values[i] = v.AsString()
+		} else if v, ok := resourceAttrs.Get(key); ok {
+			values[i] = v.AsString()
+		}
+	}
+	// A JSON array keeps the key unambiguous whatever the values contain.
+	key, _ := json.Marshal(values)
+	return string(key)
+}
+
+// counter returns the last number assigned in a stream, reading it from
+// storage when the stream is not held in memory. Must be called with p.mu
+// held.
+func (p *sequenceProcessor) counter(ctx context.Context, key string) (uint64, error) {
+	if elem, ok := p.counters[key]; ok {
+		return elem.Value.(*streamCounter).seq, nil
+	}
+	if p.storageClient == nil {
+		return 0, nil
+	}
+	data, err := p.storageClient.Get(ctx, storageKeyPrefix+key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sequence counter: %w", err)
+	}
+	var seq uint64
+	switch len(data) {
+	case 0:
+	case 8:
+		seq = binary.BigEndian.Uint64(data)
+	default:
+		return 0, fmt.Errorf("invalid sequence counter of stream %s", key)
+	}
+	return seq, nil
+}
+
+// persist writes the counters of a batch in a single storage operation.
+func (p *sequenceProcessor) persist(ctx context.Context, counters map[string]uint64) error {
+	if p.storageClient == nil || len(counters) == 0 {
+		return nil
+	}
+	ops := make([]*storage.Operation, 0, len(counters))
+	for key, seq := range counters {
+		ops = append(ops, storage.SetOperation(storageKeyPrefix+key, binary.BigEndian.AppendUint64(nil, seq)))
+	}
+	if err := p.storageClient.Batch(ctx, ops...); err != nil {
+		return fmt.Errorf("failed to persist sequence counters: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sequenceprocessor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/sequenceprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/sequenceprocessor/internal/metadatatest"
+)
+
+func storageID() *component.ID {
+	id := storagetest.NewStorageID("test")
+	return &id
+}
+
+func newTestProcessor(t *testing.T, cfg *Config) *sequenceProcessor {
+	t.Helper()
+	require.NoError(t, cfg.Validate())
+	p, err := newSequenceProcessor(processortest.NewNopSettings(metadata.Type), cfg)
+	require.NoError(t, err)
+	return p
+}
+
+// makeLogs creates one resource per service, holding one record per tenant.
+func makeLogs(service string, tenants ...string) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", service)
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, tenant := range tenants {
+		lr := lrs.AppendEmpty()
+		if tenant != "" {
+			lr.Attributes().PutStr("tenant.id", tenant)
+		}
+	}
+	return ld
+}
+
+func sequences(ld plog.Logs) []int64 {
+	var out []int64
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < lrs.Len(); i++ {
+		v, _ := lrs.At(i).Attributes().Get("log.record.sequence")
+		out = append(out, v.Int())
+	}
+	return out
+}
+
+func TestAssignsPerStream(t *testing.T) {
+	p := newTestProcessor(t, createDefaultConfig().(*Config))
+	require.NoError(t, p.Start(t.Context(), componenttest.NewNopHost()))
+
+	out, err := p.processLogs(t.Context(), makeLogs("api", "acme", "acme", "globex", ""))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 1, 1}, sequences(out))
+
+	out, err = p.processLogs(t.Context(), makeLogs("api", "globex", "acme"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, sequences(out))
+
+	out, err = p.processLogs(t.Context(), makeLogs("web", "acme"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, sequences(out))
+	require.NoError(t, p.Shutdown(t.Context()))
+}
+
+func TestPersistsAcrossRestarts(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = storageID()
+
+	p1 := newTestProcessor(t, cfg)
+	require.NoError(t, p1.Start(t.Context(), host))
+	out, err := p1.processLogs(t.Context(), makeLogs("api", "acme", "acme", "globex"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 1}, sequences(out))
+	require.NoError(t, p1.Shutdown(t.Context()))
+
+	p2 := newTestProcessor(t, cfg)
+	require.NoError(t, p2.Start(t.Context(), host))
+	t.Cleanup(func() { require.NoError(t, p2.Shutdown(t.Context())) })
+	out, err = p2.processLogs(t.Context(), makeLogs("api", "acme", "globex", "initech"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 2, 1}, sequences(out))
+}
+
+func TestEvictsLeastRecentlyUsedStreams(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxStreams = 2
+	cfg.Storage = storageID()
+	p, err := newSequenceProcessor(metadatatest.NewSettings(tel), cfg)
+	require.NoError(t, err)
+	p.storageClient = storagetest.NewInMemoryClient(component.KindProcessor, *storageID(), "")
+
+	_, err = p.processLogs(t.Context(), makeLogs("api", "acme", "globex"))
+	require.NoError(t, err)
+	_, err = p.processLogs(t.Context(), makeLogs("api", "acme"))
+	require.NoError(t, err)
+	_, err = p.processLogs(t.Context(), makeLogs("api", "initech"))
+	require.NoError(t, err)
+	assert.Len(t, p.counters, 2)
+	assert.NotContains(t, p.counters, `["globex","api"]`)
+
+	// An evicted stream continues from its persisted counter.
+	out, err := p.processLogs(t.Context(), makeLogs("api", "globex"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, sequences(out))
+	assert.NotContains(t, p.counters, `["acme","api"]`)
+
+	metadatatest.AssertEqualProcessorSequenceStreamsEvicted(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 2},
+	}, metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualProcessorSequenceStreams(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 2},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+// failingClient fails batches while fail is set.
+type failingClient struct {
+	storage.Client
+	fail bool
+}
+
+func (c *failingClient) Batch(ctx context.Context, ops ...*storage.Operation) error {
+	if c.fail {
+		return errors.New("disk full")
+	}
+	return c.Client.Batch(ctx, ops...)
+}
+
+func TestRefusedBatchDoesNotConsumeNumbers(t *testing.T) {
+	p := newTestProcessor(t, createDefaultConfig().(*Config))
+	client := &failingClient{Client: storagetest.NewInMemoryClient(component.KindProcessor, *storageID(), "")}
+	p.storageClient = client
+
+	_, err := p.processLogs(t.Context(), makeLogs("api", "acme"))
+	require.NoError(t, err)
+
+	client.fail = true
+	_, err = p.processLogs(t.Context(), makeLogs("api", "acme", "acme"))
+	assert.EqualError(t, err, "failed to persist sequence counters: disk full")
+
+	client.fail = false
+	out, err := p.processLogs(t.Context(), makeLogs("api", "acme"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, sequences(out))
+}
+
+func TestStartWithMissingStorage(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Storage = storageID()
+	p := newTestProcessor(t, cfg)
+	assert.ErrorContains(t, p.Start(t.Context(), componenttest.NewNopHost()), "storage extension")
+}
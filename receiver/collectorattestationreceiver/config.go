@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package collectorattestationreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/collectorattestationreceiver"
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines configuration for the collector attestation receiver.
+type Config struct {
+	// Identity is the ID of the collector identity extension signing the
+	// attestations.
+	Identity component.ID `mapstructure:"identity"`
+
+	// InitialDelay is the delay before the first attestation, leaving time
+	// for the effective configuration to be reported to the extension.
+	// Default: 1s.
+	InitialDelay time.Duration `mapstructure:"initial_delay"`
+
+	// Interval between two attestations. Default: 1h.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Validate checks if the receiver configuration is valid.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.Identity.Type().String() == "" {
+		errs = append(errs, errors.New("identity must be specified"))
+	}
+	if cfg.InitialDelay < 0 {
+		errs = append(errs, errors.New("initial_delay must not be negative"))
+	}
+	if cfg.Interval <= 0 {
+		errs = append(errs, errors.New("interval must be positive"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package collectorattestationreceiver periodically emits the signed
+// self-attestation records of the collector identity extension.
+package collectorattestationreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/collectorattestationreceiver"
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package collectorattestationreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/collectorattestationreceiver"
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/collectoridentityextension"
+)
+
+const dataFormat = "attestation"
+
+type attestationReceiver struct {
+	settings receiver.Settings
+	cfg      *Config
+	next     consumer.Logs
+	obsrecv  *receiverhelper.ObsReport
+
+	provider   collectoridentityextension.IdentityProvider
+	cancel     context.CancelFunc
+	shutdownWG sync.WaitGroup
+}
+
+func newAttestationReceiver(set receiver.Settings, cfg *Config, next consumer.Logs) (*attestationReceiver, error) {
+	obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{
+		ReceiverID:             set.ID,
+		ReceiverCreateSettings: set,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &attestationReceiver{
+		settings: set,
+		cfg:      cfg,
+		next:     next,
+		obsrecv:  obsrecv,
+	}, nil
+}
+
+// Start resolves the identity extension and starts emitting attestations.
+func (r *attestationReceiver) Start(_ context.Context, host component.Host) error {
+	ext, ok := host.GetExtensions()[r.cfg.Identity]
+	if !ok {
+		return fmt.Errorf("identity extension %q not found", r.cfg.Identity)
+	}
+	provider, ok := ext.(collectoridentityextension.IdentityProvider)
+	if !ok {
+		return fmt.Errorf("extension %q is not a collector identity extension", r.cfg.Identity)
+	}
+	r.provider = provider
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.shutdownWG.Go(func() { r.attestationLoop(ctx) })
+	return nil
+}
+
+// Shutdown stops emitting attestations.
+func (r *attestationReceiver) Shutdown(context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.shutdownWG.Wait()
+	return nil
+}
+
+func (r *attestationReceiver) attestationLoop(ctx context.Context) {
+	timer := time.NewTimer(r.cfg.InitialDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		r.attest(ctx)
+		timer.Reset(r.cfg.Interval)
+	}
+}
+
+// attest emits one attestation. Failures are logged: the next attestation is
+// attempted at the next interval.
+func (r *attestationReceiver) attest(ctx context.Context) {
+	ld, err := r.provider.Attest()
+	if err != nil {
+		r.settings.Logger.Warn("Failed to create attestation", zap.Error(err))
+		return
+	}
+	ctx = r.obsrecv.StartLogsOp(ctx)
+	err = r.next.ConsumeLogs(ctx, ld)
+	r.obsrecv.EndLogsOp(ctx, dataFormat, ld.LogRecordCount(), err)
+	if err != nil {
+		r.settings.Logger.Warn("Failed to emit attestation", zap.Error(err))
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package collectorattestationreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/collectoridentityextension"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/collectorattestationreceiver/internal/metadata"
+)
+
+var identityID = component.MustNewID("collector_identity")
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func newIdentityHost(t *testing.T) (*testHost, collectoridentityextension.IdentityProvider) {
+	t.Helper()
+	factory := collectoridentityextension.NewFactory()
+	ext, err := factory.Create(t.Context(), extensiontest.NewNopSettings(factory.Type()), factory.CreateDefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+	return &testHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{identityID: ext},
+	}, ext.(collectoridentityextension.IdentityProvider)
+}
+
+func TestEmitsAttestations(t *testing.T) {
+	host, provider := newIdentityHost(t)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Identity = identityID
+	cfg.InitialDelay = 0
+	cfg.Interval = 10 * time.Millisecond
+
+	sink := &consumertest.LogsSink{}
+	rcv, err := NewFactory().CreateLogs(t.Context(), receivertest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcv.Start(t.Context(), host))
+	require.Eventually(t, func() bool { return sink.LogRecordCount() >= 2 }, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, rcv.Shutdown(t.Context()))
+
+	for _, ld := range sink.AllLogs() {
+		lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		assert.Equal(t, collectoridentityextension.AttestationEventName, lr.EventName())
+		id, err := collectoridentityextension.VerifyAttestation(lr)
+		require.NoError(t, err)
+		assert.Equal(t, provider.Identity().ID, id)
+	}
+}
+
+func TestIdentityExtensionErrors(t *testing.T) {
+	host, _ := newIdentityHost(t)
+	host.extensions[component.MustNewID("other")] = struct {
+		component.StartFunc
+		component.ShutdownFunc
+	}{}
+
+	tests := []struct {
+		identity    component.ID
+		expectedErr string
+	}{
+		{
+			identity:    component.MustNewIDWithName("collector_identity", "missing"),
+			expectedErr: `identity extension "collector_identity/missing" not found`,
+		},
+		{
+			identity:    component.MustNewID("other"),
+			expectedErr: `extension "other" is not a collector identity extension`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.identity.String(), func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Identity = tt.identity
+			rcv, err := NewFactory().CreateLogs(t.Context(), receivertest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+			require.NoError(t, err)
+			assert.EqualError(t, rcv.Start(t.Context(), host), tt.expectedErr)
+			require.NoError(t, rcv.Shutdown(t.Context()))
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package intotoreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/intotoreceiver"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// Config defines configuration for the in-toto attestation receiver.
+type Config struct {
+	confighttp.ServerConfig `mapstructure:",squash"`
+
+	// Path is the URL path attestation bundles are posted to.
+	// Default: /v1/attestations.
+	Path string `mapstructure:"path"`
+
+	// Keys are the public keys trusted to sign DSSE envelopes.
+	Keys []KeyConfig `mapstructure:"keys"`
+
+	// Threshold is the number of distinct trusted keys that must have signed
+	// an envelope for it to be accepted. Default: 1.
+	Threshold int `mapstructure:"threshold"`
+}
+
+// KeyConfig configures a trusted public key. Exactly one of File and PEM must
+// be set. ECDSA, Ed25519 and RSA keys in PKIX form are supported.
+type KeyConfig struct {
+	// ID is matched against the keyid of envelope signatures. Signatures
+	// without a keyid are checked against every trusted key.
+	ID string `mapstructure:"id"`
+
+	// File is the path to a PEM-encoded public key.
+	File string `mapstructure:"file"`
+
+	// PEM is a PEM-encoded public key.
+	PEM string `mapstructure:"pem"`
+}
+
+// Validate checks the Config for invalid values.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.NetAddr.Endpoint == "" {
+		errs = append(errs, errors.New("endpoint must be specified"))
+	}
+	if !strings.HasPrefix(cfg.Path, "/") {
+		errs = append(errs, errors.New("path must start with /"))
+	}
+	if len(cfg.Keys) == 0 {
+		errs = append(errs, errors.New("at least one key must be configured"))
+	}
+	ids := map[string]bool{}
+	sources := map[string]bool{}
+	for i, key := range cfg.Keys {
+		if key.ID == "" {
+			errs = append(errs, fmt.Errorf("keys[%d]: id must be specified", i))
+		} else if ids[key.ID] {
+			errs = append(errs, fmt.Errorf("keys[%d]: duplicate id %q", i, key.ID))
+		}
+		ids[key.ID] = true
+		switch {
+		case (key.File == "") == (key.PEM == ""):
+			errs = append(errs, fmt.Errorf("keys[%d]: exactly one of file or pem must be specified", i))
+		case key.File != "" && sources["file:"+key.File]:
+			// A signature by the same key counts once towards the threshold.
+			errs = append(errs, fmt.Errorf("keys[%d]: duplicate file %q", i, key.File))
+		case key.PEM != "" && sources["pem:"+key.PEM]:
+			errs = append(errs, fmt.Errorf("keys[%d]: duplicate pem", i))
+		}
+		sources["file:"+key.File] = true
+		sources["pem:"+key.PEM] = true
+	}
+	if cfg.Threshold < 1 {
+		errs = append(errs, errors.New("threshold must be at least 1"))
+	} else if len(cfg.Keys) > 0 && cfg.Threshold > len(cfg.Keys) {
+		errs = append(errs, fmt.Errorf("threshold %d exceeds the number of keys (%d)", cfg.Threshold, len(cfg.Keys)))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package intotoreceiver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/intotoreceiver/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id          component.ID
+		expected    func() *Config
+		expectedErr string
+	}{
+		{
+			id: component.NewID(metadata.Type),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.Keys = []KeyConfig{{ID: "builder", File: "testdata/ed25519.pem"}}
+				return cfg
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "custom"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.NetAddr.Endpoint = "0.0.0.0:9443"
+				cfg.Path = "/attestations"
+				cfg.Threshold = 2
+				cfg.Keys = []KeyConfig{
+					{ID: "builder", File: "testdata/ed25519.pem"},
+					{ID: "release", PEM: "-----BEGIN PUBLIC KEY-----\nMCowBQYDK2VwAyEA5ihMWgs162ADDQS97wVFpsRKDahPzPF4TgHnqUumONQ=\n-----END PUBLIC KEY-----\n"},
+				}
+				return cfg
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "nokeys"),
+			expectedErr: "at least one key must be configured",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "threshold"),
+			expectedErr: "threshold 2 exceeds the number of keys (1)",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "keysource"),
+			expectedErr: "keys[0]: exactly one of file or pem must be specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, xconfmap.Validate(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected(), cfg)
+		})
+	}
+}
+
+func TestValidateDuplicateKeyID(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Keys = []KeyConfig{
+		{ID: "builder", File: "a.pem"},
+		{ID: "builder", File: "b.pem"},
+	}
+	assert.EqualError(t, cfg.Validate(), `keys[1]: duplicate id "builder"`)
+}
+
+func TestValidateDuplicateKey(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Keys = []KeyConfig{
+		{ID: "builder", File: "a.pem"},
+		{ID: "release", File: "a.pem"},
+		{ID: "signer", PEM: "key"},
+		{ID: "other", PEM: "key"},
+	}
+	assert.EqualError(t, cfg.Validate(), `keys[1]: duplicate file "a.pem"`+"\nkeys[3]: duplicate pem")
+}
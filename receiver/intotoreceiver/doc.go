@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package intotoreceiver implements a receiver that accepts in-toto
+// attestations wrapped in DSSE envelopes over HTTP, verifies their signatures
+// and converts the attested statements into log records.
+package intotoreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/intotoreceiver"
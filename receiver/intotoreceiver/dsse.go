@@ -0,0 +1,183 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package intotoreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/intotoreceiver"
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// envelope is a DSSE envelope, see
+// https://github.com/secure-systems-lab/dsse/blob/master/envelope.md.
+type envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []signature `json:"signatures"`
+}
+
+type signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// pae returns the DSSE pre-authentication encoding of a payload, which is the
+// message signatures are computed over.
+func pae(payloadType string, payload []byte) []byte {
+	buf := make([]byte, 0, 32+len(payloadType)+len(payload))
+	buf = append(buf, "DSSEv1 "...)
+	buf = strconv.AppendInt(buf, int64(len(payloadType)), 10)
+	buf = append(buf, ' ')
+	buf = append(buf, payloadType...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(len(payload)), 10)
+	buf = append(buf, ' ')
+	buf = append(buf, payload...)
+	return buf
+}
+
+// decodeBase64 accepts both the standard and the URL-safe base64 alphabets,
+// with or without padding, as allowed by the DSSE specification.
+func decodeBase64(s string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, errors.New("invalid base64 encoding")
+}
+
+// trustedKey is a public key envelopes may be signed with.
+type trustedKey struct {
+	id  string
+	key crypto.PublicKey
+}
+
+func loadTrustedKeys(cfgs []KeyConfig) ([]trustedKey, error) {
+	keys := make([]trustedKey, 0, len(cfgs))
+	ids := map[string]string{}
+	for _, cfg := range cfgs {
+		data := []byte(cfg.PEM)
+		if cfg.File != "" {
+			var err error
+			if data, err = os.ReadFile(cfg.File); err != nil {
+				return nil, fmt.Errorf("failed to read key %q: %w", cfg.ID, err)
+			}
+		}
+		key, err := parsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %q: %w", cfg.ID, err)
+		}
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %q: %w", cfg.ID, err)
+		}
+		if other, ok := ids[string(der)]; ok {
+			// A signature would count once for every ID of the key towards
+			// the threshold.
+			return nil, fmt.Errorf("key %q: same public key as key %q", cfg.ID, other)
+		}
+		ids[string(der)] = cfg.ID
+		keys = append(keys, trustedKey{id: cfg.ID, key: key})
+	}
+	return keys, nil
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// verify reports whether sig is a valid signature of msg by key.
+func verify(key crypto.PublicKey, msg, sig []byte) bool {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, msg, sig)
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, ecdsaDigest(k.Curve, msg), sig)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(msg)
+		return rsa.VerifyPSS(k, crypto.SHA256, digest[:], sig, nil) == nil ||
+			rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	default:
+		return false
+	}
+}
+
+// ecdsaDigest hashes msg with the hash function conventionally paired with
+// the curve.
+func ecdsaDigest(curve elliptic.Curve, msg []byte) []byte {
+	switch curve {
+	case elliptic.P384():
+		d := sha512.Sum384(msg)
+		return d[:]
+	case elliptic.P521():
+		d := sha512.Sum512(msg)
+		return d[:]
+	default:
+		d := sha256.Sum256(msg)
+		return d[:]
+	}
+}
+
+// verifyEnvelope checks the envelope signatures against the trusted keys and
+// returns the decoded payload together with the IDs of the keys that signed
+// it. It fails unless at least threshold distinct keys signed the envelope.
+// Signatures by unknown keys are ignored.
+func verifyEnvelope(env *envelope, keys []trustedKey, threshold int) ([]byte, []string, error) {
+	if env.PayloadType == "" {
+		return nil, nil, errors.New("envelope has no payloadType")
+	}
+	payload, err := decodeBase64(env.Payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("envelope payload: %w", err)
+	}
+	msg := pae(env.PayloadType, payload)
+
+	signed := make(map[string]bool, len(keys))
+	var keyIDs []string
+	for _, s := range env.Signatures {
+		sig, err := decodeBase64(s.Sig)
+		if err != nil {
+			continue
+		}
+		for _, k := range keys {
+			if signed[k.id] || (s.KeyID != "" && s.KeyID != k.id) {
+				continue
+			}
+			if verify(k.key, msg, sig) {
+				signed[k.id] = true
+				keyIDs = append(keyIDs, k.id)
+				break
+			}
+		}
+	}
+	if len(keyIDs) < threshold {
+		return nil, nil, fmt.Errorf("envelope is signed by %d trusted keys, %d required", len(keyIDs), threshold)
+	}
+	return payload, keyIDs, nil
+}
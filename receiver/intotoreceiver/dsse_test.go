@@ -0,0 +1,243 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package intotoreceiver
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigner signs DSSE envelopes with a freshly generated key.
+type testSigner struct {
+	id   string
+	sign func(msg []byte) []byte
+	pub  crypto.PublicKey
+}
+
+func newEd25519Signer(t *testing.T, id string) *testSigner {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &testSigner{id: id, pub: pub, sign: func(msg []byte) []byte {
+		return ed25519.Sign(priv, msg)
+	}}
+}
+
+func newECDSASigner(t *testing.T, id string, curve elliptic.Curve) *testSigner {
+	priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	return &testSigner{id: id, pub: &priv.PublicKey, sign: func(msg []byte) []byte {
+		sig, err := ecdsa.SignASN1(rand.Reader, priv, ecdsaDigest(curve, msg))
+		require.NoError(t, err)
+		return sig
+	}}
+}
+
+func newRSASigner(t *testing.T, id string) *testSigner {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return &testSigner{id: id, pub: &priv.PublicKey, sign: func(msg []byte) []byte {
+		digest := sha256.Sum256(msg)
+		sig, err := rsa.SignPSS(rand.Reader, priv, crypto.SHA256, digest[:], nil)
+		require.NoError(t, err)
+		return sig
+	}}
+}
+
+func (s *testSigner) pem(t *testing.T) string {
+	der, err := x509.MarshalPKIXPublicKey(s.pub)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func (s *testSigner) trusted() trustedKey {
+	return trustedKey{id: s.id, key: s.pub}
+}
+
+func signEnvelope(payloadType string, payload []byte, signers ...*testSigner) *envelope {
+	env := &envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+	}
+	for _, s := range signers {
+		env.Signatures = append(env.Signatures, signature{
+			KeyID: s.id,
+			Sig:   base64.StdEncoding.EncodeToString(s.sign(pae(payloadType, payload))),
+		})
+	}
+	return env
+}
+
+func TestPAE(t *testing.T) {
+	// Test vector from the DSSE specification.
+	assert.Equal(t, "DSSEv1 29 http://example.com/HelloWorld 11 hello world",
+		string(pae("http://example.com/HelloWorld", []byte("hello world"))))
+}
+
+func TestVerifyEnvelopeKeyTypes(t *testing.T) {
+	signers := []*testSigner{
+		newEd25519Signer(t, "ed25519"),
+		newECDSASigner(t, "p256", elliptic.P256()),
+		newECDSASigner(t, "p384", elliptic.P384()),
+		newRSASigner(t, "rsa"),
+	}
+	for _, s := range signers {
+		t.Run(s.id, func(t *testing.T) {
+			key, err := parsePublicKey([]byte(s.pem(t)))
+			require.NoError(t, err)
+
+			env := signEnvelope(inTotoPayloadType, []byte(`{}`), s)
+			payload, keyIDs, err := verifyEnvelope(env, []trustedKey{{id: s.id, key: key}}, 1)
+			require.NoError(t, err)
+			assert.Equal(t, []byte(`{}`), payload)
+			assert.Equal(t, []string{s.id}, keyIDs)
+		})
+	}
+}
+
+func TestVerifyEnvelope(t *testing.T) {
+	builder := newEd25519Signer(t, "builder")
+	release := newEd25519Signer(t, "release")
+	untrusted := newEd25519Signer(t, "untrusted")
+	keys := []trustedKey{builder.trusted(), release.trusted()}
+	payload := []byte(`{"_type":"https://in-toto.io/Statement/v1"}`)
+
+	tests := []struct {
+		name        string
+		env         func() *envelope
+		threshold   int
+		expectedIDs []string
+		expectedErr string
+	}{
+		{
+			name:        "single signature",
+			env:         func() *envelope { return signEnvelope(inTotoPayloadType, payload, builder) },
+			threshold:   1,
+			expectedIDs: []string{"builder"},
+		},
+		{
+			name:        "threshold met",
+			env:         func() *envelope { return signEnvelope(inTotoPayloadType, payload, builder, untrusted, release) },
+			threshold:   2,
+			expectedIDs: []string{"builder", "release"},
+		},
+		{
+			name:        "threshold not met",
+			env:         func() *envelope { return signEnvelope(inTotoPayloadType, payload, builder, untrusted) },
+			threshold:   2,
+			expectedErr: "envelope is signed by 1 trusted keys, 2 required",
+		},
+		{
+			name:        "same key twice",
+			env:         func() *envelope { return signEnvelope(inTotoPayloadType, payload, builder, builder) },
+			threshold:   2,
+			expectedErr: "envelope is signed by 1 trusted keys, 2 required",
+		},
+		{
+			name: "signature without keyid",
+			env: func() *envelope {
+				env := signEnvelope(inTotoPayloadType, payload, release)
+				env.Signatures[0].KeyID = ""
+				return env
+			},
+			threshold:   1,
+			expectedIDs: []string{"release"},
+		},
+		{
+			name: "keyid of another key",
+			env: func() *envelope {
+				env := signEnvelope(inTotoPayloadType, payload, release)
+				env.Signatures[0].KeyID = "builder"
+				return env
+			},
+			threshold:   1,
+			expectedErr: "envelope is signed by 0 trusted keys, 1 required",
+		},
+		{
+			name: "tampered payload",
+			env: func() *envelope {
+				env := signEnvelope(inTotoPayloadType, payload, builder)
+				env.Payload = base64.StdEncoding.EncodeToString([]byte(`{"_type":"tampered"}`))
+				return env
+			},
+			threshold:   1,
+			expectedErr: "envelope is signed by 0 trusted keys, 1 required",
+		},
+		{
+			name: "tampered payload type",
+			env: func() *envelope {
+				env := signEnvelope(inTotoPayloadType, payload, builder)
+				env.PayloadType = "application/json"
+				return env
+			},
+			threshold:   1,
+			expectedErr: "envelope is signed by 0 trusted keys, 1 required",
+		},
+		{
+			name: "invalid payload encoding",
+			env: func() *envelope {
+				env := signEnvelope(inTotoPayloadType, payload, builder)
+				env.Payload = "not base64!"
+				return env
+			},
+			threshold:   1,
+			expectedErr: "envelope payload: invalid base64 encoding",
+		},
+		{
+			name:        "missing payload type",
+			env:         func() *envelope { return signEnvelope("", payload, builder) },
+			threshold:   1,
+			expectedErr: "envelope has no payloadType",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, keyIDs, err := verifyEnvelope(tt.env(), keys, tt.threshold)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, payload, got)
+			assert.Equal(t, tt.expectedIDs, keyIDs)
+		})
+	}
+}
+
+func TestLoadTrustedKeys(t *testing.T) {
+	keys, err := loadTrustedKeys([]KeyConfig{{ID: "builder", File: "testdata/ed25519.pem"}})
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "builder", keys[0].id)
+	assert.IsType(t, ed25519.PublicKey{}, keys[0].key)
+
+	_, err = loadTrustedKeys([]KeyConfig{{ID: "missing", File: "testdata/missing.pem"}})
+	assert.ErrorContains(t, err, `failed to read key "missing"`)
+
+	_, err = loadTrustedKeys([]KeyConfig{{ID: "invalid", PEM: "not a key"}})
+	assert.EqualError(t, err, `failed to parse key "invalid": no PEM block found`)
+
+	// The same key configured under two IDs would count twice towards the
+	// threshold.
+	data, err := os.ReadFile("testdata/ed25519.pem")
+	require.NoError(t, err)
+	_, err = loadTrustedKeys([]KeyConfig{
+		{ID: "builder", File: "testdata/ed25519.pem"},
+		{ID: "release", PEM: string(data)},
+	})
+	assert.EqualError(t, err, `key "release": same public key as key "builder"`)
+}
@@ -0,0 +1,48 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package intotoreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/intotoreceiver"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/intotoreceiver/internal/metadata"
+)
+
+const (
+	defaultEndpoint = "localhost:8443"
+	defaultPath     = "/v1/attestations"
+)
+
+// NewFactory returns a new factory for the in-toto attestation receiver.
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		receiver.WithLogs(createLogsReceiver, metadata.LogsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	serverConfig := confighttp.NewDefaultServerConfig()
+	serverConfig.NetAddr.Endpoint = defaultEndpoint
+	return &Config{
+		ServerConfig: serverConfig,
+		Path:         defaultPath,
+		Threshold:    1,
+	}
+}
+
+func createLogsReceiver(
+	_ context.Context,
+	set receiver.Settings,
+	cfg component.Config,
+	next consumer.Logs,
+) (receiver.Logs, error) {
+	return newAttestationReceiver(set, cfg.(*Config), next)
+}
@@ -0,0 +1,172 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package intotoreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/intotoreceiver"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/errorutil"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/intotoreceiver/internal/metadata"
+)
+
+const dataFormat = "dsse"
+
+var errNoEnvelopes = errors.New("request contains no envelopes")
+
+type attestationReceiver struct {
+	settings receiver.Settings
+	cfg      *Config
+	next     consumer.Logs
+	obsrecv  *receiverhelper.ObsReport
+
+	keys       []trustedKey
+	server     *http.Server
+	shutdownWG sync.WaitGroup
+}
+
+func newAttestationReceiver(set receiver.Settings, cfg *Config, next consumer.Logs) (*attestationReceiver, error) {
+	transport := "http"
+	if cfg.TLS.HasValue() {
+		transport = "https"
+	}
+	obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{
+		ReceiverID:             set.ID,
+		Transport:              transport,
+		ReceiverCreateSettings: set,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &attestationReceiver{
+		settings: set,
+		cfg:      cfg,
+		next:     next,
+		obsrecv:  obsrecv,
+	}, nil
+}
+
+// Start loads the trusted keys and starts the HTTP server.
+func (r *attestationReceiver) Start(ctx context.Context, host component.Host) error {
+	keys, err := loadTrustedKeys(r.cfg.Keys)
+	if err != nil {
+		return err
+	}
+	r.keys = keys
+
+	ln, err := r.cfg.ToListener(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", r.cfg.NetAddr.Endpoint, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(r.cfg.Path, r.handleAttestations)
+	r.server, err = r.cfg.ToServer(ctx, host.GetExtensions(), r.settings.TelemetrySettings, mux)
+	if err != nil {
+		return errors.Join(err, ln.Close())
+	}
+
+	r.shutdownWG.Go(func() {
+		if errHTTP := r.server.Serve(ln); errHTTP != nil && !errors.Is(errHTTP, http.ErrServerClosed) {
+			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(errHTTP))
+		}
+	})
+	return nil
+}
+
+// Shutdown stops the HTTP server.
+func (r *attestationReceiver) Shutdown(context.Context) error {
+	if r.server == nil {
+		return nil
+	}
+	err := r.server.Close()
+	r.shutdownWG.Wait()
+	return err
+}
+
+// handleAttestations accepts a bundle of DSSE envelopes, either as JSON Lines
+// or as a sequence of JSON documents. The request is rejected as a whole when
+// any envelope fails verification, so that no statement of a tampered bundle
+// enters the pipeline.
+func (r *attestationReceiver) handleAttestations(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.obsrecv.StartLogsOp(req.Context())
+	ld, err := r.decodeBundle(req.Body)
+	if err != nil {
+		r.settings.Logger.Debug("rejected attestation bundle", zap.Error(err))
+		r.obsrecv.EndLogsOp(ctx, dataFormat, 0, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	count := ld.LogRecordCount()
+	err = r.next.ConsumeLogs(ctx, ld)
+	r.obsrecv.EndLogsOp(ctx, dataFormat, count, err)
+	if err != nil {
+		errorutil.HTTPError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *attestationReceiver) decodeBundle(body io.Reader) (plog.Logs, error) {
+	ld := plog.NewLogs()
+	sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	sl.Scope().SetName(metadata.ScopeName)
+	sl.Scope().SetVersion(r.settings.BuildInfo.Version)
+	lrs := sl.LogRecords()
+	observed := pcommon.NewTimestampFromTime(time.Now())
+
+	dec := json.NewDecoder(body)
+	for i := 0; ; i++ {
+		var env envelope
+		if err := dec.Decode(&env); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return ld, fmt.Errorf("envelope %d: invalid JSON: %w", i, err)
+		}
+		if err := r.appendEnvelope(lrs, &env, observed); err != nil {
+			return ld, fmt.Errorf("envelope %d: %w", i, err)
+		}
+	}
+	if lrs.Len() == 0 {
+		return ld, errNoEnvelopes
+	}
+	return ld, nil
+}
+
+func (r *attestationReceiver) appendEnvelope(lrs plog.LogRecordSlice, env *envelope, observed pcommon.Timestamp) error {
+	payload, keyIDs, err := verifyEnvelope(env, r.keys, r.cfg.Threshold)
+	if err != nil {
+		return err
+	}
+	if env.PayloadType != inTotoPayloadType {
+		return fmt.Errorf("unsupported payload type %q", env.PayloadType)
+	}
+	st, raw, err := parseStatement(payload)
+	if err != nil {
+		return err
+	}
+	return appendStatement(lrs, st, raw, env.PayloadType, keyIDs, observed)
+}
@@ -0,0 +1,202 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package intotoreceiver
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/testutil"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/intotoreceiver/internal/metadata"
+)
+
+const provenanceStatement = `{
+  "_type": "https://in-toto.io/Statement/v1",
+  "subject": [
+    {"name": "otelcol-contrib", "digest": {"sha512": "cd34", "sha256": "ab12"}},
+    {"name": "otelcol-contrib.sbom"}
+  ],
+  "predicateType": "https://slsa.dev/provenance/v1",
+  "predicate": {"buildDefinition": {"buildType": "https://actions.github.io/buildtypes/workflow/v1"}}
+}`
+
+func startReceiver(t *testing.T, next consumer.Logs, signers ...*testSigner) string {
+	t.Helper()
+	cfg := createDefaultConfig().(*Config)
+	cfg.NetAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+	for _, s := range signers {
+		cfg.Keys = append(cfg.Keys, KeyConfig{ID: s.id, PEM: s.pem(t)})
+	}
+	require.NoError(t, cfg.Validate())
+
+	r, err := NewFactory().CreateLogs(t.Context(), receivertest.NewNopSettings(metadata.Type), cfg, next)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		require.NoError(t, r.Shutdown(context.Background()))
+	})
+	return "http://" + cfg.NetAddr.Endpoint + cfg.Path
+}
+
+func bundle(t *testing.T, envs ...*envelope) []byte {
+	var buf bytes.Buffer
+	for _, env := range envs {
+		line, err := json.Marshal(env)
+		require.NoError(t, err)
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func post(t *testing.T, url string, body []byte) (int, string) {
+	t.Helper()
+	resp, err := http.Post(url, "application/jsonl", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(respBody)
+}
+
+func TestReceiveBundle(t *testing.T) {
+	signer := newEd25519Signer(t, "builder")
+	sink := new(consumertest.LogsSink)
+	url := startReceiver(t, sink, signer)
+
+	status, _ := post(t, url, bundle(t,
+		signEnvelope(inTotoPayloadType, []byte(provenanceStatement), signer),
+		signEnvelope(inTotoPayloadType, []byte(provenanceStatement), signer),
+	))
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, sink.AllLogs(), 1)
+
+	ld := sink.AllLogs()[0]
+	assert.Equal(t, 2, ld.LogRecordCount())
+	sl := ld.ResourceLogs().At(0).ScopeLogs().At(0)
+	assert.Equal(t, metadata.ScopeName, sl.Scope().Name())
+
+	lr := sl.LogRecords().At(0)
+	assert.Equal(t, eventName, lr.EventName())
+	assert.NotZero(t, lr.ObservedTimestamp())
+	assert.Equal(t, map[string]any{
+		attrStatementType:  "https://in-toto.io/Statement/v1",
+		attrPredicateType:  "https://slsa.dev/provenance/v1",
+		attrPayloadType:    inTotoPayloadType,
+		attrSubjectNames:   []any{"otelcol-contrib", "otelcol-contrib.sbom"},
+		attrSubjectDigests: []any{"sha256:ab12", "sha512:cd34"},
+		attrKeyIDs:         []any{"builder"},
+	}, lr.Attributes().AsRaw())
+
+	predicate, ok := lr.Body().Map().Get("predicate")
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{
+		"buildDefinition": map[string]any{"buildType": "https://actions.github.io/buildtypes/workflow/v1"},
+	}, predicate.Map().AsRaw())
+}
+
+func TestReceivePrettyPrintedEnvelope(t *testing.T) {
+	signer := newEd25519Signer(t, "builder")
+	sink := new(consumertest.LogsSink)
+	url := startReceiver(t, sink, signer)
+
+	body, err := json.MarshalIndent(signEnvelope(inTotoPayloadType, []byte(provenanceStatement), signer), "", "  ")
+	require.NoError(t, err)
+
+	status, _ := post(t, url, body)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 1, sink.LogRecordCount())
+}
+
+func TestRejectBundle(t *testing.T) {
+	signer := newEd25519Signer(t, "builder")
+	untrusted := newEd25519Signer(t, "untrusted")
+	valid := signEnvelope(inTotoPayloadType, []byte(provenanceStatement), signer)
+
+	tests := []struct {
+		name        string
+		body        []byte
+		expectedErr string
+	}{
+		{
+			name:        "empty",
+			body:        nil,
+			expectedErr: errNoEnvelopes.Error(),
+		},
+		{
+			name:        "invalid JSON",
+			body:        []byte("{"),
+			expectedErr: "envelope 0: invalid JSON",
+		},
+		{
+			name:        "untrusted signature",
+			body:        bundle(t, valid, signEnvelope(inTotoPayloadType, []byte(provenanceStatement), untrusted)),
+			expectedErr: "envelope 1: envelope is signed by 0 trusted keys, 1 required",
+		},
+		{
+			name:        "unsupported payload type",
+			body:        bundle(t, signEnvelope("application/json", []byte(provenanceStatement), signer)),
+			expectedErr: `envelope 0: unsupported payload type "application/json"`,
+		},
+		{
+			name:        "invalid statement",
+			body:        bundle(t, signEnvelope(inTotoPayloadType, []byte(`{"_type":"https://in-toto.io/Statement/v1"}`), signer)),
+			expectedErr: "envelope 0: statement has no predicateType",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := new(consumertest.LogsSink)
+			url := startReceiver(t, sink, signer)
+
+			status, body := post(t, url, tt.body)
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Contains(t, body, tt.expectedErr)
+			assert.Zero(t, sink.LogRecordCount())
+		})
+	}
+}
+
+func TestConsumerError(t *testing.T) {
+	signer := newEd25519Signer(t, "builder")
+	url := startReceiver(t, consumertest.NewErr(errors.New("queue full")), signer)
+
+	status, _ := post(t, url, bundle(t, signEnvelope(inTotoPayloadType, []byte(provenanceStatement), signer)))
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	signer := newEd25519Signer(t, "builder")
+	url := startReceiver(t, consumertest.NewNop(), signer)
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestStartInvalidKey(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.NetAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+	cfg.Keys = []KeyConfig{{ID: "builder", PEM: base64.StdEncoding.EncodeToString([]byte("garbage"))}}
+
+	r, err := NewFactory().CreateLogs(t.Context(), receivertest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.EqualError(t, r.Start(t.Context(), componenttest.NewNopHost()), `failed to parse key "builder": no PEM block found`)
+	require.NoError(t, r.Shutdown(t.Context()))
+}
@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package intotoreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/intotoreceiver"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	inTotoPayloadType = "application/vnd.in-toto+json"
+	eventName         = "in_toto.attestation"
+
+	attrStatementType  = "in_toto.statement.type"
+	attrPredicateType  = "in_toto.predicate.type"
+	attrSubjectNames   = "in_toto.subject.names"
+	attrSubjectDigests = "in_toto.subject.digests"
+	attrPayloadType    = "dsse.payload_type"
+	attrKeyIDs         = "dsse.key_ids"
+)
+
+// statement is the subset of an in-toto statement the receiver interprets,
+// see https://github.com/in-toto/attestation/blob/main/spec/v1/statement.md.
+type statement struct {
+	Type          string               `json:"_type"`
+	Subject       []resourceDescriptor `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+}
+
+type resourceDescriptor struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// parseStatement decodes an in-toto statement, returning both its typed
+// fields and its raw form.
+func parseStatement(payload []byte) (*statement, map[string]any, error) {
+	var st statement
+	if err := json.Unmarshal(payload, &st); err != nil {
+		return nil, nil, fmt.Errorf("invalid statement: %w", err)
+	}
+	if st.Type == "" {
+		return nil, nil, errors.New("statement has no _type")
+	}
+	if st.PredicateType == "" {
+		return nil, nil, errors.New("statement has no predicateType")
+	}
+	if len(st.Subject) == 0 {
+		return nil, nil, errors.New("statement has no subject")
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, nil, fmt.Errorf("invalid statement: %w", err)
+	}
+	return &st, raw, nil
+}
+
+// appendStatement adds a log record describing a verified statement to lrs.
+// The body holds the full statement; the attributes summarize what was
+// attested and who signed it.
+func appendStatement(lrs plog.LogRecordSlice, st *statement, raw map[string]any, payloadType string, keyIDs []string, observed pcommon.Timestamp) error {
+	lr := lrs.AppendEmpty()
+	lr.SetObservedTimestamp(observed)
+	lr.SetEventName(eventName)
+	if err := lr.Body().SetEmptyMap().FromRaw(raw); err != nil {
+		return err
+	}
+
+	attrs := lr.Attributes()
+	attrs.PutStr(attrStatementType, st.Type)
+	attrs.PutStr(attrPredicateType, st.PredicateType)
+	attrs.PutStr(attrPayloadType, payloadType)
+
+	names := attrs.PutEmptySlice(attrSubjectNames)
+	digests := attrs.PutEmptySlice(attrSubjectDigests)
+	for _, subject := range st.Subject {
+		names.AppendEmpty().SetStr(subject.Name)
+		algs := make([]string, 0, len(subject.Digest))
+		for alg := range subject.Digest {
+			algs = append(algs, alg)
+		}
+		sort.Strings(algs)
+		for _, alg := range algs {
+			digests.AppendEmpty().SetStr(alg + ":" + subject.Digest[alg])
+		}
+	}
+
+	signers := attrs.PutEmptySlice(attrKeyIDs)
+	for _, id := range keyIDs {
+		signers.AppendEmpty().SetStr(id)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package wormarchivereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/wormarchivereceiver"
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/hashchain"
+)
+
+// archive is a verified WORM file.
+type archive struct {
+	// start is the chain state the first entry continues from: the state at
+	// the seal of the previous file, or the genesis state.
+	start hashchain.State
+	// end is the chain state at the last entry of the file.
+	end hashchain.State
+}
+
+// seal returns the hex encoded hash of the seal of the archive, empty when
+// the file is not sealed.
+func (a archive) seal() string {
+	if !a.end.Sealed {
+		return ""
+	}
+	return hex.EncodeToString(a.end.Head)
+}
+
+// verifyFile verifies the hash chain of a WORM file, see hashchain.Verify.
+// The file may continue any chain: the state it starts from is read from its
+// first entry and returned for the caller to check.
+func verifyFile(path string) (archive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return archive{}, err
+	}
+	defer f.Close()
+
+	start, err := chainStart(f)
+	if err != nil {
+		return archive{}, err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return archive{}, err
+	}
+	end, err := hashchain.Verify(f, start, nil)
+	if err != nil {
+		return archive{}, err
+	}
+	return archive{start: start, end: end}, nil
+}
+
+// replayFile verifies a WORM file again, continuing the chain at start, and
+// calls fn with every verified entry.
+func replayFile(path string, start hashchain.State, fn func(hashchain.Entry) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = hashchain.Verify(f, start, fn)
+	return err
+}
+
+// chainStart returns the chain state the first entry read from r links to.
+func chainStart(r io.Reader) (hashchain.State, error) {
+	raw, err := bufio.NewReader(r).ReadBytes('\n')
+	if errors.Is(err, io.EOF) {
+		return hashchain.Genesis(), nil
+	}
+	if err != nil {
+		return hashchain.State{}, err
+	}
+	var e hashchain.Entry
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return hashchain.State{}, fmt.Errorf("line 1: %w", err)
+	}
+	if e.Seq == 0 {
+		return hashchain.State{}, errors.New("line 1: sequence numbers start at 1")
+	}
+	prev, err := hex.DecodeString(e.Prev)
+	if err != nil {
+		return hashchain.State{}, fmt.Errorf("line 1: invalid prev: %w", err)
+	}
+	return hashchain.State{Seq: e.Seq - 1, Head: prev}, nil
+}
+
+// decodeBatch decodes the batch of a logs entry: OTLP JSON embedded as is, or
+// OTLP protobuf as a base64 string.
+func decodeBatch(data []byte) (plog.Logs, error) {
+	if len(data) > 0 && data[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(data, &encoded); err != nil {
+			return plog.Logs{}, err
+		}
+		buf, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return plog.Logs{}, err
+		}
+		return (&plog.ProtoUnmarshaler{}).UnmarshalLogs(buf)
+	}
+	return (&plog.JSONUnmarshaler{}).UnmarshalLogs(data)
+}
@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package wormarchivereceiver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/hashchain"
+)
+
+func TestVerifyFile(t *testing.T) {
+	dir := t.TempDir()
+	w := newChainWriter()
+	first := w.line(hashchain.EntryTypeLogs, []byte(`{}`))
+	start := w.state.Next()
+	second := w.line(hashchain.EntryTypeLogs, []byte(`{}`))
+	seal := w.line(hashchain.EntryTypeSeal, []byte(`{"entries":1}`))
+
+	write := func(content string) string {
+		path := filepath.Join(dir, "audit.ndjson")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	arch, err := verifyFile(write(first))
+	require.NoError(t, err)
+	assert.Equal(t, hashchain.Genesis(), arch.start)
+	assert.Empty(t, arch.seal())
+
+	// A rotated file continues the chain at the seal of the previous file.
+	arch, err = verifyFile(write(second + seal))
+	require.NoError(t, err)
+	assert.Equal(t, start, arch.start)
+	assert.Equal(t, uint64(3), arch.end.Seq)
+	assert.NotEmpty(t, arch.seal())
+
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{name: "seal miscounting entries", content: first + second + seal, expectedErr: "line 3: seal counts 1 entries, file has 2"},
+		{name: "reordered", content: second + first, expectedErr: "line 2: prev does not match the hash of the previous entry"},
+		{name: "modified", content: strings.Replace(first, `"data":{}`, `"data":{ }`, 1), expectedErr: "line 1: hash mismatch"},
+		{name: "sequence zero", content: strings.Replace(first, `"seq":1`, `"seq":0`, 1), expectedErr: "line 1: sequence numbers start at 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifyFile(write(tt.content))
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
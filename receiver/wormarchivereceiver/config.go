@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package wormarchivereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/wormarchivereceiver"
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines configuration for the WORM archive receiver.
+type Config struct {
+	// Include lists glob patterns matching the archives to import, typically
+	// the rotated files of a WORM file exporter.
+	Include []string `mapstructure:"include"`
+
+	// PollInterval is the interval at which the patterns are matched again to
+	// pick up newly rotated archives. When 0, archives are only imported once
+	// on start. Default: 0.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+
+	// Storage is the ID of a storage extension used to remember which
+	// archives were imported, so they are not replayed again after a
+	// restart. Optional.
+	Storage *component.ID `mapstructure:"storage"`
+}
+
+// Validate checks the Config for invalid values.
+func (cfg *Config) Validate() error {
+	if len(cfg.Include) == 0 {
+		return errors.New("include must not be empty")
+	}
+	for _, pattern := range cfg.Include {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+	}
+	if cfg.PollInterval < 0 {
+		return fmt.Errorf("poll_interval must not be negative, got %s", cfg.PollInterval)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate make mdatagen
+
+// Package wormarchivereceiver replays the sealed files written by the WORM
+// file exporter into a logs pipeline after verifying their hash chain.
+package wormarchivereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/wormarchivereceiver"
@@ -0,0 +1,255 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package wormarchivereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/wormarchivereceiver"
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/hashchain"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/wormarchivereceiver/internal/metadata"
+)
+
+const (
+	dataFormat = "worm_file"
+
+	outcomeImported = "imported"
+	outcomeRejected = "rejected"
+)
+
+type archiveReceiver struct {
+	settings  receiver.Settings
+	cfg       *Config
+	next      consumer.Logs
+	obsrecv   *receiverhelper.ObsReport
+	telemetry *metadata.TelemetryBuilder
+
+	storageClient storage.Client
+	// states caches the import progress by seal hash.
+	states map[string]importState
+	// done holds the paths of the archives imported or rejected, which are
+	// not read again.
+	done map[string]struct{}
+
+	cancel     context.CancelFunc
+	shutdownWG sync.WaitGroup
+}
+
+func newArchiveReceiver(set receiver.Settings, cfg *Config, next consumer.Logs) (*archiveReceiver, error) {
+	obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{
+		ReceiverID:             set.ID,
+		ReceiverCreateSettings: set,
+	})
+	if err != nil {
+		return nil, err
+	}
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+	return &archiveReceiver{
+		settings:  set,
+		cfg:       cfg,
+		next:      next,
+		obsrecv:   obsrecv,
+		telemetry: telemetryBuilder,
+		states:    map[string]importState{},
+		done:      map[string]struct{}{},
+	}, nil
+}
+
+// Start starts importing the archives in the background.
+func (r *archiveReceiver) Start(ctx context.Context, host component.Host) error {
+	if r.cfg.Storage != nil {
+		client, err := getStorageClient(ctx, host, r.cfg.Storage, r.settings.ID)
+		if err != nil {
+			return err
+		}
+		r.storageClient = client
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.shutdownWG.Go(func() { r.importLoop(loopCtx) })
+	return nil
+}
+
+// Shutdown stops importing archives.
+func (r *archiveReceiver) Shutdown(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.shutdownWG.Wait()
+	r.telemetry.Shutdown()
+	if r.storageClient != nil {
+		return r.storageClient.Close(ctx)
+	}
+	return nil
+}
+
+func (r *archiveReceiver) importLoop(ctx context.Context) {
+	r.scan(ctx)
+	if r.cfg.PollInterval == 0 {
+		return
+	}
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.scan(ctx)
+		}
+	}
+}
+
+// scan imports the matching archives in the order of their names, which is
+// the order the WORM file exporter rotated them in. The scan stops at an
+// archive that could not be imported completely, so that later archives are
+// not replayed before it.
+func (r *archiveReceiver) scan(ctx context.Context) {
+	var paths []string
+	for _, pattern := range r.cfg.Include {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			r.settings.Logger.Error("Invalid include pattern", zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		paths = append(paths, matches...)
+	}
+	slices.Sort(paths)
+	for _, path := range slices.Compact(paths) {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, ok := r.done[path]; ok {
+			continue
+		}
+		if !r.importArchive(ctx, path) {
+			return
+		}
+	}
+}
+
+// importArchive verifies an archive as a whole before replaying it, so that
+// nothing of a tampered archive is replayed. An archive must continue the
+// chain: its first entry links to the genesis hash or to the seal of an
+// archive verified before, so that removed or reordered archives are
+// detected. Archives are then read again and re-verified while replaying, in
+// case they changed in between. It reports false when the import is to be
+// retried on the next scan.
+func (r *archiveReceiver) importArchive(ctx context.Context, path string) bool {
+	logger := r.settings.Logger.With(zap.String("path", path))
+
+	arch, err := verifyFile(path)
+	if err != nil {
+		logger.Error("Archive failed verification, not importing it", zap.Error(err))
+		r.reject(ctx, path)
+		return true
+	}
+	seal := arch.seal()
+	if seal == "" {
+		// The file is still being written to or was not closed properly.
+		logger.Debug("Archive is not sealed, skipping it")
+		return true
+	}
+
+	state, err := r.loadState(ctx, seal)
+	if err != nil {
+		logger.Warn("Failed to load import state", zap.Error(err))
+		return false
+	}
+	if state.Complete {
+		logger.Debug("Archive was already imported", zap.String("imported_from", state.Path))
+		r.done[path] = struct{}{}
+		return true
+	}
+	if state.SealSeq == 0 {
+		ok, err := r.continuesChain(ctx, arch.start)
+		if err != nil {
+			logger.Warn("Failed to load import state", zap.Error(err))
+			return false
+		}
+		if !ok {
+			logger.Error("Archive does not continue the chain of a verified archive, not importing it",
+				zap.Uint64("first_seq", arch.start.Seq+1), zap.String("prev", hex.EncodeToString(arch.start.Head)))
+			r.reject(ctx, path)
+			return true
+		}
+		state.SealSeq = arch.end.Seq
+	}
+
+	state.Path = path
+	if err := r.saveState(ctx, seal, state); err != nil {
+		logger.Warn("Failed to record verified archive", zap.Error(err))
+		return false
+	}
+	records := 0
+	err = replayFile(path, arch.start, func(e hashchain.Entry) error {
+		if e.Type != hashchain.EntryTypeLogs || e.Seq <= state.Seq {
+			return nil
+		}
+		ld, err := decodeBatch(e.Data)
+		if err != nil {
+			return fmt.Errorf("failed to decode batch: %w", err)
+		}
+		obsCtx := r.obsrecv.StartLogsOp(ctx)
+		err = r.next.ConsumeLogs(obsCtx, ld)
+		r.obsrecv.EndLogsOp(obsCtx, dataFormat, ld.LogRecordCount(), err)
+		if err != nil {
+			return err
+		}
+		records += ld.LogRecordCount()
+		state.Seq = e.Seq
+		return r.saveState(ctx, seal, state)
+	})
+	if err != nil {
+		// The import resumes after the last replayed entry on the next scan.
+		logger.Warn("Failed to replay archive", zap.Int("records", records), zap.Error(err))
+		return false
+	}
+
+	state.Complete = true
+	if err := r.saveState(ctx, seal, state); err != nil {
+		logger.Warn("Failed to record import", zap.Error(err))
+	}
+	r.done[path] = struct{}{}
+	r.telemetry.ReceiverWormArchiveFiles.Add(ctx, 1, metric.WithAttributeSet(attribute.NewSet(attribute.String("outcome", outcomeImported))))
+	logger.Info("Archive imported", zap.Uint64("entries", arch.end.Entries), zap.Int("records", records))
+	return true
+}
+
+// continuesChain reports whether an archive starting at start continues the
+// chain: at the genesis state, or at the seal of an archive verified before.
+func (r *archiveReceiver) continuesChain(ctx context.Context, start hashchain.State) (bool, error) {
+	if start.Seq == 0 {
+		return bytes.Equal(start.Head, hashchain.GenesisHash), nil
+	}
+	prev, err := r.loadState(ctx, hex.EncodeToString(start.Head))
+	if err != nil {
+		return false, err
+	}
+	return prev.SealSeq == start.Seq, nil
+}
+
+func (r *archiveReceiver) reject(ctx context.Context, path string) {
+	r.done[path] = struct{}{}
+	r.telemetry.ReceiverWormArchiveFiles.Add(ctx, 1, metric.WithAttributeSet(attribute.NewSet(attribute.String("outcome", outcomeRejected))))
+}
@@ -0,0 +1,270 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package wormarchivereceiver
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/hashchain"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/wormarchivereceiver/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/wormarchivereceiver/internal/metadatatest"
+)
+
+func testLogs(bodies ...string) plog.Logs {
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, body := range bodies {
+		lrs.AppendEmpty().Body().SetStr(body)
+	}
+	return ld
+}
+
+// chainWriter writes WORM files the way the WORM file exporter does.
+type chainWriter struct {
+	state hashchain.State
+}
+
+func newChainWriter() *chainWriter {
+	return &chainWriter{state: hashchain.Genesis()}
+}
+
+func (w *chainWriter) line(typ string, data []byte) string {
+	return string(w.state.Append(typ, data))
+}
+
+// write writes batches to path, closing the file with a seal when sealed.
+func (w *chainWriter) write(t *testing.T, path string, sealed bool, batches ...plog.Logs) {
+	t.Helper()
+	var b strings.Builder
+	for _, ld := range batches {
+		data, err := (&plog.JSONMarshaler{}).MarshalLogs(ld)
+		require.NoError(t, err)
+		b.WriteString(w.line(hashchain.EntryTypeLogs, data))
+	}
+	if sealed {
+		b.WriteString(w.line(hashchain.EntryTypeSeal, fmt.Appendf(nil, `{"entries":%d,"sealed_at":"2026-10-15T08:00:00Z"}`, len(batches))))
+	}
+	require.NoError(t, os.WriteFile(path, []byte(b.String()), 0o600))
+}
+
+func newTestReceiver(t *testing.T, cfg *Config, next consumer.Logs, tel *componenttest.Telemetry) *archiveReceiver {
+	t.Helper()
+	set := receivertest.NewNopSettings(metadata.Type)
+	set.ID = component.NewID(metadata.Type)
+	if tel != nil {
+		set.TelemetrySettings = tel.NewTelemetrySettings()
+	}
+	r, err := newArchiveReceiver(set, cfg, next)
+	require.NoError(t, err)
+	return r
+}
+
+func bodies(sink *consumertest.LogsSink) []string {
+	var out []string
+	for _, ld := range sink.AllLogs() {
+		for _, rl := range ld.ResourceLogs().All() {
+			for _, sl := range rl.ScopeLogs().All() {
+				for _, lr := range sl.LogRecords().All() {
+					out = append(out, lr.Body().Str())
+				}
+			}
+		}
+	}
+	return out
+}
+
+func TestImportArchives(t *testing.T) {
+	dir := t.TempDir()
+	w := newChainWriter()
+	w.write(t, filepath.Join(dir, "audit-20261015T080000.ndjson"), true, testLogs("a", "b"), testLogs("c"))
+	w.write(t, filepath.Join(dir, "audit-20261015T090000.ndjson"), true, testLogs("d"))
+	w.write(t, filepath.Join(dir, "audit.ndjson"), false, testLogs("e"))
+
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	sink := new(consumertest.LogsSink)
+	r := newTestReceiver(t, &Config{Include: []string{filepath.Join(dir, "*.ndjson")}}, sink, tel)
+
+	r.scan(t.Context())
+	assert.Equal(t, []string{"a", "b", "c", "d"}, bodies(sink))
+
+	// Imported archives are not replayed again.
+	r.scan(t.Context())
+	assert.Equal(t, 4, sink.LogRecordCount())
+
+	metadatatest.AssertEqualReceiverWormArchiveFiles(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 2, Attributes: attribute.NewSet(attribute.String("outcome", "imported"))},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestImportProtoArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit-1.ndjson")
+	buf, err := (&plog.ProtoMarshaler{}).MarshalLogs(testLogs("proto"))
+	require.NoError(t, err)
+	w := newChainWriter()
+	data := fmt.Appendf(nil, "%q", base64.StdEncoding.EncodeToString(buf))
+	content := w.line(hashchain.EntryTypeLogs, data) + w.line(hashchain.EntryTypeSeal, []byte(`{"entries":1}`))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	sink := new(consumertest.LogsSink)
+	r := newTestReceiver(t, &Config{Include: []string{path}}, sink, nil)
+	r.scan(t.Context())
+	assert.Equal(t, []string{"proto"}, bodies(sink))
+}
+
+func TestTamperedArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit-1.ndjson")
+	newChainWriter().write(t, path, true, testLogs("first"), testLogs("second"))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(content), "second", "forged", 1)), 0o600))
+
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	sink := new(consumertest.LogsSink)
+	r := newTestReceiver(t, &Config{Include: []string{path}}, sink, tel)
+
+	r.scan(t.Context())
+	// Nothing is replayed, not even the entries preceding the forged one.
+	assert.Zero(t, sink.LogRecordCount())
+
+	metadatatest.AssertEqualReceiverWormArchiveFiles(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 1, Attributes: attribute.NewSet(attribute.String("outcome", "rejected"))},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+// failingConsumer fails the given call and forwards the others to a sink.
+type failingConsumer struct {
+	*consumertest.LogsSink
+	failOn int
+	calls  int
+}
+
+func (c *failingConsumer) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	c.calls++
+	if c.calls == c.failOn {
+		return errors.New("pipeline refused data")
+	}
+	return c.LogsSink.ConsumeLogs(ctx, ld)
+}
+
+func TestResumeImport(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit-1.ndjson")
+	w := newChainWriter()
+	w.write(t, path, true, testLogs("a"), testLogs("b"), testLogs("c"))
+	w.write(t, filepath.Join(dir, "audit-2.ndjson"), true, testLogs("d"))
+
+	storageDir := t.TempDir()
+	storageID := storagetest.NewStorageID("test")
+	cfg := &Config{Include: []string{filepath.Join(dir, "audit-*.ndjson")}, Storage: &storageID}
+
+	run := func(next consumer.Logs) {
+		ext := storagetest.NewFileBackedStorageExtension("test", storageDir)
+		host := storagetest.NewStorageHost().WithExtension(storageID, ext)
+		r := newTestReceiver(t, cfg, next, nil)
+		client, err := getStorageClient(t.Context(), host, cfg.Storage, r.settings.ID)
+		require.NoError(t, err)
+		r.storageClient = client
+		r.scan(t.Context())
+		require.NoError(t, client.Close(context.Background()))
+		require.NoError(t, ext.Shutdown(context.Background()))
+	}
+
+	// The second batch is refused: the import stops after the first one, and
+	// the next archive is not imported before the refused batch.
+	first := &failingConsumer{LogsSink: new(consumertest.LogsSink), failOn: 2}
+	run(first)
+	assert.Equal(t, []string{"a"}, bodies(first.LogsSink))
+
+	// After a restart, the import resumes with the refused batch.
+	second := new(consumertest.LogsSink)
+	run(second)
+	assert.Equal(t, []string{"b", "c", "d"}, bodies(second))
+
+	// Once complete, the archives are not replayed, even under another name.
+	// The first archive having been imported, the second one continues the
+	// chain without it.
+	require.NoError(t, os.Rename(path, filepath.Join(dir, "copy.ndjson")))
+	cfg.Include = []string{filepath.Join(dir, "*.ndjson")}
+	third := new(consumertest.LogsSink)
+	run(third)
+	assert.Zero(t, third.LogRecordCount())
+}
+
+func TestArchiveContinuity(t *testing.T) {
+	dir := t.TempDir()
+	w := newChainWriter()
+	w.write(t, filepath.Join(dir, "audit-1.ndjson"), true, testLogs("a"))
+	second := filepath.Join(dir, "audit-2.ndjson")
+	w.write(t, second, true, testLogs("b"))
+	w.write(t, filepath.Join(dir, "audit-3.ndjson"), true, testLogs("c"))
+	require.NoError(t, os.Remove(second))
+
+	// An archive starting a chain of its own.
+	newChainWriter().write(t, filepath.Join(dir, "audit-4.ndjson"), true, testLogs("d"))
+	// A seal not counting the entries of the file.
+	miscounted := newChainWriter()
+	miscounted.state = w.state
+	content := miscounted.line(hashchain.EntryTypeLogs, []byte(`{}`)) + miscounted.line(hashchain.EntryTypeSeal, []byte(`{"entries":2}`))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "audit-5.ndjson"), []byte(content), 0o600))
+
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	sink := new(consumertest.LogsSink)
+	r := newTestReceiver(t, &Config{Include: []string{filepath.Join(dir, "*.ndjson")}}, sink, tel)
+
+	// The archive following a removed one is not imported; the archive
+	// starting a new chain is, as it links to the genesis hash.
+	r.scan(t.Context())
+	assert.Equal(t, []string{"a", "d"}, bodies(sink))
+
+	metadatatest.AssertEqualReceiverWormArchiveFiles(t, tel, []metricdata.DataPoint[int64]{
+		{Value: 2, Attributes: attribute.NewSet(attribute.String("outcome", "imported"))},
+		{Value: 2, Attributes: attribute.NewSet(attribute.String("outcome", "rejected"))},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
+func TestPollArchives(t *testing.T) {
+	dir := t.TempDir()
+	w := newChainWriter()
+	w.write(t, filepath.Join(dir, "audit-1.ndjson"), true, testLogs("a"))
+
+	sink := new(consumertest.LogsSink)
+	cfg := &Config{Include: []string{filepath.Join(dir, "audit-*.ndjson")}, PollInterval: 10 * time.Millisecond}
+	r := newTestReceiver(t, cfg, sink, nil)
+	require.NoError(t, r.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, r.Shutdown(context.Background())) })
+
+	assert.Eventually(t, func() bool { return sink.LogRecordCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	w.write(t, filepath.Join(dir, "audit-2.ndjson"), true, testLogs("b"))
+	assert.Eventually(t, func() bool { return sink.LogRecordCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStartStorageErrors(t *testing.T) {
+	storageID := storagetest.NewStorageID("missing")
+	r := newTestReceiver(t, &Config{Include: []string{"*.ndjson"}, Storage: &storageID}, consumertest.NewNop(), nil)
+	assert.EqualError(t, r.Start(t.Context(), storagetest.NewStorageHost()), `storage extension "test_storage/missing" not found`)
+}
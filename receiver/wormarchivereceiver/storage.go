@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package wormarchivereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/wormarchivereceiver"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+)
+
+const importKeyPrefix = "archive/"
+
+// importState is the progress of importing an archive, identified by the
+// hash of its seal so that renamed or copied archives are recognized.
+type importState struct {
+	Path string `json:"path"`
+	// SealSeq is the sequence number of the seal, set once the archive was
+	// verified. The next archive of the chain starts after it.
+	SealSeq uint64 `json:"seal_seq"`
+	// Seq is the sequence number of the last entry replayed.
+	Seq      uint64 `json:"seq"`
+	Complete bool   `json:"complete"`
+}
+
+// getStorageClient resolves a storage.Client for the receiver.
+func getStorageClient(ctx context.Context, host component.Host, storageID *component.ID, componentID component.ID) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[*storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", storageID)
+	}
+
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", storageID)
+	}
+
+	return storageExt.GetClient(ctx, component.KindReceiver, componentID, "")
+}
+
+// loadState returns the import progress of the archive with the given seal.
+func (r *archiveReceiver) loadState(ctx context.Context, seal string) (importState, error) {
+	if state, ok := r.states[seal]; ok {
+		return state, nil
+	}
+	var state importState
+	if r.storageClient == nil {
+		return state, nil
+	}
+	data, err := r.storageClient.Get(ctx, importKeyPrefix+seal)
+	if err != nil {
+		return state, fmt.Errorf("failed to read import state: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return state, fmt.Errorf("failed to decode import state: %w", err)
+		}
+	}
+	r.states[seal] = state
+	return state, nil
+}
+
+// saveState records the import progress of the archive with the given seal.
+func (r *archiveReceiver) saveState(ctx context.Context, seal string, state importState) error {
+	r.states[seal] = state
+	if r.storageClient == nil {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := r.storageClient.Set(ctx, importKeyPrefix+seal, data); err != nil {
+		return fmt.Errorf("failed to write import state: %w", err)
+	}
+	return nil
+}